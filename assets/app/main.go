@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/base64"
 	"fmt"
 	"image"
 	_ "image/png"
@@ -15,10 +16,24 @@ import (
 // vmArguments may be set by hover at compile-time
 var vmArguments string
 
+// buildInfo may be set by hover at compile-time, it contains the base64
+// encoded build-info.json of the build.
+var buildInfo string
+
 func main() {
+	if len(os.Args) == 2 && os.Args[1] == "--build-info" {
+		info, err := base64.StdEncoding.DecodeString(buildInfo)
+		if err != nil || len(info) == 0 {
+			fmt.Println("no build info embedded, build with `hover build --embed-build-info`")
+			os.Exit(1)
+		}
+		fmt.Println(string(info))
+		return
+	}
+
 	// DO NOT EDIT, add options in options.go
 	mainOptions := []flutter.Option{
-		flutter.OptionVMArguments(strings.Split(vmArguments, ";")),
+		flutter.OptionVMArguments(resolveVMArguments(strings.Split(vmArguments, ";"))),
 		flutter.WindowIcon(iconProvider),
 	}
 	err := flutter.Run(append(options, mainOptions...)...)
@@ -28,6 +43,30 @@ func main() {
 	}
 }
 
+// resolveVMArguments makes the path of the AOT snapshot set by hover absolute,
+// the snapshot is placed next to the executable.
+func resolveVMArguments(args []string) []string {
+	const aotLibraryFlag = "--aot-shared-library-name="
+	for i, arg := range args {
+		if !strings.HasPrefix(arg, aotLibraryFlag) || filepath.IsAbs(strings.TrimPrefix(arg, aotLibraryFlag)) {
+			continue
+		}
+		execPath, err := os.Executable()
+		if err != nil {
+			continue
+		}
+		execPath, err = filepath.EvalSymlinks(execPath)
+		if err != nil {
+			continue
+		}
+		args[i] = aotLibraryFlag + filepath.Join(filepath.Dir(execPath), strings.TrimPrefix(arg, aotLibraryFlag))
+	}
+	return args
+}
+
+// iconProvider loads the icon of the window: the assets/icon.png and the
+// assets/icon-<size>.png images generated by hover, the window system picks
+// the size it displays.
 func iconProvider() ([]image.Image, error) {
 	execPath, err := os.Executable()
 	if err != nil {
@@ -37,13 +76,23 @@ func iconProvider() ([]image.Image, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to eval symlinks for executable path")
 	}
-	imgFile, err := os.Open(filepath.Join(filepath.Dir(execPath), "assets", "icon.png"))
+	assetsPath := filepath.Join(filepath.Dir(execPath), "assets")
+	iconPaths, err := filepath.Glob(filepath.Join(assetsPath, "icon-*.png"))
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to open assets/icon.png")
+		return nil, errors.Wrap(err, "failed to list the icons")
 	}
-	img, _, err := image.Decode(imgFile)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to decode image")
+	var images []image.Image
+	for _, iconPath := range append([]string{filepath.Join(assetsPath, "icon.png")}, iconPaths...) {
+		imgFile, err := os.Open(iconPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to open assets/%s", filepath.Base(iconPath))
+		}
+		img, _, err := image.Decode(imgFile)
+		imgFile.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode assets/%s", filepath.Base(iconPath))
+		}
+		images = append(images, img)
 	}
-	return []image.Image{img}, nil
+	return images, nil
 }
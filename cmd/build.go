@@ -1,31 +1,26 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"runtime"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/go-flutter-desktop/hover/internal/enginecache"
 
-	"github.com/hashicorp/go-version"
-	"github.com/otiai10/copy"
 	"github.com/spf13/cobra"
 
-	"github.com/go-flutter-desktop/hover/cmd/packaging"
-	"github.com/go-flutter-desktop/hover/internal/androidmanifest"
 	"github.com/go-flutter-desktop/hover/internal/build"
 	"github.com/go-flutter-desktop/hover/internal/config"
 	"github.com/go-flutter-desktop/hover/internal/fileutils"
 	"github.com/go-flutter-desktop/hover/internal/log"
-	"github.com/go-flutter-desktop/hover/internal/pubspec"
-	"github.com/go-flutter-desktop/hover/internal/versioncheck"
+	"github.com/go-flutter-desktop/hover/pkg/hover"
 )
 
-var dotSlash = string([]byte{'.', filepath.Separator})
-
 var (
 	// common build flages (shared with `hover run`)
 	buildOrRunFlutterTarget   string
@@ -34,6 +29,8 @@ var (
 	buildOrRunOpenGlVersion   string
 	buildOrRunEngineVersion   string
 	buildOrRunDocker          bool
+	buildOrRunFlavor          string
+	buildOrRunDartDefines     []string
 )
 
 func initCompileFlags(cmd *cobra.Command) {
@@ -43,6 +40,8 @@ func initCompileFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().StringVar(&buildOrRunOpenGlVersion, "opengl", config.BuildOpenGlVersionDefault, "The OpenGL version specified here is only relevant for external texture plugin (i.e. video_plugin).\nIf 'none' is provided, texture won't be supported. Note: the Flutter Engine still needs a OpenGL compatible context.")
 	cmd.PersistentFlags().StringVar(&buildOrRunEngineVersion, "engine-version", config.BuildEngineDefault, "The flutter engine version to use.")
 	cmd.PersistentFlags().BoolVar(&buildOrRunDocker, "docker", false, "Execute the go build and packaging in a docker container. The Flutter build is always run locally")
+	cmd.PersistentFlags().StringVar(&buildOrRunFlavor, "flavor", "", "The flavor of hover.yaml to build.")
+	cmd.PersistentFlags().StringArrayVar(&buildOrRunDartDefines, "dart-define", nil, "A compile-time variable of the dart code, as KEY=VALUE. Can be repeated, overrides the dart-defines of hover.yaml.")
 
 	cmd.PersistentFlags().MarkHidden("branch")
 }
@@ -50,48 +49,78 @@ func initCompileFlags(cmd *cobra.Command) {
 var (
 	// `hover build`-only build flags
 	buildDebug                  bool
+	buildProfile                string
+	buildAOT                    bool
+	buildReproducible           bool
+	buildVerifyReproducible     bool
+	buildEmbedBuildInfo         bool
 	buildVersionNumber          string
+	buildArch                   string
 	buildSkipEngineDownload     bool
 	buildSkipFlutterBuildBundle bool
+	buildTargets                []string
+	buildForce                  bool
 )
 
-const mingwGccBinName = "x86_64-w64-mingw32-gcc"
-const clangBinName = "o32-clang"
-
-var engineCachePath string
-
 func init() {
 	initCompileFlags(buildCmd)
 
 	buildCmd.PersistentFlags().StringVar(&buildVersionNumber, "version-number", "", "Override the version number used in build and packaging. You may use it with $(git describe --tags)")
-	buildCmd.PersistentFlags().BoolVar(&buildDebug, "debug", false, "Build a debug version of the app.")
+	buildCmd.PersistentFlags().StringVar(&buildArch, "arch", build.DefaultArch, "The architecture to build for (amd64 or arm64).")
+	buildCmd.PersistentFlags().BoolVar(&buildDebug, "debug", false, "Build a debug version of the app. Shorthand for --profile debug.")
+	buildCmd.PersistentFlags().StringVar(&buildProfile, "profile", config.BuildProfileDefault, "The build profile to use: debug, profile, release or one of the profiles of hover.yaml.")
+	buildCmd.PersistentFlags().BoolVar(&buildAOT, "aot", false, "Compile the dart code ahead-of-time with gen_snapshot. Needs a profile using the profile or release mode, and can only target the host platform.")
 	buildCmd.PersistentFlags().BoolVar(&buildSkipEngineDownload, "skip-engine-download", false, "Skip donwloading the Flutter Engine and artifacts.")
 	buildCmd.PersistentFlags().BoolVar(&buildSkipFlutterBuildBundle, "skip-flutter-build-bundle", false, "Skip the 'flutter build bundle' step.")
+	buildCmd.PersistentFlags().BoolVar(&buildReproducible, "reproducible", false, "Make the build outputs bit-for-bit deterministic. Files are dated with SOURCE_DATE_EPOCH, or with the date of the last git commit.")
+	buildCmd.PersistentFlags().BoolVar(&buildVerifyReproducible, "verify-reproducible", false, "Build twice in reproducible mode and report the files that differ between the two builds.")
+	buildCmd.PersistentFlags().BoolVar(&buildEmbedBuildInfo, "embed-build-info", false, "Embed the build info in the buildInfo variable of the main package.")
+	buildCmd.PersistentFlags().BoolVar(&buildForce, "force", false, "Clean the output directory and rerun every build step, even when its inputs didn't change since the last build.")
+	buildCmd.Flags().StringSliceVar(&buildTargets, "targets", nil, "Comma separated list of targets to build at once (linux-deb,windows-msi for example). Defaults to the targets listed in hover.yaml.")
 	buildCmd.AddCommand(buildLinuxCmd)
 	buildCmd.AddCommand(buildLinuxSnapCmd)
 	buildCmd.AddCommand(buildLinuxDebCmd)
 	buildCmd.AddCommand(buildLinuxAppImageCmd)
 	buildCmd.AddCommand(buildLinuxRpmCmd)
 	buildCmd.AddCommand(buildLinuxPkgCmd)
+	buildCmd.AddCommand(buildLinuxTarCmd)
+	buildCmd.AddCommand(buildLinuxFlatpakCmd)
 	buildCmd.AddCommand(buildDarwinCmd)
 	buildCmd.AddCommand(buildDarwinBundleCmd)
 	buildCmd.AddCommand(buildDarwinPkgCmd)
 	buildCmd.AddCommand(buildDarwinDmgCmd)
+	buildCmd.AddCommand(buildDarwinZipCmd)
 	buildCmd.AddCommand(buildWindowsCmd)
 	buildCmd.AddCommand(buildWindowsMsiCmd)
+	buildCmd.AddCommand(buildWindowsZipCmd)
+	buildCmd.AddCommand(buildWindowsNsisCmd)
+	buildCmd.AddCommand(buildWindowsMsixCmd)
+	buildCmd.AddCommand(buildLinuxNixCmd)
 	rootCmd.AddCommand(buildCmd)
 }
 
 var buildCmd = &cobra.Command{
 	Use:   "build",
 	Short: "Build a desktop release",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		targets := buildTargets
+		if len(targets) == 0 {
+			targets = getConfig().Targets
+		}
+		if len(targets) == 0 {
+			cmd.Help()
+			return
+		}
+		runBuild(targets)
+	},
 }
 
 var buildLinuxCmd = &cobra.Command{
 	Use:   "linux",
 	Short: "Build a desktop release for linux",
 	Run: func(cmd *cobra.Command, args []string) {
-		subcommandBuild("linux", packaging.NoopTask)
+		runBuild([]string{"linux"})
 	},
 }
 
@@ -99,7 +128,7 @@ var buildLinuxSnapCmd = &cobra.Command{
 	Use:   "linux-snap",
 	Short: "Build a desktop release for linux and package it for snap",
 	Run: func(cmd *cobra.Command, args []string) {
-		subcommandBuild("linux", packaging.LinuxSnapTask)
+		runBuild([]string{"linux-snap"})
 	},
 }
 
@@ -107,7 +136,7 @@ var buildLinuxDebCmd = &cobra.Command{
 	Use:   "linux-deb",
 	Short: "Build a desktop release for linux and package it for deb",
 	Run: func(cmd *cobra.Command, args []string) {
-		subcommandBuild("linux", packaging.LinuxDebTask)
+		runBuild([]string{"linux-deb"})
 	},
 }
 
@@ -115,7 +144,7 @@ var buildLinuxAppImageCmd = &cobra.Command{
 	Use:   "linux-appimage",
 	Short: "Build a desktop release for linux and package it for AppImage",
 	Run: func(cmd *cobra.Command, args []string) {
-		subcommandBuild("linux", packaging.LinuxAppImageTask)
+		runBuild([]string{"linux-appimage"})
 	},
 }
 
@@ -123,7 +152,7 @@ var buildLinuxRpmCmd = &cobra.Command{
 	Use:   "linux-rpm",
 	Short: "Build a desktop release for linux and package it for rpm",
 	Run: func(cmd *cobra.Command, args []string) {
-		subcommandBuild("linux", packaging.LinuxRpmTask)
+		runBuild([]string{"linux-rpm"})
 	},
 }
 
@@ -131,7 +160,23 @@ var buildLinuxPkgCmd = &cobra.Command{
 	Use:   "linux-pkg",
 	Short: "Build a desktop release for linux and package it for pacman pkg",
 	Run: func(cmd *cobra.Command, args []string) {
-		subcommandBuild("linux", packaging.LinuxPkgTask)
+		runBuild([]string{"linux-pkg"})
+	},
+}
+
+var buildLinuxTarCmd = &cobra.Command{
+	Use:   "linux-tar",
+	Short: "Build a desktop release for linux and package it for tar.gz",
+	Run: func(cmd *cobra.Command, args []string) {
+		runBuild([]string{"linux-tar"})
+	},
+}
+
+var buildLinuxFlatpakCmd = &cobra.Command{
+	Use:   "linux-flatpak",
+	Short: "Build a desktop release for linux and package it for flatpak",
+	Run: func(cmd *cobra.Command, args []string) {
+		runBuild([]string{"linux-flatpak"})
 	},
 }
 
@@ -139,7 +184,7 @@ var buildDarwinCmd = &cobra.Command{
 	Use:   "darwin",
 	Short: "Build a desktop release for darwin",
 	Run: func(cmd *cobra.Command, args []string) {
-		subcommandBuild("darwin", packaging.NoopTask)
+		runBuild([]string{"darwin"})
 	},
 }
 
@@ -147,7 +192,7 @@ var buildDarwinBundleCmd = &cobra.Command{
 	Use:   "darwin-bundle",
 	Short: "Build a desktop release for darwin and package it for OSX bundle",
 	Run: func(cmd *cobra.Command, args []string) {
-		subcommandBuild("darwin", packaging.DarwinBundleTask)
+		runBuild([]string{"darwin-bundle"})
 	},
 }
 
@@ -155,7 +200,7 @@ var buildDarwinPkgCmd = &cobra.Command{
 	Use:   "darwin-pkg",
 	Short: "Build a desktop release for darwin and package it for OSX pkg installer",
 	Run: func(cmd *cobra.Command, args []string) {
-		subcommandBuild("darwin", packaging.DarwinPkgTask)
+		runBuild([]string{"darwin-pkg"})
 	},
 }
 
@@ -163,7 +208,15 @@ var buildDarwinDmgCmd = &cobra.Command{
 	Use:   "darwin-dmg",
 	Short: "Build a desktop release for darwin and package it for OSX dmg",
 	Run: func(cmd *cobra.Command, args []string) {
-		subcommandBuild("darwin", packaging.DarwinDmgTask)
+		runBuild([]string{"darwin-dmg"})
+	},
+}
+
+var buildDarwinZipCmd = &cobra.Command{
+	Use:   "darwin-zip",
+	Short: "Build a desktop release for darwin and package it for OSX zip",
+	Run: func(cmd *cobra.Command, args []string) {
+		runBuild([]string{"darwin-zip"})
 	},
 }
 
@@ -171,7 +224,7 @@ var buildWindowsCmd = &cobra.Command{
 	Use:   "windows",
 	Short: "Build a desktop release for windows",
 	Run: func(cmd *cobra.Command, args []string) {
-		subcommandBuild("windows", packaging.NoopTask)
+		runBuild([]string{"windows"})
 	},
 }
 
@@ -179,129 +232,213 @@ var buildWindowsMsiCmd = &cobra.Command{
 	Use:   "windows-msi",
 	Short: "Build a desktop release for windows and package it for msi",
 	Run: func(cmd *cobra.Command, args []string) {
-		subcommandBuild("windows", packaging.WindowsMsiTask)
+		runBuild([]string{"windows-msi"})
+	},
+}
+
+var buildWindowsZipCmd = &cobra.Command{
+	Use:   "windows-zip",
+	Short: "Build a desktop release for windows and package it for zip",
+	Run: func(cmd *cobra.Command, args []string) {
+		runBuild([]string{"windows-zip"})
+	},
+}
+
+var buildWindowsNsisCmd = &cobra.Command{
+	Use:   "windows-nsis",
+	Short: "Build a desktop release for windows and package it for nsis",
+	Run: func(cmd *cobra.Command, args []string) {
+		runBuild([]string{"windows-nsis"})
 	},
 }
 
-// TODO: replace targetOS with a same Task type for build (build.Task) ?
-func subcommandBuild(targetOS string, packagingTask packaging.Task) {
+var buildWindowsMsixCmd = &cobra.Command{
+	Use:   "windows-msix",
+	Short: "Build a desktop release for windows and package it for msix",
+	Run: func(cmd *cobra.Command, args []string) {
+		runBuild([]string{"windows-msix"})
+	},
+}
+
+var buildLinuxNixCmd = &cobra.Command{
+	Use:   "linux-nix",
+	Short: "Build a desktop release for linux and package it for nix",
+	Run: func(cmd *cobra.Command, args []string) {
+		runBuild([]string{"linux-nix"})
+	},
+}
+
+// runBuild builds the targets and exits when the build failed. A summary is
+// printed when several targets are built.
+func runBuild(targets []string) {
 	assertHoverInitialized()
-	packagingTask.AssertInitialized()
-	if !buildOrRunDocker {
-		packagingTask.AssertSupported()
+	selectFlavor(buildOrRunFlavor)
+	if !buildSkipFlutterBuildBundle {
+		prepareFlutterBundle()
 	}
 
-	if !buildSkipFlutterBuildBundle {
-		cleanBuildOutputsDir(targetOS)
-		buildFlutterBundle(targetOS)
+	if buildVerifyReproducible {
+		verifyReproducible(targets)
+		return
 	}
-	if buildOrRunDocker {
-		var buildFlags []string
-		buildFlags = append(buildFlags, commonFlags()...)
-		buildFlags = append(buildFlags, "--skip-flutter-build-bundle")
-		buildFlags = append(buildFlags, "--skip-engine-download")
-		if buildVersionNumber != "" {
-			buildFlags = append(buildFlags, "--version-number", buildVersionNumber)
+
+	result, err := hover.Build(context.Background(), buildOptions(targets))
+	if result != nil && log.JSONOutput() {
+		log.Result(newBuildResult(result))
+	} else if result != nil {
+		if len(result.Targets) > 1 {
+			printBuildSummary(result)
+		} else {
+			for _, target := range result.Targets {
+				if target.Err != nil {
+					log.Errorf("%v", target.Err)
+				}
+			}
 		}
-		if buildDebug {
-			buildFlags = append(buildFlags, "--debug")
+	}
+	if err != nil {
+		log.Errorf("%v", err)
+		if result != nil && result.Failed() > 0 {
+			printPackagingHint()
 		}
-		dockerHoverBuild(targetOS, packagingTask, buildFlags, nil)
-	} else {
-		buildGoBinary(targetOS, nil)
-		packagingTask.Pack(buildVersionNumber)
+		os.Exit(1)
 	}
 }
 
-// initBuildParameters is used to initialize all the build parameters. It sets
-// fallback values based on config or defaults for values that have not
-// explicitly been set through flags.
-func initBuildParameters(targetOS string) {
-	if buildOrRunCachePath == "" {
-		log.Errorf("Missing cache path, cannot continue. Please see previous warning.")
+// verifyReproducible builds the targets twice and exits with an error when
+// their artifacts differ.
+func verifyReproducible(targets []string) {
+	differences, err := hover.VerifyReproducible(context.Background(), buildOptions(targets))
+	if err != nil {
+		log.Errorf("%v", err)
 		os.Exit(1)
 	}
-
-	if buildOrRunEngineVersion == config.BuildEngineDefault && config.GetConfig().Engine != "" {
-		log.Warnf("changing the engine version can lead to undesirable behavior")
-		buildOrRunEngineVersion = config.GetConfig().Engine
+	if log.JSONOutput() {
+		result := verifyReproducibleResult{
+			Reproducible: len(differences) == 0,
+			Differences:  []differenceResult{},
+		}
+		for _, difference := range differences {
+			result.Differences = append(result.Differences, differenceResult{
+				Target: difference.Target,
+				Path:   difference.Path,
+			})
+		}
+		log.Result(result)
 	}
-
-	// TODO: This override doesn't work properly when the config specifies a
-	// value other than the default, and the flag is used to revert back to the
-	// default.
-	//
-	// The comment on (*FlagSet).Lookup(..).Changed isn't very clear, but we
-	// could test how that behaves and switch to it.
-	if buildOrRunOpenGlVersion == config.BuildOpenGlVersionDefault && config.GetConfig().OpenGL != "" {
-		buildOrRunOpenGlVersion = config.GetConfig().OpenGL
+	if len(differences) == 0 {
+		log.Infof("The build is reproducible, both builds produced the same artifacts")
+		return
 	}
-
-	if buildVersionNumber == "" {
-		buildVersionNumber = pubspec.GetPubSpec().GetVersion()
+	log.Errorf("The build is not reproducible, %d files differ between the two builds:", len(differences))
+	for _, difference := range differences {
+		log.Errorf("  %s: %s", difference.Target, difference.Path)
 	}
+	os.Exit(1)
+}
 
-	engineCachePath = enginecache.EngineCachePath(targetOS, buildOrRunCachePath)
-	if !buildSkipEngineDownload {
-		enginecache.ValidateOrUpdateEngine(targetOS, buildOrRunCachePath, buildOrRunEngineVersion)
-	}
+// buildResult is the JSON result of the build commands.
+type buildResult struct {
+	Arch    string              `json:"arch"`
+	Targets []buildTargetResult `json:"targets"`
 }
 
-func commonFlags() []string {
-	f := []string{}
-	if buildOrRunFlutterTarget != config.BuildTargetDefault {
-		f = append(f, "--target", buildOrRunFlutterTarget)
-	}
-	if buildOrRunGoFlutterBranch != "" {
-		f = append(f, "--branch", buildOrRunGoFlutterBranch)
-	}
-	if buildOrRunOpenGlVersion != config.BuildOpenGlVersionDefault {
-		f = append(f, "--opengl", buildOrRunOpenGlVersion)
-	}
-	return f
+type buildTargetResult struct {
+	Target string `json:"target"`
+	// Status is ok or failed.
+	Status   string `json:"status"`
+	Artifact string `json:"artifact,omitempty"`
+	// Duration is the packaging duration in seconds.
+	Duration float64 `json:"duration"`
+	Error    string  `json:"error,omitempty"`
 }
 
-// assertTargetFileExists checks and adds the lib/main_desktop.dart dart entry
-// point if needed
-func assertTargetFileExists(targetFilename string) {
-	_, err := os.Stat(targetFilename)
-	if os.IsNotExist(err) {
-		log.Warnf("Target file \"%s\" not found.", targetFilename)
-		if targetFilename == config.BuildTargetDefault {
-			log.Warnf("Let hover add the \"lib/main_desktop.dart\" file? ")
-			if askForConfirmation() {
-				fileutils.CopyAsset("app/main_desktop.dart", filepath.Join("lib", "main_desktop.dart"), fileutils.AssetsBox())
-				log.Infof("Target file \"lib/main_desktop.dart\" has been created.")
-				log.Infof("       Depending on your project, you might want to tweak it.")
-				return
-			}
+func newBuildResult(result *hover.BuildResult) buildResult {
+	r := buildResult{
+		Arch:    result.Arch,
+		Targets: []buildTargetResult{},
+	}
+	for _, target := range result.Targets {
+		targetResult := buildTargetResult{
+			Target:   target.Target,
+			Status:   "ok",
+			Artifact: target.Artifact,
+			Duration: target.Duration.Seconds(),
 		}
-		log.Printf("You can define a custom traget by using the %s flag.", log.Au().Magenta("--target"))
-		os.Exit(1)
-	}
-	if err != nil {
-		log.Errorf("Failed to stat lib/main_desktop.dart: %v\n", err)
-		os.Exit(1)
+		if target.Err != nil {
+			targetResult.Status = "failed"
+			targetResult.Artifact = ""
+			targetResult.Error = target.Err.Error()
+		}
+		r.Targets = append(r.Targets, targetResult)
 	}
+	return r
 }
 
-func cleanBuildOutputsDir(targetOS string) {
-	err := os.RemoveAll(build.OutputDirectoryPath(targetOS))
-	log.Printf("Cleaning the build directory")
-	if err != nil {
-		log.Errorf("Failed to remove output directory %s: %v", build.OutputDirectoryPath(targetOS), err)
-		os.Exit(1)
+// verifyReproducibleResult is the JSON result of `hover build
+// --verify-reproducible`.
+type verifyReproducibleResult struct {
+	Reproducible bool               `json:"reproducible"`
+	Differences  []differenceResult `json:"differences"`
+}
+
+type differenceResult struct {
+	Target string `json:"target"`
+	Path   string `json:"path"`
+}
+
+// buildOptions returns the hover.BuildOptions set by the build and run flags.
+// The settings that can also be set in hover.yaml, such as the OpenGL version,
+// are only set when their flag is set on the command line, they are otherwise
+// resolved by config.Resolve.
+func buildOptions(targets []string) hover.BuildOptions {
+	opts := hover.BuildOptions{
+		Targets:                targets,
+		Flavor:                 buildOrRunFlavor,
+		DartDefines:            buildOrRunDartDefines,
+		GoFlutterBranch:        buildOrRunGoFlutterBranch,
+		CachePath:              buildOrRunCachePath,
+		VersionNumber:          buildVersionNumber,
+		Profile:                buildProfile,
+		Reproducible:           buildReproducible,
+		Force:                  buildForce,
+		SkipEngineDownload:     buildSkipEngineDownload,
+		SkipFlutterBuildBundle: buildSkipFlutterBuildBundle,
 	}
-	err = os.MkdirAll(build.OutputDirectoryPath(targetOS), 0775)
-	if err != nil {
-		log.Errorf("Failed to create output directory %s: %v", build.OutputDirectoryPath(targetOS), err)
-		os.Exit(1)
+	if buildDebug {
+		opts.Profile = config.BuildProfileDebug
+	}
+	if flagChanged(config.SettingTarget) {
+		opts.FlutterTarget = buildOrRunFlutterTarget
+	}
+	if flagChanged(config.SettingOpenGL) {
+		opts.OpenGL = buildOrRunOpenGlVersion
+	}
+	if flagChanged(config.SettingEngineVersion) {
+		opts.EngineVersion = buildOrRunEngineVersion
 	}
+	if flagChanged(config.SettingArch) {
+		opts.Arch = buildArch
+	}
+	if flagChanged(config.SettingDocker) {
+		opts.Docker = &buildOrRunDocker
+	}
+	if flagChanged(config.SettingEmbedBuildInfo) {
+		opts.EmbedBuildInfo = &buildEmbedBuildInfo
+	}
+	if flagChanged("aot") {
+		opts.AOT = &buildAOT
+	}
+	return opts
 }
 
-func buildFlutterBundle(targetOS string) {
-	if buildOrRunFlutterTarget == config.BuildTargetDefault && config.GetConfig().Target != "" {
-		buildOrRunFlutterTarget = config.GetConfig().Target
+// prepareFlutterBundle runs the interactive checks done before building the
+// flutter bundle.
+func prepareFlutterBundle() {
+	if !flagChanged(config.SettingTarget) {
+		flutterTarget, err := config.Resolve(config.SettingTarget)
+		exitOnError(err)
+		buildOrRunFlutterTarget = flutterTarget.Value
 	}
 	assertTargetFileExists(buildOrRunFlutterTarget)
 
@@ -319,234 +456,59 @@ func buildFlutterBundle(targetOS string) {
 	}
 
 	checkFlutterChannel()
-
-	var flutterBuildBundleArgs = []string{
-		"build", "bundle",
-		"--asset-dir", filepath.Join(build.OutputDirectoryPath(targetOS), "flutter_assets"),
-		"--target", buildOrRunFlutterTarget,
-	}
-	if buildDebug {
-		flutterBuildBundleArgs = append(flutterBuildBundleArgs, "--track-widget-creation")
-	}
-	cmdFlutterBuildBundle := exec.Command(build.FlutterBin(), flutterBuildBundleArgs...)
-	cmdFlutterBuildBundle.Stderr = os.Stderr
-	cmdFlutterBuildBundle.Stdout = os.Stdout
-
-	log.Infof("Building flutter bundle")
-	err = cmdFlutterBuildBundle.Run()
-	if err != nil {
-		log.Errorf("Flutter build failed: %v", err)
-		os.Exit(1)
-	}
 }
 
-func buildGoBinary(targetOS string, vmArguments []string) {
-	if vmArgsFromEnv := os.Getenv("HOVER_IN_DOCKER_BUILD_VMARGS"); len(vmArgsFromEnv) > 0 {
-		vmArguments = append(vmArguments, strings.Split(vmArgsFromEnv, ",")...)
-	}
-	initBuildParameters(targetOS)
-
-	fileutils.CopyDir(build.IntermediatesDirectoryPath(targetOS), build.OutputDirectoryPath(targetOS))
-
-	outputEngineFile := filepath.Join(build.OutputDirectoryPath(targetOS), build.EngineFilename(targetOS))
-	if _, err := os.Stat(outputEngineFile); err == nil || os.IsExist(err) {
-		err = os.RemoveAll(outputEngineFile)
-		if err != nil {
-			log.Errorf("Failed to remove old engine: %v", err)
-			os.Exit(1)
+// printBuildSummary prints a table with the result of every target.
+func printBuildSummary(result *hover.BuildResult) {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TARGET\tSTATUS\tDURATION\tARTIFACT")
+	for _, target := range result.Targets {
+		if target.Err != nil {
+			fmt.Fprintf(w, "%s\tfailed\t%s\t%v\n", target.Target, target.Duration.Round(time.Millisecond), target.Err)
+			continue
 		}
+		fmt.Fprintf(w, "%s\tok\t%s\t%s\n", target.Target, target.Duration.Round(time.Millisecond), target.Artifact)
 	}
-	err := copy.Copy(
-		filepath.Join(engineCachePath, build.EngineFilename(targetOS)),
-		outputEngineFile,
-	)
-	if err != nil {
-		log.Errorf("Failed to copy %s: %v", build.EngineFilename(targetOS), err)
-		os.Exit(1)
-	}
+	w.Flush()
 
-	err = copy.Copy(
-		filepath.Join(engineCachePath, "artifacts", "icudtl.dat"),
-		filepath.Join(build.OutputDirectoryPath(targetOS), "icudtl.dat"),
-	)
-	if err != nil {
-		log.Errorf("Failed to copy icudtl.dat: %v", err)
-		os.Exit(1)
+	log.Printf("Build summary for %s:", result.Arch)
+	for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+		log.Printf("  %s", line)
 	}
-
-	fileutils.CopyDir(
-		filepath.Join(build.BuildPath, "assets"),
-		filepath.Join(build.OutputDirectoryPath(targetOS), "assets"),
-	)
-
-	wd, err := os.Getwd()
-	if err != nil {
-		log.Errorf("Failed to get working dir: %v", err)
-		os.Exit(1)
+	if result.Failed() == 0 {
+		log.Infof("Successfully built %d targets", len(result.Targets))
 	}
+}
 
-	if buildOrRunGoFlutterBranch == "" {
-		currentTag, err := versioncheck.CurrentGoFlutterTag(filepath.Join(wd, build.BuildPath))
-		if err != nil {
-			log.Errorf("%v", err)
-			os.Exit(1)
-		}
-
-		if currentTag == "" {
-			log.Warnf("Empty version found for go-flutter. Skipping upgrade check. (This may be caused by replace statement in the application go.mod)")
-		} else {
-			semver, err := version.NewSemver(currentTag)
-			if err != nil {
-				log.Errorf("Faild to parse 'go-flutter' semver: %v", err)
-				os.Exit(1)
-			}
+// printPackagingHint asks the user to report packaging failures.
+func printPackagingHint() {
+	log.Warnf("Packaging is very experimental and has mostly been tested on Linux.")
+	log.Infof("Please open an issue at https://github.com/go-flutter-desktop/go-flutter/issues/new?template=BUG.md")
+	log.Infof("with the log and a reproducible example if possible. You may also zip your app code")
+	log.Infof("if you are comfortable with it (closed source etc.) and attach it to the issue.")
+}
 
-			if semver.Prerelease() != "" {
-				log.Infof("Upgrading 'go-flutter' to the latest release")
-				// no buildBranch provided and currentTag isn't a release,
-				// force update. (same behaviour as previous version of hover).
-				err = upgradeGoFlutter(targetOS)
-				if err != nil {
-					// the upgrade can fail silently
-					log.Warnf("Upgrade ignored, current 'go-flutter' version: %s", currentTag)
-				}
-			} else {
-				// when the buildBranch is empty and the currentTag is a release.
-				// Check if the 'go-flutter' needs updates.
-				versioncheck.CheckForGoFlutterUpdate(filepath.Join(wd, build.BuildPath), currentTag)
+// assertTargetFileExists checks and adds the lib/main_desktop.dart dart entry
+// point if needed
+func assertTargetFileExists(targetFilename string) {
+	_, err := os.Stat(targetFilename)
+	if os.IsNotExist(err) {
+		log.Warnf("Target file \"%s\" not found.", targetFilename)
+		if targetFilename == config.BuildTargetDefault {
+			log.Warnf("Let hover add the \"lib/main_desktop.dart\" file? ")
+			if askForConfirmation() {
+				exitOnError(fileutils.CopyAsset("app/main_desktop.dart", filepath.Join("lib", "main_desktop.dart")))
+				log.Infof("Target file \"lib/main_desktop.dart\" has been created.")
+				log.Infof("       Depending on your project, you might want to tweak it.")
+				return
 			}
 		}
-
-	} else {
-		log.Printf("Downloading 'go-flutter' %s", buildOrRunGoFlutterBranch)
-
-		// when the buildBranch is set, fetch the go-flutter branch version.
-		err = upgradeGoFlutter(targetOS)
-		if err != nil {
-			os.Exit(1)
-		}
-	}
-
-	versioncheck.CheckForHoverUpdate(hoverVersion())
-
-	if buildOrRunOpenGlVersion == "none" {
-		log.Warnf("The '--opengl=none' flag makes go-flutter incompatible with texture plugins!")
-	}
-
-	if !buildDebug && targetOS == "linux" {
-		err = exec.Command("strip", "-s", outputEngineFile).Run()
-		if err != nil {
-			log.Errorf("Failed to strip %s: %v", outputEngineFile, err)
-			os.Exit(1)
-		}
-	}
-
-	buildCommandString := buildCommand(targetOS, vmArguments, build.OutputBinaryPath(config.GetConfig().GetExecutableName(pubspec.GetPubSpec().Name), targetOS))
-	cmdGoBuild := exec.Command(buildCommandString[0], buildCommandString[1:]...)
-	cmdGoBuild.Dir = filepath.Join(wd, build.BuildPath)
-	cmdGoBuild.Env = append(os.Environ(),
-		buildEnv(targetOS, engineCachePath)...,
-	)
-
-	cmdGoBuild.Stderr = os.Stderr
-	cmdGoBuild.Stdout = os.Stdout
-
-	log.Infof("Compiling 'go-flutter' and plugins")
-	err = cmdGoBuild.Run()
-	if err != nil {
-		log.Errorf("Go build failed: %v", err)
-		os.Exit(1)
-	}
-	log.Infof("Successfully compiled executable binary for %s", targetOS)
-}
-
-func buildEnv(targetOS string, engineCachePath string) []string {
-	var cgoLdflags string = os.Getenv("CGO_LDFLAGS")
-	var cgoCflags string = os.Getenv("CGO_CFLAGS")
-
-	outputDirPath := build.OutputDirectoryPath(targetOS)
-
-	switch targetOS {
-	case "darwin":
-		cgoLdflags += fmt.Sprintf(" -F%s -Wl,-rpath,@executable_path", engineCachePath)
-		cgoLdflags += fmt.Sprintf(" -F%s -L%s", outputDirPath, outputDirPath)
-		cgoLdflags += " -mmacosx-version-min=10.10"
-		cgoCflags += " -mmacosx-version-min=10.10"
-	case "linux":
-		cgoLdflags += fmt.Sprintf(" -L%s -L%s", engineCachePath, outputDirPath)
-	case "windows":
-		cgoLdflags += fmt.Sprintf(" -L%s -L%s", engineCachePath, outputDirPath)
-	default:
-		log.Errorf("Target platform %s is not supported, cgo_ldflags not implemented.", targetOS)
-		os.Exit(1)
-	}
-	env := []string{
-		"GO111MODULE=on",
-		"CGO_LDFLAGS=" + cgoLdflags,
-		"CGO_CFLAGS=" + cgoCflags,
-		"GOOS=" + targetOS,
-		"GOARCH=amd64",
-		"CGO_ENABLED=1",
-	}
-	if runtime.GOOS == "linux" {
-		if targetOS == "windows" {
-			env = append(env,
-				"CC="+mingwGccBinName,
-			)
-		}
-		if targetOS == "darwin" {
-			env = append(env,
-				"CC="+clangBinName,
-			)
-		}
-	}
-	return env
-}
-
-func buildCommand(targetOS string, vmArguments []string, outputBinaryPath string) []string {
-	abspath, err := filepath.Abs(build.BuildPath)
-	if err != nil {
-		log.Errorf("unable to detect absolute path: %s - %v", build.BuildPath, err)
+		log.Printf("You can define a custom traget by using the %s flag.", log.Au().Magenta("--target"))
 		os.Exit(1)
 	}
-
-	currentTag, err := versioncheck.CurrentGoFlutterTag(abspath)
 	if err != nil {
-		log.Errorf("%v", err)
+		log.Errorf("Failed to stat lib/main_desktop.dart: %v\n", err)
 		os.Exit(1)
 	}
-
-	var ldflags []string
-	if !buildDebug {
-		vmArguments = append(vmArguments, "--disable-dart-asserts")
-		vmArguments = append(vmArguments, "--disable-observatory")
-
-		if targetOS == "windows" {
-			ldflags = append(ldflags, "-H=windowsgui")
-		}
-		ldflags = append(ldflags, "-s")
-		ldflags = append(ldflags, "-w")
-	}
-	ldflags = append(ldflags, fmt.Sprintf("-X main.vmArguments=%s", strings.Join(vmArguments, ";")))
-	// overwrite go-flutter build-constants values
-	ldflags = append(ldflags, fmt.Sprintf(
-		"-X 'github.com/go-flutter-desktop/go-flutter.ProjectVersion=%s' "+
-			" -X 'github.com/go-flutter-desktop/go-flutter.PlatformVersion=%s' "+
-			" -X 'github.com/go-flutter-desktop/go-flutter.ProjectName=%s' "+
-			" -X 'github.com/go-flutter-desktop/go-flutter.ProjectOrganizationName=%s'",
-		buildVersionNumber,
-		currentTag,
-		config.GetConfig().GetApplicationName(pubspec.GetPubSpec().Name),
-		androidmanifest.AndroidOrganizationName()))
-
-	outputCommand := []string{
-		"go",
-		"build",
-		"-tags=opengl" + buildOrRunOpenGlVersion,
-		"-o", outputBinaryPath,
-		"-v",
-	}
-	outputCommand = append(outputCommand, fmt.Sprintf("-ldflags=%s", strings.Join(ldflags, " ")))
-	outputCommand = append(outputCommand, dotSlash+"cmd")
-	return outputCommand
 }
@@ -1,17 +1,15 @@
 package cmd
 
 import (
+	"context"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"runtime"
 
 	"github.com/spf13/cobra"
 
-	"github.com/go-flutter-desktop/hover/internal/build"
 	"github.com/go-flutter-desktop/hover/internal/enginecache"
 	"github.com/go-flutter-desktop/hover/internal/log"
-	"github.com/go-flutter-desktop/hover/internal/versioncheck"
+	"github.com/go-flutter-desktop/hover/pkg/hover"
 )
 
 func init() {
@@ -28,67 +26,19 @@ var upgradeCmd = &cobra.Command{
 		// Hardcode target to the current OS (no cross-compile for this command)
 		targetOS := runtime.GOOS
 
-		err := upgrade(targetOS)
+		_, err := hover.EnsureEngine(context.Background(), hover.EngineOptions{
+			TargetOS:   targetOS,
+			TargetArch: runtime.GOARCH,
+			CachePath:  buildOrRunCachePath,
+		})
 		if err != nil {
+			log.Errorf("%v", err)
+			os.Exit(1)
+		}
+		err = hover.UpgradeGoFlutter(context.Background(), buildOrRunGoFlutterBranch)
+		if err != nil {
+			log.Errorf("%v", err)
 			os.Exit(1)
 		}
 	},
 }
-
-func upgrade(targetOS string) (err error) {
-	enginecache.ValidateOrUpdateEngine(targetOS, buildOrRunCachePath, "")
-	return upgradeGoFlutter(targetOS)
-}
-
-func upgradeGoFlutter(targetOS string) (err error) {
-	wd, err := os.Getwd()
-	if err != nil {
-		log.Errorf("Failed to get working dir: %v", err)
-		return
-	}
-
-	if buildOrRunGoFlutterBranch == "" {
-		buildOrRunGoFlutterBranch = "@latest"
-	}
-
-	cmdGoGetU := exec.Command(build.GoBin(), "get", "-u", "-d", "github.com/go-flutter-desktop/go-flutter"+buildOrRunGoFlutterBranch)
-	cmdGoGetU.Dir = filepath.Join(wd, build.BuildPath)
-	cmdGoGetU.Env = append(os.Environ(),
-		"GOPROXY=direct", // github.com/golang/go/issues/32955 (allows '/' in branch name)
-		"GO111MODULE=on",
-	)
-	cmdGoGetU.Stderr = os.Stderr
-	cmdGoGetU.Stdout = os.Stdout
-
-	err = cmdGoGetU.Run()
-	// When cross-compiling the command fails, but that is not an error
-	if err != nil {
-		log.Errorf("Updating go-flutter to %s version failed: %v", buildOrRunGoFlutterBranch, err)
-		return
-	}
-
-	cmdGoModDownload := exec.Command(build.GoBin(), "mod", "download")
-	cmdGoModDownload.Dir = filepath.Join(wd, build.BuildPath)
-	cmdGoModDownload.Env = append(os.Environ(),
-		"GO111MODULE=on",
-	)
-	cmdGoModDownload.Stderr = os.Stderr
-	cmdGoModDownload.Stdout = os.Stdout
-
-	err = cmdGoModDownload.Run()
-	if err != nil {
-		log.Errorf("Go mod download failed: %v", err)
-		return
-	}
-
-	currentTag, err := versioncheck.CurrentGoFlutterTag(filepath.Join(wd, build.BuildPath))
-	if err != nil {
-		log.Errorf("%v", err)
-		os.Exit(1)
-	}
-
-	log.Printf("'go-flutter' is on version: %s", currentTag)
-
-	return nil
-
-}
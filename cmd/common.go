@@ -7,26 +7,79 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
-	"runtime"
 	"strings"
 	"time"
 
-	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
 
 	"github.com/go-flutter-desktop/hover/internal/build"
+	"github.com/go-flutter-desktop/hover/internal/config"
 	"github.com/go-flutter-desktop/hover/internal/flutterversion"
 	"github.com/go-flutter-desktop/hover/internal/log"
 	"github.com/go-flutter-desktop/hover/internal/pubspec"
 )
 
+// exitOnError logs the error and exits when err isn't nil.
+func exitOnError(err error) {
+	if err != nil {
+		log.Errorf("%v", err)
+		os.Exit(1)
+	}
+}
+
+// getPubSpec returns the pubspec.yaml of the project and exits when it can't
+// be read.
+func getPubSpec() pubspec.PubSpec {
+	pubSpec, err := pubspec.GetPubSpec()
+	exitOnError(err)
+	return pubSpec
+}
+
+// getConfig returns the hover.yaml of the project and exits when it can't be
+// read.
+func getConfig() config.Config {
+	cfg, err := config.GetConfig()
+	exitOnError(err)
+	return cfg
+}
+
+// binPath returns the path of a binary found by a build.*Bin function and
+// exits when it wasn't found.
+func binPath(path string, err error) string {
+	exitOnError(err)
+	return path
+}
+
+// flagChanged returns whether a flag of the running command is set on the
+// command line.
+func flagChanged(name string) bool {
+	if commandFlags == nil {
+		return false
+	}
+	flag := commandFlags.Lookup(name)
+	return flag != nil && flag.Changed
+}
+
+// changedFlags returns the values of the flags of the running command set on
+// the command line, indexed by name.
+func changedFlags() map[string]string {
+	flags := make(map[string]string)
+	if commandFlags != nil {
+		commandFlags.Visit(func(flag *pflag.Flag) {
+			flags[flag.Name] = flag.Value.String()
+		})
+	}
+	return flags
+}
+
 // assertInFlutterProject asserts this command is executed in a flutter project
 func assertInFlutterProject() {
-	pubspec.GetPubSpec()
+	getPubSpec()
 }
 
 // assertInFlutterPluginProject asserts this command is executed in a flutter plugin project
 func assertInFlutterPluginProject() {
-	if _, ok := pubspec.GetPubSpec().Flutter["plugin"]; !ok {
+	if _, ok := getPubSpec().Flutter["plugin"]; !ok {
 		log.Errorf("The directory doesn't appear to contain a plugin package.\nTo create a new plugin, first run `%s`, then run `%s`.", log.Au().Magenta("flutter create --template=plugin"), log.Au().Magenta("hover init-plugin"))
 		os.Exit(1)
 	}
@@ -47,8 +100,22 @@ func assertHoverInitialized() {
 	}
 }
 
+// selectFlavor selects the flavor of hover.yaml being built and exits when it
+// doesn't exist.
+func selectFlavor(name string) {
+	err := config.SelectFlavor(name)
+	if err != nil {
+		log.Errorf("%v", err)
+		os.Exit(1)
+	}
+}
+
 func checkFlutterChannel() {
-	channel := flutterversion.FlutterChannel()
+	channel, err := flutterversion.FlutterChannel()
+	if err != nil {
+		log.Errorf("%v", err)
+		os.Exit(1)
+	}
 	ignoreWarning := os.Getenv("HOVER_IGNORE_CHANNEL_WARNING")
 	if channel != "beta" && ignoreWarning != "true" {
 		log.Warnf("⚠ The go-flutter project tries to stay compatible with the beta channel of Flutter.")
@@ -84,10 +151,10 @@ func hoverMigrateDesktopToGo() bool {
 
 // askForConfirmation asks the user for confirmation.
 func askForConfirmation() bool {
-	fmt.Print(log.Au().Bold(log.Au().Cyan("hover: ")).String() + "[y/N]? ")
+	fmt.Fprint(log.CommandOutput(), log.Au().Bold(log.Au().Cyan("hover: ")).String()+"[y/N]? ")
 
 	if len(os.Getenv("HOVER_DISABLE_INTERACTIONS")) > 0 {
-		fmt.Println(log.Au().Bold(log.Au().Yellow("Interactions disabled, assuming 'no'.")).String())
+		fmt.Fprintln(log.CommandOutput(), log.Au().Bold(log.Au().Yellow("Interactions disabled, assuming 'no'.")).String())
 		return false
 	}
 
@@ -123,27 +190,28 @@ func initializeGoModule(projectPath string) {
 		os.Exit(1)
 	}
 
-	cmdGoModInit := exec.Command(build.GoBin(), "mod", "init", projectPath+"/"+build.BuildPath)
+	goBin := binPath(build.GoBin())
+	cmdGoModInit := exec.Command(goBin, "mod", "init", projectPath+"/"+build.BuildPath)
 	cmdGoModInit.Dir = filepath.Join(wd, build.BuildPath)
 	cmdGoModInit.Env = append(os.Environ(),
 		"GO111MODULE=on",
 	)
 	cmdGoModInit.Stderr = os.Stderr
-	cmdGoModInit.Stdout = os.Stdout
+	cmdGoModInit.Stdout = log.CommandOutput()
 	err = cmdGoModInit.Run()
 	if err != nil {
 		log.Errorf("Go mod init failed: %v\n", err)
 		os.Exit(1)
 	}
 
-	cmdGoModTidy := exec.Command(build.GoBin(), "mod", "tidy")
+	cmdGoModTidy := exec.Command(goBin, "mod", "tidy")
 	cmdGoModTidy.Dir = filepath.Join(wd, build.BuildPath)
 	log.Infof("You can add the '%s' directory to git.", cmdGoModTidy.Dir)
 	cmdGoModTidy.Env = append(os.Environ(),
 		"GO111MODULE=on",
 	)
 	cmdGoModTidy.Stderr = os.Stderr
-	cmdGoModTidy.Stdout = os.Stdout
+	cmdGoModTidy.Stdout = log.CommandOutput()
 	err = cmdGoModTidy.Run()
 	if err != nil {
 		log.Errorf("Go mod tidy failed: %v\n", err)
@@ -151,22 +219,6 @@ func initializeGoModule(projectPath string) {
 	}
 }
 
-// findPubcachePath returns the absolute path for the pub-cache or an error.
-func findPubcachePath() (string, error) {
-	var path string
-	switch runtime.GOOS {
-	case "darwin", "linux":
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return "", errors.Wrap(err, "failed to resolve user home dir")
-		}
-		path = filepath.Join(home, ".pub-cache")
-	case "windows":
-		path = filepath.Join(os.Getenv("APPDATA"), "Pub", "Cache")
-	}
-	return path, nil
-}
-
 // shouldRunPluginGet checks if the pubspec.yaml file is older than the
 // .packages file, if it is the case, prompt the user for a hover plugin get.
 func shouldRunPluginGet() (bool, error) {
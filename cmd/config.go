@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/go-flutter-desktop/hover/internal/config"
+	"github.com/go-flutter-desktop/hover/internal/log"
+)
+
+var configShowFlavor string
+
+func init() {
+	// the flags of the settings, their values are reported with the flag
+	// source like the ones of `hover build`
+	configShowCmd.Flags().StringP(config.SettingTarget, "t", "", "The main entry-point file of the application.")
+	configShowCmd.Flags().String(config.SettingOpenGL, "", "The OpenGL version.")
+	configShowCmd.Flags().String(config.SettingEngineVersion, "", "The flutter engine version to use.")
+	configShowCmd.Flags().String(config.SettingArch, "", "The architecture to build for (amd64 or arm64).")
+	configShowCmd.Flags().Bool(config.SettingEmbedBuildInfo, false, "Embed the build info in the buildInfo variable of the main package.")
+	configShowCmd.Flags().StringVar(&configShowFlavor, "flavor", "", "Show the settings of a flavor of hover.yaml.")
+	configCmd.AddCommand(configShowCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the hover configuration",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective value of every setting and where it comes from",
+	Long: `Print the effective value of every setting and where it comes from.
+
+A setting is read from the first source setting it:
+  1. the command line flag,
+  2. the HOVER_* environment variable,
+  3. go/hover.yaml,
+  4. the user config (` + config.UserConfigPath() + `),
+  5. the hover default.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		assertInFlutterProject()
+		selectFlavor(configShowFlavor)
+		values, err := config.ResolveAll(changedFlags())
+		exitOnError(err)
+
+		if log.JSONOutput() {
+			result := configShowResult{}
+			for _, value := range values {
+				result.Settings = append(result.Settings, settingResult{
+					Name:   value.Name,
+					Value:  value.Value,
+					Source: string(value.Source),
+					Origin: value.Origin,
+				})
+			}
+			log.Result(result)
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SETTING\tVALUE\tSOURCE")
+		for _, value := range values {
+			source := string(value.Source)
+			if value.Origin != "" {
+				source += " (" + value.Origin + ")"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", value.Name, value.Value, source)
+		}
+		w.Flush()
+	},
+}
+
+// configShowResult is the JSON result of `hover config show`.
+type configShowResult struct {
+	Settings []settingResult `json:"settings"`
+}
+
+type settingResult struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	// Source is flag, env, hover.yaml, user config or default.
+	Source string `json:"source"`
+	// Origin is the flag, the environment variable or the file the value
+	// was read from.
+	Origin string `json:"origin,omitempty"`
+}
@@ -36,45 +36,61 @@ var doctorCmd = &cobra.Command{
 		assertInFlutterProject()
 
 		version := hoverVersion()
+		result := doctorResult{Hover: version, OS: runtime.GOOS}
 		log.Infof("Hover version %s running on %s", version, runtime.GOOS)
 
-		log.Infof("Sharing flutter version")
-		cmdFlutterVersion := exec.Command(build.FlutterBin(), "--version")
-		cmdFlutterVersion.Stderr = os.Stderr
-		cmdFlutterVersion.Stdout = os.Stdout
-		err := cmdFlutterVersion.Run()
+		if !log.JSONOutput() {
+			log.Infof("Sharing flutter version")
+			cmdFlutterVersion := exec.Command(binPath(build.FlutterBin()), "--version")
+			cmdFlutterVersion.Stderr = os.Stderr
+			cmdFlutterVersion.Stdout = log.CommandOutput()
+			err := cmdFlutterVersion.Run()
+			if err != nil {
+				log.Errorf("Flutter --version failed: %v", err)
+			}
+		}
+		flutterVersion, err := flutterversion.FlutterVersion()
 		if err != nil {
-			log.Errorf("Flutter --version failed: %v", err)
+			log.Errorf("Failed to read the flutter version: %v", err)
+			result.Flutter.Error = err.Error()
+		} else {
+			result.Flutter = doctorFlutter{
+				Version: flutterVersion.Framework,
+				Channel: flutterVersion.Channel,
+				Engine:  flutterVersion.Engine,
+			}
+			log.Infof("Flutter engine commit: %s", log.Au().Magenta("https://github.com/flutter/engine/commit/"+flutterVersion.Engine))
+			checkFlutterChannel()
 		}
 
-		engineCommitHash := flutterversion.FlutterRequiredEngineVersion()
-		log.Infof("Flutter engine commit: %s", log.Au().Magenta("https://github.com/flutter/engine/commit/"+engineCommitHash))
-
-		checkFlutterChannel()
-
-		cmdGoEnvCC := exec.Command(build.GoBin(), "env", "CC")
+		cmdGoEnvCC := exec.Command(binPath(build.GoBin()), "env", "CC")
 		cmdGoEnvCCOut, err := cmdGoEnvCC.Output()
 		if err != nil {
 			log.Errorf("Go env CC failed: %v", err)
 		}
 		cCompiler := strings.Trim(string(cmdGoEnvCCOut), " ")
 		cCompiler = strings.Trim(cCompiler, "\n")
+		result.CCompiler.Path = cCompiler
 		if cCompiler != "" {
 			log.Infof("Finding out the C compiler version")
 			cmdCCVersion := exec.Command(cCompiler, "--version")
 			cmdCCVersion.Stderr = os.Stderr
-			cmdCCVersion.Stdout = os.Stdout
-			cmdCCVersion.Run()
+			if log.JSONOutput() {
+				out, _ := cmdCCVersion.Output()
+				result.CCompiler.Version = strings.TrimSpace(string(out))
+			} else {
+				cmdCCVersion.Stdout = log.CommandOutput()
+				cmdCCVersion.Run()
+			}
 		}
 
 		log.Infof("Sharing the content of go.mod")
-		file, err := os.Open(filepath.Join(build.BuildPath, "go.mod"))
+		goMod, err := ioutil.ReadFile(filepath.Join(build.BuildPath, "go.mod"))
 		if err != nil {
 			log.Errorf("Failed to read go.mod: %v", err)
 		} else {
-			defer file.Close()
-			b, _ := ioutil.ReadAll(file)
-			fmt.Print(string(b))
+			result.GoMod = string(goMod)
+			printDoctorSection(result.GoMod)
 		}
 
 		hoverConfig, err := config.ReadConfigFile(filepath.Join(build.BuildPath, "hover.yaml"))
@@ -86,16 +102,50 @@ var doctorCmd = &cobra.Command{
 			if err != nil {
 				log.Warnf("%v", err)
 			} else {
-				fmt.Print(string(dump))
+				result.HoverYAML = string(dump)
+				printDoctorSection(result.HoverYAML)
 			}
 		}
 
 		log.Infof("Sharing the content of go/cmd")
 		files, err := filepath.Glob(filepath.Join(build.BuildPath, "cmd", "*"))
 		if err != nil {
-			log.Errorf("Failed to get the list of files in go/cmd", err)
+			log.Errorf("Failed to get the list of files in go/cmd: %v", err)
 			os.Exit(1)
 		}
-		fmt.Println(strings.Join(files, "\t"))
+		result.CmdFiles = files
+		printDoctorSection(strings.Join(files, "\t") + "\n")
+
+		log.Result(result)
 	},
 }
+
+// doctorResult is the JSON result of `hover doctor`.
+type doctorResult struct {
+	Hover     string        `json:"hover"`
+	OS        string        `json:"os"`
+	Flutter   doctorFlutter `json:"flutter"`
+	CCompiler struct {
+		Path    string `json:"path"`
+		Version string `json:"version"`
+	} `json:"cCompiler"`
+	GoMod     string   `json:"goMod"`
+	HoverYAML string   `json:"hoverYaml"`
+	CmdFiles  []string `json:"cmdFiles"`
+}
+
+type doctorFlutter struct {
+	Version string `json:"version"`
+	Channel string `json:"channel"`
+	Engine  string `json:"engine"`
+	// Error is the reason the flutter version couldn't be read.
+	Error string `json:"error,omitempty"`
+}
+
+// printDoctorSection prints the content of a file shared by `hover doctor`,
+// the content is part of the result in the JSON output.
+func printDoctorSection(content string) {
+	if !log.JSONOutput() {
+		fmt.Print(content)
+	}
+}
@@ -10,7 +10,6 @@ import (
 	"github.com/go-flutter-desktop/hover/internal/build"
 	"github.com/go-flutter-desktop/hover/internal/fileutils"
 	"github.com/go-flutter-desktop/hover/internal/log"
-	"github.com/go-flutter-desktop/hover/internal/pubspec"
 )
 
 func init() {
@@ -22,7 +21,7 @@ var createPluginCmd = &cobra.Command{
 	Short: "Initialize a go-flutter plugin in a existing flutter platform plugin",
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
-			return errors.New("requires one argument, the VCS repository path. e.g.: github.com/my-organization/" + pubspec.GetPubSpec().Name + "\n" +
+			return errors.New("requires one argument, the VCS repository path. e.g.: github.com/my-organization/" + getPubSpec().Name + "\n" +
 				"This path will be used by Golang to fetch the plugin, make sure it correspond to the code repository of the plugin!")
 		}
 		return nil
@@ -43,14 +42,14 @@ var createPluginCmd = &cobra.Command{
 		}
 
 		templateData := map[string]string{
-			"pluginName": pubspec.GetPubSpec().Name,
-			"structName": toCamelCase(pubspec.GetPubSpec().Name + "Plugin"),
+			"pluginName": getPubSpec().Name,
+			"structName": toCamelCase(getPubSpec().Name + "Plugin"),
 			"urlVSCRepo": vcsPath,
 		}
 
-		fileutils.ExecuteTemplateFromAssetsBox("plugin/plugin.go.tmpl", filepath.Join(build.BuildPath, "plugin.go"), fileutils.AssetsBox(), templateData)
-		fileutils.ExecuteTemplateFromAssetsBox("plugin/README.md.tmpl", filepath.Join(build.BuildPath, "README.md"), fileutils.AssetsBox(), templateData)
-		fileutils.ExecuteTemplateFromAssetsBox("plugin/import.go.tmpl.tmpl", filepath.Join(build.BuildPath, "import.go.tmpl"), fileutils.AssetsBox(), templateData)
+		exitOnError(fileutils.ExecuteTemplateFromAssetsBox("plugin/plugin.go.tmpl", filepath.Join(build.BuildPath, "plugin.go"), templateData))
+		exitOnError(fileutils.ExecuteTemplateFromAssetsBox("plugin/README.md.tmpl", filepath.Join(build.BuildPath, "README.md"), templateData))
+		exitOnError(fileutils.ExecuteTemplateFromAssetsBox("plugin/import.go.tmpl.tmpl", filepath.Join(build.BuildPath, "import.go.tmpl"), templateData))
 
 		dlibPath := filepath.Join(build.BuildPath, "dlib")
 		err = os.Mkdir(dlibPath, 0775)
@@ -58,7 +57,7 @@ var createPluginCmd = &cobra.Command{
 			log.Errorf("Failed to create '%s' directory: %v", dlibPath, err)
 			os.Exit(1)
 		}
-		fileutils.ExecuteTemplateFromAssetsBox("plugin/README.md.dlib.tmpl", filepath.Join(dlibPath, "README.md"), fileutils.AssetsBox(), templateData)
+		exitOnError(fileutils.ExecuteTemplateFromAssetsBox("plugin/README.md.dlib.tmpl", filepath.Join(dlibPath, "README.md"), templateData))
 
 		platforms := []string{"darwin", "linux", "windows"}
 		for _, platform := range platforms {
@@ -11,7 +11,6 @@ import (
 	"github.com/go-flutter-desktop/hover/internal/config"
 	"github.com/go-flutter-desktop/hover/internal/fileutils"
 	"github.com/go-flutter-desktop/hover/internal/log"
-	"github.com/go-flutter-desktop/hover/internal/pubspec"
 )
 
 func init() {
@@ -30,7 +29,7 @@ var initCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		assertInFlutterProject()
 
-		projectName := pubspec.GetPubSpec().Name
+		projectName := getPubSpec().Name
 
 		var projectPath string
 		if len(args) == 0 || args[0] == "." {
@@ -65,15 +64,15 @@ var initCmd = &cobra.Command{
 
 		emptyConfig := config.Config{}
 
-		fileutils.CopyAsset("app/main.go", filepath.Join(desktopCmdPath, "main.go"), fileutils.AssetsBox())
-		fileutils.CopyAsset("app/options.go", filepath.Join(desktopCmdPath, "options.go"), fileutils.AssetsBox())
-		fileutils.CopyAsset("app/icon.png", filepath.Join(desktopAssetsPath, "icon.png"), fileutils.AssetsBox())
-		fileutils.CopyAsset("app/gitignore", filepath.Join(build.BuildPath, ".gitignore"), fileutils.AssetsBox())
-		fileutils.ExecuteTemplateFromAssetsBox("app/hover.yaml.tmpl", filepath.Join(build.BuildPath, "hover.yaml"), fileutils.AssetsBox(), map[string]string{
+		exitOnError(fileutils.CopyAsset("app/main.go", filepath.Join(desktopCmdPath, "main.go")))
+		exitOnError(fileutils.CopyAsset("app/options.go", filepath.Join(desktopCmdPath, "options.go")))
+		exitOnError(fileutils.CopyAsset("app/icon.png", filepath.Join(desktopAssetsPath, "icon.png")))
+		exitOnError(fileutils.CopyAsset("app/gitignore", filepath.Join(build.BuildPath, ".gitignore")))
+		exitOnError(fileutils.ExecuteTemplateFromAssetsBox("app/hover.yaml.tmpl", filepath.Join(build.BuildPath, "hover.yaml"), map[string]string{
 			"applicationName": emptyConfig.GetApplicationName(projectName),
 			"executableName":  emptyConfig.GetExecutableName(projectName),
 			"packageName":     emptyConfig.GetPackageName(projectName),
-		})
+		}))
 
 		initializeGoModule(projectPath)
 		log.Printf("Available plugin for this project:")
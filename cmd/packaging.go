@@ -1,21 +1,34 @@
 package cmd
 
 import (
+	"os"
+
 	"github.com/spf13/cobra"
 
-	"github.com/go-flutter-desktop/hover/cmd/packaging"
+	"github.com/go-flutter-desktop/hover/internal/log"
+	"github.com/go-flutter-desktop/hover/pkg/hover"
 )
 
+var initPackagingFlavor string
+
 func init() {
+	initPackagingCmd.PersistentFlags().StringVar(&initPackagingFlavor, "flavor", "", "Create the configuration files of a flavor of hover.yaml.")
 	initPackagingCmd.AddCommand(initLinuxSnapCmd)
 	initPackagingCmd.AddCommand(initLinuxDebCmd)
 	initPackagingCmd.AddCommand(initLinuxAppImageCmd)
 	initPackagingCmd.AddCommand(initLinuxRpmCmd)
 	initPackagingCmd.AddCommand(initLinuxPkgCmd)
+	initPackagingCmd.AddCommand(initLinuxTarCmd)
+	initPackagingCmd.AddCommand(initLinuxFlatpakCmd)
 	initPackagingCmd.AddCommand(initWindowsMsiCmd)
+	initPackagingCmd.AddCommand(initWindowsZipCmd)
+	initPackagingCmd.AddCommand(initWindowsNsisCmd)
+	initPackagingCmd.AddCommand(initWindowsMsixCmd)
+	initPackagingCmd.AddCommand(initLinuxNixCmd)
 	initPackagingCmd.AddCommand(initDarwinBundleCmd)
 	initPackagingCmd.AddCommand(initDarwinPkgCmd)
 	initPackagingCmd.AddCommand(initDarwinDmgCmd)
+	initPackagingCmd.AddCommand(initDarwinZipCmd)
 	rootCmd.AddCommand(initPackagingCmd)
 }
 
@@ -24,13 +37,22 @@ var initPackagingCmd = &cobra.Command{
 	Short: "Create configuration files for a packaging format",
 }
 
+// initPackaging creates the configuration files of a packaging format and
+// exits when it fails.
+func initPackaging(target string) {
+	assertHoverInitialized()
+	err := hover.InitPackaging(target, initPackagingFlavor)
+	if err != nil {
+		log.Errorf("%v", err)
+		os.Exit(1)
+	}
+}
+
 var initLinuxSnapCmd = &cobra.Command{
 	Use:   "linux-snap",
 	Short: "Create configuration files for snap packaging",
 	Run: func(cmd *cobra.Command, args []string) {
-		assertHoverInitialized()
-
-		packaging.LinuxSnapTask.Init()
+		initPackaging("linux-snap")
 	},
 }
 
@@ -38,9 +60,7 @@ var initLinuxDebCmd = &cobra.Command{
 	Use:   "linux-deb",
 	Short: "Create configuration files for deb packaging",
 	Run: func(cmd *cobra.Command, args []string) {
-		assertHoverInitialized()
-
-		packaging.LinuxDebTask.Init()
+		initPackaging("linux-deb")
 	},
 }
 
@@ -48,36 +68,28 @@ var initLinuxAppImageCmd = &cobra.Command{
 	Use:   "linux-appimage",
 	Short: "Create configuration files for AppImage packaging",
 	Run: func(cmd *cobra.Command, args []string) {
-		assertHoverInitialized()
-
-		packaging.LinuxAppImageTask.Init()
+		initPackaging("linux-appimage")
 	},
 }
 var initLinuxRpmCmd = &cobra.Command{
 	Use:   "linux-rpm",
 	Short: "Create configuration files for rpm packaging",
 	Run: func(cmd *cobra.Command, args []string) {
-		assertHoverInitialized()
-
-		packaging.LinuxRpmTask.Init()
+		initPackaging("linux-rpm")
 	},
 }
 var initLinuxPkgCmd = &cobra.Command{
 	Use:   "linux-pkg",
 	Short: "Create configuration files for pacman pkg packaging",
 	Run: func(cmd *cobra.Command, args []string) {
-		assertHoverInitialized()
-
-		packaging.LinuxPkgTask.Init()
+		initPackaging("linux-pkg")
 	},
 }
 var initWindowsMsiCmd = &cobra.Command{
 	Use:   "windows-msi",
 	Short: "Create configuration files for msi packaging",
 	Run: func(cmd *cobra.Command, args []string) {
-		assertHoverInitialized()
-
-		packaging.WindowsMsiTask.Init()
+		initPackaging("windows-msi")
 	},
 }
 
@@ -85,9 +97,7 @@ var initDarwinBundleCmd = &cobra.Command{
 	Use:   "darwin-bundle",
 	Short: "Create configuration files for OSX bundle packaging",
 	Run: func(cmd *cobra.Command, args []string) {
-		assertHoverInitialized()
-
-		packaging.DarwinBundleTask.Init()
+		initPackaging("darwin-bundle")
 	},
 }
 
@@ -95,9 +105,7 @@ var initDarwinPkgCmd = &cobra.Command{
 	Use:   "darwin-pkg",
 	Short: "Create configuration files for OSX pkg installer packaging",
 	Run: func(cmd *cobra.Command, args []string) {
-		assertHoverInitialized()
-
-		packaging.DarwinPkgTask.Init()
+		initPackaging("darwin-pkg")
 	},
 }
 
@@ -105,8 +113,62 @@ var initDarwinDmgCmd = &cobra.Command{
 	Use:   "darwin-dmg",
 	Short: "Create configuration files for OSX dmg packaging",
 	Run: func(cmd *cobra.Command, args []string) {
-		assertHoverInitialized()
+		initPackaging("darwin-dmg")
+	},
+}
+
+var initLinuxTarCmd = &cobra.Command{
+	Use:   "linux-tar",
+	Short: "Create configuration files for tar.gz packaging",
+	Run: func(cmd *cobra.Command, args []string) {
+		initPackaging("linux-tar")
+	},
+}
 
-		packaging.DarwinDmgTask.Init()
+var initLinuxFlatpakCmd = &cobra.Command{
+	Use:   "linux-flatpak",
+	Short: "Create configuration files for flatpak packaging",
+	Run: func(cmd *cobra.Command, args []string) {
+		initPackaging("linux-flatpak")
+	},
+}
+
+var initWindowsZipCmd = &cobra.Command{
+	Use:   "windows-zip",
+	Short: "Create configuration files for zip packaging",
+	Run: func(cmd *cobra.Command, args []string) {
+		initPackaging("windows-zip")
+	},
+}
+
+var initDarwinZipCmd = &cobra.Command{
+	Use:   "darwin-zip",
+	Short: "Create configuration files for OSX zip packaging",
+	Run: func(cmd *cobra.Command, args []string) {
+		initPackaging("darwin-zip")
+	},
+}
+
+var initWindowsNsisCmd = &cobra.Command{
+	Use:   "windows-nsis",
+	Short: "Create configuration files for nsis packaging",
+	Run: func(cmd *cobra.Command, args []string) {
+		initPackaging("windows-nsis")
+	},
+}
+
+var initWindowsMsixCmd = &cobra.Command{
+	Use:   "windows-msix",
+	Short: "Create configuration files for msix packaging",
+	Run: func(cmd *cobra.Command, args []string) {
+		initPackaging("windows-msix")
+	},
+}
+
+var initLinuxNixCmd = &cobra.Command{
+	Use:   "linux-nix",
+	Short: "Create configuration files for nix packaging",
+	Run: func(cmd *cobra.Command, args []string) {
+		initPackaging("linux-nix")
 	},
 }
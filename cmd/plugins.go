@@ -1,32 +1,26 @@
 package cmd
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"io/ioutil"
-	"net/http"
-	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
-	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"golang.org/x/mod/modfile"
-	"gopkg.in/yaml.v2"
 
 	"github.com/go-flutter-desktop/hover/internal/build"
 	"github.com/go-flutter-desktop/hover/internal/fileutils"
 	"github.com/go-flutter-desktop/hover/internal/log"
 	"github.com/go-flutter-desktop/hover/internal/modx"
-	"github.com/go-flutter-desktop/hover/internal/pubspec"
+	"github.com/go-flutter-desktop/hover/pkg/hover"
 )
 
-const standaloneImplementationListAPI = "https://raw.githubusercontent.com/go-flutter-desktop/plugins/master/list.json"
-
 var (
 	listAllPluginDependencies bool
 	tidyPurge                 bool
@@ -53,56 +47,6 @@ var pluginCmd = &cobra.Command{
 	Long:  "A collection of commands to help with finding/importing go-flutter implementations of plugins.",
 }
 
-// PubSpecLock contains the parsed contents of pubspec.lock
-type PubSpecLock struct {
-	Packages map[string]PubDep
-}
-
-// PubDep contains one entry of the pubspec.lock yaml list
-type PubDep struct {
-	Dependency  string
-	Description interface{}
-	Source      string
-	Version     string
-
-	// Fields set by hover
-	name    string
-	android bool
-	ios     bool
-	desktop bool
-	// optional description values
-	path string // correspond to the path field in lock file
-	host string // correspond to the host field in lock file
-	// contain a import.go.tmpl file used for import
-	autoImport bool
-	// the path/URL to the go code of the plugin is stored
-	pluginGoSource string
-	// whether or not the go plugin source code is located on another VCS repo.
-	standaloneImpl bool
-}
-
-func (p PubDep) imported() bool {
-	pluginImportOutPath := filepath.Join(build.BuildPath, "cmd", fmt.Sprintf("import-%s-plugin.go", p.name))
-	if _, err := os.Stat(pluginImportOutPath); err == nil {
-		return true
-	}
-	return false
-}
-
-func (p PubDep) platforms() []string {
-	var platforms []string
-	if p.android {
-		platforms = append(platforms, "android")
-	}
-	if p.ios {
-		platforms = append(platforms, "ios")
-	}
-	if p.desktop {
-		platforms = append(platforms, build.BuildPath)
-	}
-	return platforms
-}
-
 var pluginListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List golang platform plugins in the application",
@@ -114,43 +58,64 @@ var pluginListCmd = &cobra.Command{
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		assertInFlutterProject()
-		dependencyList, err := listPlatformPlugin()
+		dependencyList, err := hover.PluginsList(context.Background())
 		if err != nil {
 			log.Errorf("%v", err)
 			os.Exit(1)
 		}
 
+		if log.JSONOutput() {
+			plugins := []pluginResult{}
+			for _, dep := range dependencyList {
+				if !(dep.Desktop || listAllPluginDependencies) {
+					continue
+				}
+				plugins = append(plugins, pluginResult{
+					Name:           dep.Name,
+					Version:        dep.Version,
+					Platforms:      dep.Platforms(),
+					Desktop:        dep.Desktop,
+					Imported:       dep.Desktop && dep.Imported(),
+					Importable:     dep.AutoImport || dep.StandaloneImpl,
+					StandaloneImpl: dep.StandaloneImpl,
+					Path:           dep.Path,
+				})
+			}
+			log.Result(pluginsListResult{Plugins: plugins})
+			return
+		}
+
 		var hasNewPlugin bool
 		var hasPlugins bool
 		for _, dep := range dependencyList {
-			if !(dep.desktop || listAllPluginDependencies) {
+			if !(dep.Desktop || listAllPluginDependencies) {
 				continue
 			}
 
 			if hasPlugins {
-				fmt.Println("")
+				fmt.Fprintln(log.CommandOutput(), "")
 			}
 			hasPlugins = true
 
-			log.Infof("     - %s", dep.name)
+			log.Infof("     - %s", dep.Name)
 			log.Infof("         version:   %s", dep.Version)
-			log.Infof("         platforms: [%s]", strings.Join(dep.platforms(), ", "))
-			if dep.desktop {
-				if dep.standaloneImpl {
+			log.Infof("         platforms: [%s]", strings.Join(dep.Platforms(), ", "))
+			if dep.Desktop {
+				if dep.StandaloneImpl {
 					log.Infof("         source:    This go plugin isn't maintained by the official plugin creator.")
 				}
-				if dep.imported() {
+				if dep.Imported() {
 					log.Infof("         import:    [OK] The plugin is already imported in the project.")
 					continue
 				}
-				if dep.autoImport || dep.standaloneImpl {
+				if dep.AutoImport || dep.StandaloneImpl {
 					hasNewPlugin = true
 					log.Infof("         import:    [Missing] The plugin can be imported by hover.")
 				} else {
 					log.Infof("         import:    [Manual import] The plugin is missing the import.go.tmpl file required for hover import.")
 				}
-				if dep.path != "" {
-					log.Infof("         dev:       Plugin replaced in go.mod to path: '%s'", dep.path)
+				if dep.Path != "" {
+					log.Infof("         dev:       Plugin replaced in go.mod to path: '%s'", dep.Path)
 				}
 			}
 		}
@@ -160,6 +125,24 @@ var pluginListCmd = &cobra.Command{
 	},
 }
 
+// pluginsListResult is the JSON result of `hover plugins list`.
+type pluginsListResult struct {
+	Plugins []pluginResult `json:"plugins"`
+}
+
+type pluginResult struct {
+	Name      string   `json:"name"`
+	Version   string   `json:"version"`
+	Platforms []string `json:"platforms"`
+	// Desktop is set when the plugin has a go-flutter implementation.
+	Desktop  bool `json:"desktop"`
+	Imported bool `json:"imported"`
+	// Importable is set when hover is able to import the plugin.
+	Importable     bool   `json:"importable"`
+	StandaloneImpl bool   `json:"standaloneImpl"`
+	Path           string `json:"path,omitempty"`
+}
+
 var pluginTidyCmd = &cobra.Command{
 	Use:   "tidy",
 	Short: "Removes unused platform plugins.",
@@ -179,12 +162,12 @@ var pluginTidyCmd = &cobra.Command{
 
 		gomod, err = modx.Open(build.BuildPath)
 		if err != nil {
-			log.Errorf("failed to open go.mod", err)
+			log.Errorf("failed to open go.mod: %v", err)
 			os.Exit(1)
 		}
 
 		desktopCmdPath := filepath.Join(build.BuildPath, "cmd")
-		dependencyList, err := listPlatformPlugin()
+		dependencyList, err := hover.PluginsList(context.Background())
 		if err != nil {
 			log.Errorf("%v", err)
 			os.Exit(1)
@@ -207,7 +190,7 @@ var pluginTidyCmd = &cobra.Command{
 				pluginInUse := false
 
 				for _, dep := range dependencyList {
-					if dep.name == pluginName {
+					if dep.Name == pluginName {
 						// plugin in pubspec.lock
 						pluginInUse = true
 						break
@@ -288,58 +271,58 @@ var pluginGetCmd = &cobra.Command{
 }
 
 func hoverPluginGet(dryRun bool) bool {
-	dependencyList, err := listPlatformPlugin()
+	dependencyList, err := hover.PluginsList(context.Background())
 	if err != nil {
 		log.Errorf("%v", err)
 		os.Exit(1)
 	}
 
 	for _, dep := range dependencyList {
-		if !dep.desktop {
+		if !dep.Desktop {
 			continue
 		}
 
-		if !dep.autoImport {
-			log.Infof("       plugin: [%s] couldn't be imported, check the plugin's README for manual instructions", dep.name)
+		if !dep.AutoImport {
+			log.Infof("       plugin: [%s] couldn't be imported, check the plugin's README for manual instructions", dep.Name)
 			continue
 		}
 
 		if dryRun {
-			if dep.imported() {
-				log.Infof("       plugin: [%s] can be updated", dep.name)
+			if dep.Imported() {
+				log.Infof("       plugin: [%s] can be updated", dep.Name)
 			} else {
-				log.Infof("       plugin: [%s] can be imported", dep.name)
+				log.Infof("       plugin: [%s] can be imported", dep.Name)
 			}
 			continue
 		}
 
-		pluginImportOutPath := filepath.Join(build.BuildPath, "cmd", fmt.Sprintf("import-%s-plugin.go", dep.name))
-		if dep.imported() && !reImport {
-			pluginImportStr, err := readPluginGoImport(pluginImportOutPath, dep.name)
+		pluginImportOutPath := filepath.Join(build.BuildPath, "cmd", fmt.Sprintf("import-%s-plugin.go", dep.Name))
+		if dep.Imported() && !reImport {
+			pluginImportStr, err := readPluginGoImport(pluginImportOutPath, dep.Name)
 			if err != nil {
-				log.Warnf("Couldn't read the plugin '%s' import URL", dep.name)
+				log.Warnf("Couldn't read the plugin '%s' import URL", dep.Name)
 				log.Warnf("Fallback to the latest version installed.")
 				continue
 			}
 
 			if !goGetModuleSuccess(pluginImportStr, dep.Version) {
-				log.Warnf("Couldn't download version '%s' of plugin '%s'", dep.Version, dep.name)
+				log.Warnf("Couldn't download version '%s' of plugin '%s'", dep.Version, dep.Name)
 				log.Warnf("Fallback to the latest version installed.")
 				continue
 			}
 
-			log.Infof("       plugin: [%s] updated", dep.name)
+			log.Infof("       plugin: [%s] updated", dep.Name)
 			continue
 		}
 
-		if dep.standaloneImpl {
-			fileutils.DownloadFile(dep.pluginGoSource, pluginImportOutPath)
+		if dep.StandaloneImpl {
+			exitOnError(fileutils.DownloadFile(dep.GoSource, pluginImportOutPath))
 		} else {
-			autoImportTemplatePath := filepath.Join(dep.pluginGoSource, "import.go.tmpl")
-			fileutils.CopyFile(autoImportTemplatePath, pluginImportOutPath)
+			autoImportTemplatePath := filepath.Join(dep.GoSource, "import.go.tmpl")
+			exitOnError(fileutils.CopyFile(autoImportTemplatePath, pluginImportOutPath))
 
-			if fileutils.IsDirectory(filepath.Join(dep.pluginGoSource, "dlib")) {
-				dlibPath, err := filepath.Abs(filepath.Join(dep.pluginGoSource, "dlib"))
+			if fileutils.IsDirectory(filepath.Join(dep.GoSource, "dlib")) {
+				dlibPath, err := filepath.Abs(filepath.Join(dep.GoSource, "dlib"))
 				if err != nil {
 					log.Errorf("Failed to resolve absolute path for dlib directory: %v", err)
 					os.Exit(1)
@@ -351,34 +334,34 @@ func hoverPluginGet(dryRun bool) bool {
 					os.Exit(1)
 				}
 
-				fileutils.CopyDir(dlibPath, intermediatesDirectoryPath)
+				exitOnError(fileutils.CopyDir(dlibPath, intermediatesDirectoryPath))
 				if fileutils.IsFileExists(filepath.Join(dlibPath, "README.md")) {
-					readmeName := fmt.Sprintf("README-%s.md", dep.name)
-					fileutils.CopyFile(filepath.Join(dlibPath, "README.md"), filepath.Join(intermediatesDirectoryPath, readmeName))
+					readmeName := fmt.Sprintf("README-%s.md", dep.Name)
+					exitOnError(fileutils.CopyFile(filepath.Join(dlibPath, "README.md"), filepath.Join(intermediatesDirectoryPath, readmeName)))
 					_ = os.Remove(filepath.Join(intermediatesDirectoryPath, "README.md"))
 				}
 			}
 
-			pluginImportStr, err := readPluginGoImport(pluginImportOutPath, dep.name)
+			pluginImportStr, err := readPluginGoImport(pluginImportOutPath, dep.Name)
 			if err != nil {
-				log.Warnf("Couldn't read the plugin '%s' import URL", dep.name)
+				log.Warnf("Couldn't read the plugin '%s' import URL", dep.Name)
 				log.Warnf("Fallback to the latest version available on github.")
 				continue
 			}
 
 			// if remote plugin, get the correct version
-			if dep.path == "" {
+			if dep.Path == "" {
 				if !goGetModuleSuccess(pluginImportStr, dep.Version) {
-					log.Warnf("Couldn't download version '%s' of plugin '%s'", dep.Version, dep.name)
+					log.Warnf("Couldn't download version '%s' of plugin '%s'", dep.Version, dep.Name)
 					log.Warnf("Fallback to the latest version available on github.")
 				}
 			}
 
 			// if local plugin
-			if dep.path != "" {
-				path, err := filepath.Abs(filepath.Join(dep.path, build.BuildPath))
+			if dep.Path != "" {
+				path, err := filepath.Abs(filepath.Join(dep.Path, build.BuildPath))
 				if err != nil {
-					log.Errorf("Failed to resolve absolute path for plugin '%s': %v", dep.name, err)
+					log.Errorf("Failed to resolve absolute path for plugin '%s': %v", dep.Name, err)
 					os.Exit(1)
 				}
 
@@ -393,147 +376,13 @@ func hoverPluginGet(dryRun bool) bool {
 				}
 			}
 
-			log.Infof("       plugin: [%s] imported", dep.name)
+			log.Infof("       plugin: [%s] imported", dep.Name)
 		}
 	}
 
 	return len(dependencyList) != 0
 }
 
-func listPlatformPlugin() ([]PubDep, error) {
-	onlineList, err := fetchStandaloneImplementationList()
-	if err != nil {
-		log.Warnf("Warning, couldn't read the online plugin list: %v", err)
-	}
-
-	pubcachePath, err := findPubcachePath()
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to find path for pub-cache")
-	}
-
-	var list []PubDep
-	pubLock, err := readPubSpecLock()
-	if err != nil {
-		log.Infof("Run `%s` (or equivalent) first", log.Au().Magenta("flutter build bundle"))
-		return nil, err
-	}
-
-	for name, entry := range pubLock.Packages {
-		entry.name = name
-
-		switch i := entry.Description.(type) {
-		case string:
-			if i == "flutter" {
-				continue
-			}
-		case map[interface{}]interface{}:
-			if value, ok := i["path"]; ok {
-				entry.path = value.(string)
-			}
-			if value, ok := i["url"]; ok {
-				url, err := url.Parse(value.(string))
-				if err != nil {
-					return nil, errors.Wrap(err, "failed to parse URL from string %s"+value.(string))
-				}
-				entry.host = url.Host
-			}
-		}
-
-		pluginPath := filepath.Join(pubcachePath, "hosted", entry.host, entry.name+"-"+entry.Version)
-		if entry.path != "" {
-			pluginPath = entry.path
-		}
-
-		pluginPubspecPath := filepath.Join(pluginPath, "pubspec.yaml")
-		pluginPubspec, err := pubspec.ReadPubSpecFile(pluginPubspecPath)
-		if err != nil {
-			continue
-		}
-
-		// Non plugin package are likely to contain android/ios folders (even
-		// through they aren't used).
-		// To check if the package is really a platform plugin, we need to read
-		// the pubspec.yaml file. If he contains a Flutter/plugin entry, then
-		// it's a platform plugin.
-		if _, ok := pluginPubspec.Flutter["plugin"]; !ok {
-			continue
-		}
-
-		detectPlatformPlugin := func(platform string) (bool, error) {
-			platformPath := filepath.Join(pluginPath, platform)
-			stat, err := os.Stat(platformPath)
-			if err != nil {
-				if os.IsNotExist(err) {
-					return false, nil
-				}
-				return false, errors.Wrapf(err, "failed to stat %s", platformPath)
-			}
-			return stat.IsDir(), nil
-		}
-
-		entry.android, err = detectPlatformPlugin("android")
-		if err != nil {
-			return nil, err
-		}
-		entry.ios, err = detectPlatformPlugin("ios")
-		if err != nil {
-			return nil, err
-		}
-		entry.desktop, err = detectPlatformPlugin(build.BuildPath)
-		if err != nil {
-			return nil, err
-		}
-
-		if entry.desktop {
-			entry.pluginGoSource = filepath.Join(pluginPath, build.BuildPath)
-			autoImportTemplate := filepath.Join(entry.pluginGoSource, "import.go.tmpl")
-			_, err := os.Stat(autoImportTemplate)
-			entry.autoImport = true
-			if err != nil {
-				entry.autoImport = false
-				if !os.IsNotExist(err) {
-					return nil, errors.Wrapf(err, "failed to stat %s", autoImportTemplate)
-				}
-			}
-		} else {
-			// check if the plugin is available in github.com/go-flutter-desktop/plugins
-			for _, plugin := range onlineList {
-				if entry.name == plugin.Name {
-					entry.desktop = true
-					entry.standaloneImpl = true
-					entry.autoImport = true
-					entry.pluginGoSource = plugin.ImportFile
-					break
-				}
-			}
-		}
-
-		list = append(list, entry)
-
-	}
-	return list, nil
-}
-
-// readLocal reads pubspec.lock in the current working directory.
-func readPubSpecLock() (*PubSpecLock, error) {
-	p := &PubSpecLock{}
-	file, err := os.Open("pubspec.lock")
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, errors.New("no pubspec.lock file found")
-
-		}
-		return nil, errors.Wrap(err, "failed to open pubspec.lock")
-	}
-	defer file.Close()
-
-	err = yaml.NewDecoder(file).Decode(p)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to decode pubspec.lock")
-	}
-	return p, nil
-}
-
 func readPluginGoImport(pluginImportOutPath, pluginName string) (string, error) {
 	pluginImportBytes, err := ioutil.ReadFile(pluginImportOutPath)
 	if err != nil && !os.IsNotExist(err) {
@@ -550,59 +399,15 @@ func readPluginGoImport(pluginImportOutPath, pluginName string) (string, error)
 	return match[1], nil
 }
 
-type onlineList struct {
-	List []StandaloneImplementation `json:"standaloneImplementation"`
-}
-
-// StandaloneImplementation contains the go-flutter compatible plugins that
-// aren't merged into original VSC repo.
-type StandaloneImplementation struct {
-	Name       string `json:"name"`
-	ImportFile string `json:"importFile"`
-}
-
-func fetchStandaloneImplementationList() ([]StandaloneImplementation, error) {
-	remoteList := &onlineList{}
-
-	client := http.Client{
-		Timeout: time.Second * 20, // Maximum of 10 secs
-	}
-
-	req, err := http.NewRequest(http.MethodGet, standaloneImplementationListAPI, nil)
-	if err != nil {
-		return remoteList.List, err
-	}
-
-	res, err := client.Do(req)
-	if err != nil {
-		return remoteList.List, err
-	}
-
-	body, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return remoteList.List, err
-	}
-
-	if res.StatusCode != 200 {
-		return remoteList.List, errors.New(strings.TrimRight(string(body), "\r\n"))
-	}
-
-	err = json.Unmarshal(body, remoteList)
-	if err != nil {
-		return remoteList.List, err
-	}
-	return remoteList.List, nil
-}
-
 // goGetModuleSuccess updates a module at a version, if it fails, return false.
 func goGetModuleSuccess(pluginImportStr, version string) bool {
-	cmdGoGetU := exec.Command(build.GoBin(), "get", "-u", "-d", pluginImportStr+"@v"+version)
+	cmdGoGetU := exec.Command(binPath(build.GoBin()), "get", "-u", "-d", pluginImportStr+"@v"+version)
 	cmdGoGetU.Dir = filepath.Join(build.BuildPath)
 	cmdGoGetU.Env = append(os.Environ(),
 		"GOPROXY=direct", // github.com/golang/go/issues/32955 (allows '/' in branch name)
 		"GO111MODULE=on",
 	)
 	cmdGoGetU.Stderr = os.Stderr
-	cmdGoGetU.Stdout = os.Stdout
+	cmdGoGetU.Stdout = log.CommandOutput()
 	return cmdGoGetU.Run() == nil
 }
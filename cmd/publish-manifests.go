@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/go-flutter-desktop/hover/internal/log"
+	"github.com/go-flutter-desktop/hover/pkg/hover"
+)
+
+var (
+	publishManifestsBaseURL       string
+	publishManifestsFlavor        string
+	publishManifestsVersionNumber string
+)
+
+func init() {
+	publishManifestsCmd.Flags().StringVar(&publishManifestsBaseURL, "base-url", "", "The URL the artifacts are published at, e.g. https://github.com/<owner>/<repo>/releases/download/v1.0.0")
+	publishManifestsCmd.Flags().StringVar(&publishManifestsFlavor, "flavor", "", "The flavor of hover.yaml the artifacts were built for.")
+	publishManifestsCmd.Flags().StringVar(&publishManifestsVersionNumber, "version-number", "", "Override the version number of pubspec.yaml, it must match the version the artifacts were built with.")
+	publishManifestsCmd.MarkFlagRequired("base-url")
+	rootCmd.AddCommand(publishManifestsCmd)
+}
+
+var publishManifestsCmd = &cobra.Command{
+	Use:   "publish-manifests",
+	Short: "Generate the Homebrew cask, Scoop and winget manifests of the packaged artifacts",
+	Run: func(cmd *cobra.Command, args []string) {
+		assertHoverInitialized()
+
+		manifests, err := hover.PublishManifests(hover.PublishManifestsOptions{
+			BaseURL:       publishManifestsBaseURL,
+			Flavor:        publishManifestsFlavor,
+			VersionNumber: publishManifestsVersionNumber,
+		})
+		exitOnError(err)
+		log.Result(publishManifestsResult{Manifests: manifests})
+	},
+}
+
+// publishManifestsResult is the JSON result of `hover publish-manifests`.
+type publishManifestsResult struct {
+	// Manifests are the paths of the generated manifests.
+	Manifests []string `json:"manifests"`
+}
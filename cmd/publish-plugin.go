@@ -12,7 +12,6 @@ import (
 
 	"github.com/go-flutter-desktop/hover/internal/build"
 	"github.com/go-flutter-desktop/hover/internal/log"
-	"github.com/go-flutter-desktop/hover/internal/pubspec"
 	"github.com/spf13/cobra"
 )
 
@@ -32,7 +31,7 @@ var publishPluginCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		assertInFlutterPluginProject()
 		// check if dir 'go' is tracked
-		goCheckTrackedCmd := exec.Command(build.GitBin(), "ls-files", "--error-unmatch", build.BuildPath)
+		goCheckTrackedCmd := exec.Command(binPath(build.GitBin()), "ls-files", "--error-unmatch", build.BuildPath)
 		goCheckTrackedCmd.Stderr = os.Stderr
 		err := goCheckTrackedCmd.Run()
 		if err != nil {
@@ -41,11 +40,11 @@ var publishPluginCmd = &cobra.Command{
 		}
 
 		// check if dir 'go' is clean (all tracked files are committed)
-		goCheckCleanCmd := exec.Command(build.GitBin(), "status", "--untracked-file=no", "--porcelain", build.BuildPath)
+		goCheckCleanCmd := exec.Command(binPath(build.GitBin()), "status", "--untracked-file=no", "--porcelain", build.BuildPath)
 		goCheckCleanCmd.Stderr = os.Stderr
 		cleanOut, err := goCheckCleanCmd.Output()
 		if err != nil {
-			log.Errorf("Failed to check if '%s' is clean.", build.BuildPath, err)
+			log.Errorf("Failed to check if '%s' is clean: %v", build.BuildPath, err)
 			os.Exit(1)
 		}
 		if len(cleanOut) != 0 {
@@ -54,11 +53,11 @@ var publishPluginCmd = &cobra.Command{
 		}
 
 		// check if one of the git remote urls equals the package import 'url'
-		pluginImportStr, err := readPluginGoImport(filepath.Join(build.BuildPath, "import.go.tmpl"), pubspec.GetPubSpec().Name)
+		pluginImportStr, err := readPluginGoImport(filepath.Join(build.BuildPath, "import.go.tmpl"), getPubSpec().Name)
 		if err != nil {
 			log.Errorf("Failed to read the plugin import url: %v", err)
 			log.Infof("The file go/import.go.tmpl should look something like this:")
-			fmt.Printf(`package main
+			fmt.Fprintf(log.CommandOutput(), `package main
 
 import (
 	flutter "github.com/go-flutter-desktop/go-flutter"
@@ -66,7 +65,7 @@ import (
 )
 
 // .. [init function] ..
-      `, pubspec.GetPubSpec().Name, pubspec.GetPubSpec().Name)
+      `, getPubSpec().Name, getPubSpec().Name)
 			os.Exit(1)
 		}
 		url, err := url.Parse("https://" + pluginImportStr)
@@ -81,7 +80,7 @@ import (
 		path := strings.TrimPrefix(url.Path, "/")
 		path = strings.TrimSuffix(path, "/go")
 		re := regexp.MustCompile(`(\w+)\s+(\S+)` + url.Host + "." + path + ".git")
-		goCheckRemote := exec.Command(build.GitBin(), "remote", "-v")
+		goCheckRemote := exec.Command(binPath(build.GitBin()), "remote", "-v")
 		goCheckRemote.Stderr = os.Stderr
 		remoteOut, err := goCheckRemote.Output()
 		if err != nil {
@@ -93,33 +92,33 @@ import (
 			log.Warnf("At least one git remote urls must matchs the plugin golang import URL.")
 			log.Printf("go import URL: %s", pluginImportStr)
 			log.Printf("git remote -v:\n%s\n", string(remoteOut))
-			goCheckRemote.Stdout = os.Stdout
+			goCheckRemote.Stdout = log.CommandOutput()
 			//default to origin
 			log.Warnf("Assuming origin is where the plugin code is stored")
 			log.Printf(" This warning can occur because the git repo name dosn't match the plugin name in pubspec.yaml")
 			match = []string{"", "origin"}
 		}
 
-		tag := "go/v" + pubspec.GetPubSpec().GetVersion()
+		tag := "go/v" + getPubSpec().GetVersion()
 
-		log.Infof("Your plugin at version '%s' is ready to be publish as a golang module.", pubspec.GetPubSpec().GetVersion())
+		log.Infof("Your plugin at version '%s' is ready to be publish as a golang module.", getPubSpec().GetVersion())
 		log.Infof("Please run: `%s`", log.Au().Magenta("git tag "+tag))
 		log.Infof("            `%s`", log.Au().Magenta("git push "+match[1]+" "+tag))
 
 		log.Infof(fmt.Sprintf("Let hover run those commands? "))
 		if askForConfirmation() {
-			gitTag := exec.Command(build.GitBin(), "tag", tag)
+			gitTag := exec.Command(binPath(build.GitBin()), "tag", tag)
 			gitTag.Stderr = os.Stderr
-			gitTag.Stdout = os.Stdout
+			gitTag.Stdout = log.CommandOutput()
 			err = gitTag.Run()
 			if err != nil {
 				log.Errorf("The git command '%s' failed. Error: %v", gitTag.String(), err)
 				os.Exit(1)
 			}
 
-			gitPush := exec.Command(build.GitBin(), "push", match[1], tag)
+			gitPush := exec.Command(binPath(build.GitBin()), "push", match[1], tag)
 			gitPush.Stderr = os.Stderr
-			gitPush.Stdout = os.Stdout
+			gitPush.Stdout = log.CommandOutput()
 			err = gitPush.Run()
 			if err != nil {
 				log.Errorf("The git command '%s' failed. Error: %v", gitPush.String(), err)
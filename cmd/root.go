@@ -7,27 +7,44 @@ import (
 
 	"github.com/go-flutter-desktop/hover/internal/log"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var verbose bool
 var colors bool
 var docker bool
+var output string
+
+// commandFlags are the flags of the running command.
+var commandFlags *pflag.FlagSet
 
 func init() {
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "increase logging verbosity")
 	rootCmd.PersistentFlags().BoolVar(&colors, "colors", true, "Add colors to log")
 	rootCmd.PersistentFlags().BoolVar(&docker, "docker", false, "Run the command in a docker container for hover")
+	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", "text", "Output format: text, or json to print NDJSON events and results on stdout")
 }
 
 func initHover() {
 	log.Colorize(colors)
 	log.Verbosity(verbose)
+	switch output {
+	case "text":
+	case "json":
+		// Only the JSON events are written to stdout, the output of the
+		// commands run by hover (flutter, go, ...) is sent to stderr.
+		log.JSON(os.Stdout)
+		log.SetCommandOutput(os.Stderr)
+	default:
+		log.Errorf("Unknown output format '%s', use text or json", output)
+		os.Exit(1)
+	}
 
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
 	go func() {
 		for range c {
-			fmt.Println("")
+			fmt.Fprintln(log.CommandOutput(), "")
 			os.Exit(1)
 		}
 	}()
@@ -37,6 +54,9 @@ var rootCmd = &cobra.Command{
 	Use:   "hover",
 	Short: "Hover connects Flutter and go-flutter-desktop.",
 	Long:  "Hover helps developers to release Flutter applications on desktop.",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		commandFlags = cmd.Flags()
+	},
 }
 
 // Execute executes the rootCmd
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -12,18 +13,22 @@ import (
 
 	"github.com/spf13/cobra"
 
-	"github.com/go-flutter-desktop/hover/cmd/packaging"
 	"github.com/go-flutter-desktop/hover/internal/build"
 	"github.com/go-flutter-desktop/hover/internal/config"
+	"github.com/go-flutter-desktop/hover/internal/enginecache"
 	"github.com/go-flutter-desktop/hover/internal/log"
-	"github.com/go-flutter-desktop/hover/internal/pubspec"
+	"github.com/go-flutter-desktop/hover/pkg/hover"
 )
 
+var dotSlash = string([]byte{'.', filepath.Separator})
+
 var (
 	runObservatoryPort   string
 	runInitialRoute      string
 	runOmitEmbedder      bool
 	runOmitFlutterBundle bool
+	runProfile           string
+	runAOT               bool
 )
 
 func init() {
@@ -33,6 +38,8 @@ func init() {
 	runCmd.Flags().StringVarP(&runObservatoryPort, "observatory-port", "", "50300", "The observatory port used to connect hover to VM services (hot-reload/debug/..)")
 	runCmd.Flags().BoolVar(&runOmitFlutterBundle, "omit-flutter", false, "Don't (re)compile the current Flutter project, useful when only working with Golang code (plugin)")
 	runCmd.Flags().BoolVar(&runOmitEmbedder, "omit-embedder", false, "Don't (re)compile 'go-flutter' source code, useful when only working with Dart code")
+	runCmd.Flags().StringVar(&runProfile, "profile", config.BuildProfileDebug, "The build profile to use. Hot reload is only available with profiles using the debug mode.")
+	runCmd.Flags().BoolVar(&runAOT, "aot", false, "Compile the dart code ahead-of-time with gen_snapshot. Needs a profile using the profile or release mode.")
 	rootCmd.AddCommand(runCmd)
 }
 
@@ -40,8 +47,9 @@ var runCmd = &cobra.Command{
 	Use:   "run",
 	Short: "Build and start a desktop release, with hot-reload support",
 	Run: func(cmd *cobra.Command, args []string) {
-		projectName := pubspec.GetPubSpec().Name
+		projectName := getPubSpec().Name
 		assertHoverInitialized()
+		selectFlavor(buildOrRunFlavor)
 
 		// ensure we have something to build
 		if runOmitEmbedder && runOmitFlutterBundle {
@@ -49,43 +57,53 @@ var runCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		// Can only run on host OS
+		// Can only run on host OS and architecture
 		targetOS := runtime.GOOS
-
-		// forcefully enable --debug as it is not optional for 'hover run'
-		buildDebug = true
+		targetArch := runtime.GOARCH
+
+		// the build flags are shared with 'hover build', which uses another
+		// default profile
+		buildProfile = runProfile
+		buildAOT = runAOT
+		profile, err := getConfig().GetProfile(buildProfile)
+		if err != nil {
+			log.Errorf("%v", err)
+			os.Exit(1)
+		}
+		aot := profile.ForOS(targetOS).GetAOT()
+		if flagChanged("aot") {
+			aot = runAOT
+		}
+		// hot reload needs the JIT kernel of the debug mode
+		hotReload := profile.ForOS(targetOS).Mode == enginecache.ModeDebug && !aot
 
 		if runOmitFlutterBundle {
 			log.Infof("Omiting flutter build bundle")
 		} else {
 			// TODO: cleaning can't be enabled because it would break when users --omit-embedder.
 			// cleanBuildOutputsDir(targetOS)
-			buildFlutterBundle(targetOS)
+			prepareFlutterBundle()
 		}
 		if runOmitEmbedder {
 			log.Infof("Omiting build the embedder")
-		} else {
-			vmArguments := []string{"--observatory-port=" + runObservatoryPort, "--enable-service-port-fallback", "--disable-service-auth-codes"}
-			if buildOrRunDocker {
-				var buildFlags []string
-				buildFlags = append(buildFlags, commonFlags()...)
-				buildFlags = append(buildFlags, []string{
-					"--skip-flutter-build-bundle",
-					"--skip-engine-download",
-					"--debug",
-				}...)
-				dockerHoverBuild(targetOS, packaging.NoopTask, buildFlags, vmArguments)
-			} else {
-				buildGoBinary(targetOS, vmArguments)
-			}
+		}
+		opts := buildOptions([]string{targetOS})
+		opts.Arch = targetArch
+		opts.VMArguments = []string{"--observatory-port=" + runObservatoryPort, "--enable-service-port-fallback", "--disable-service-auth-codes"}
+		opts.SkipFlutterBuildBundle = runOmitFlutterBundle
+		opts.SkipGoBuild = runOmitEmbedder
+		_, err = hover.Build(context.Background(), opts)
+		if err != nil {
+			log.Errorf("%v", err)
+			os.Exit(1)
 		}
 		log.Infof("Build finished, starting app...")
-		runAndAttach(projectName, targetOS)
+		runAndAttach(projectName, targetOS, targetArch, hotReload)
 	},
 }
 
-func runAndAttach(projectName string, targetOS string) {
-	cmdApp := exec.Command(dotSlash + filepath.Join(build.BuildPath, "build", "outputs", targetOS, config.GetConfig().GetExecutableName(projectName)))
+func runAndAttach(projectName string, targetOS, targetArch string, hotReload bool) {
+	cmdApp := exec.Command(dotSlash + filepath.Join(build.BuildPath, "build", "outputs", build.TargetDirectoryName(build.FlavorDirectoryName(targetOS), targetArch), getConfig().GetExecutableName(projectName)))
 	cmdApp.Env = append(os.Environ(),
 		"GOFLUTTER_ROUTE="+runInitialRoute)
 	cmdFlutterAttach := exec.Command("flutter", "attach")
@@ -108,22 +126,26 @@ func runAndAttach(projectName string, targetOS string) {
 		scanner := bufio.NewScanner(reader)
 		for scanner.Scan() {
 			text := scanner.Text()
-			fmt.Println(text)
+			fmt.Fprintln(log.CommandOutput(), text)
 			match := regexObservatory.FindStringSubmatch(text)
-			if len(match) == 2 {
+			if len(match) == 2 && hotReload {
 				log.Infof("Connecting hover to '%s' for hot reload", projectName)
 				startHotReloadProcess(cmdFlutterAttach, buildOrRunFlutterTarget, match[1])
 				break
 			}
 		}
 		// echo command Stdout to terminal
-		io.Copy(os.Stdout, stdoutApp)
+		io.Copy(log.CommandOutput(), stdoutApp)
 	}(stdoutApp)
 
 	// Non-blockingly echo command stderr to terminal
 	go io.Copy(os.Stderr, stderrApp)
 
-	log.Infof("Running %s in debug mode", projectName)
+	if hotReload {
+		log.Infof("Running %s in debug mode", projectName)
+	} else {
+		log.Infof("Running %s with the %s profile, hot reload is disabled", projectName, buildProfile)
+	}
 	err = cmdApp.Start()
 	if err != nil {
 		log.Errorf("Failed to start app '%s': %v", projectName, err)
@@ -136,14 +158,16 @@ func runAndAttach(projectName string, targetOS string) {
 		os.Exit(cmdApp.ProcessState.ExitCode())
 	}
 	log.Infof("App '%s' exited.", projectName)
-	log.Printf("Closing the flutter attach sub process..")
-	cmdFlutterAttach.Wait()
+	if hotReload {
+		log.Printf("Closing the flutter attach sub process..")
+		cmdFlutterAttach.Wait()
+	}
 	os.Exit(0)
 }
 
 func startHotReloadProcess(cmdFlutterAttach *exec.Cmd, buildTargetMainDart string, uri string) {
 	cmdFlutterAttach.Stdin = os.Stdin
-	cmdFlutterAttach.Stdout = os.Stdout
+	cmdFlutterAttach.Stdout = log.CommandOutput()
 	cmdFlutterAttach.Stderr = os.Stderr
 
 	cmdFlutterAttach.Args = []string{
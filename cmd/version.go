@@ -3,10 +3,9 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"runtime/debug"
-	"sync"
 
 	"github.com/go-flutter-desktop/hover/internal/log"
+	"github.com/go-flutter-desktop/hover/pkg/hover"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -26,23 +25,24 @@ var versionCmd = &cobra.Command{
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		version := hoverVersion()
+		if log.JSONOutput() {
+			log.Result(versionResult{Version: version})
+			return
+		}
 		fmt.Printf("Hover %s\n", version)
 	},
 }
 
-var (
-	hoverVersionValue string
-	hoverVersionOnce  sync.Once
-)
+// versionResult is the JSON result of `hover version`.
+type versionResult struct {
+	Version string `json:"version"`
+}
 
 func hoverVersion() string {
-	hoverVersionOnce.Do(func() {
-		buildInfo, ok := debug.ReadBuildInfo()
-		if !ok {
-			log.Errorf("Cannot obtain version information from hover build. To resolve this, please go-get hover using Go 1.13 or newer.")
-			os.Exit(1)
-		}
-		hoverVersionValue = buildInfo.Main.Version
-	})
-	return hoverVersionValue
+	version := hover.Version()
+	if version == "" {
+		log.Errorf("Cannot obtain version information from hover build. To resolve this, please go-get hover using Go 1.13 or newer.")
+		os.Exit(1)
+	}
+	return version
 }
@@ -0,0 +1,183 @@
+// Package archive writes portable archives (.tar.gz and .zip) of a directory
+// that keep the modes and the symbolic links of its files.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// entry is a file of an archive.
+type entry struct {
+	name       string // slash separated path of the file in the archive, directories end with a slash
+	path       string // path of the file on disk
+	info       os.FileInfo
+	target     string // target of symbolic links
+	executable bool   // forced to mode 0755
+}
+
+// walk returns the files of root in lexical order, named after prefix: root
+// itself is the prefix/ directory. executables are the slash separated paths,
+// relative to root, of the files forced to mode 0755.
+func walk(root, prefix string, executables []string) ([]entry, error) {
+	isExecutable := make(map[string]bool, len(executables))
+	for _, executable := range executables {
+		isExecutable[executable] = true
+	}
+	var entries []entry
+	err := filepath.Walk(root, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+		e := entry{
+			name:       path.Join(prefix, filepath.ToSlash(relativePath)),
+			path:       filePath,
+			info:       info,
+			executable: isExecutable[filepath.ToSlash(relativePath)],
+		}
+		switch {
+		case info.IsDir():
+			e.name += "/"
+		case info.Mode()&os.ModeSymlink != 0:
+			e.target, err = os.Readlink(filePath)
+			if err != nil {
+				return err
+			}
+		case !info.Mode().IsRegular():
+			return errors.Errorf("%s is not a regular file", relativePath)
+		}
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the files of %s", root)
+	}
+	return entries, nil
+}
+
+// mode returns the permissions of a file in the archives: 0755 for
+// directories and executables, 0644 for other files. The archives don't
+// depend on the umask of the machine they are written on.
+func mode(e entry) os.FileMode {
+	if e.info.IsDir() || e.executable || e.info.Mode()&0111 != 0 {
+		return 0755
+	}
+	return 0644
+}
+
+// copyFile copies the content of a file to w.
+func copyFile(w io.Writer, filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(w, file)
+	return err
+}
+
+// WriteTarGz writes a gzip compressed tar archive of the files of root to w,
+// in a top-level directory named prefix. The files are owned by root and
+// keep their modification time. executables are the slash separated paths,
+// relative to root, of the files written with mode 0755 whatever their mode
+// on the host.
+func WriteTarGz(root, prefix string, w io.Writer, executables []string) error {
+	entries, err := walk(root, prefix, executables)
+	if err != nil {
+		return err
+	}
+	gzipWriter, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, e := range entries {
+		header := &tar.Header{
+			Name:    e.name,
+			Mode:    int64(mode(e)),
+			ModTime: e.info.ModTime(),
+			Uname:   "root",
+			Gname:   "root",
+		}
+		switch {
+		case e.info.IsDir():
+			header.Typeflag = tar.TypeDir
+		case e.target != "":
+			header.Typeflag = tar.TypeSymlink
+			header.Mode = 0777
+			header.Linkname = e.target
+		default:
+			header.Typeflag = tar.TypeReg
+			header.Size = e.info.Size()
+		}
+		err = tarWriter.WriteHeader(header)
+		if err != nil {
+			return errors.Wrapf(err, "failed to write the header of %s", e.name)
+		}
+		if header.Typeflag == tar.TypeReg {
+			err = copyFile(tarWriter, e.path)
+			if err != nil {
+				return errors.Wrapf(err, "failed to write %s", e.name)
+			}
+		}
+	}
+	err = tarWriter.Close()
+	if err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+// WriteZip writes a zip archive of the files of root to w, in a top-level
+// directory named prefix. The files keep their modification time, and
+// their unix modes are stored for the tools that restore them. executables
+// are forced to mode 0755, like the ones of WriteTarGz.
+func WriteZip(root, prefix string, w io.Writer, executables []string) error {
+	entries, err := walk(root, prefix, executables)
+	if err != nil {
+		return err
+	}
+	zipWriter := zip.NewWriter(w)
+	for _, e := range entries {
+		header := &zip.FileHeader{
+			Name:     e.name,
+			Method:   zip.Deflate,
+			Modified: e.info.ModTime().UTC(),
+		}
+		switch {
+		case e.info.IsDir():
+			header.Method = zip.Store
+			header.SetMode(os.ModeDir | mode(e))
+		case e.target != "":
+			header.SetMode(os.ModeSymlink | 0777)
+		default:
+			header.SetMode(mode(e))
+		}
+		fileWriter, err := zipWriter.CreateHeader(header)
+		if err != nil {
+			return errors.Wrapf(err, "failed to write the header of %s", e.name)
+		}
+		switch {
+		case e.info.IsDir():
+		case e.target != "":
+			_, err = io.WriteString(fileWriter, e.target)
+		default:
+			err = copyFile(fileWriter, e.path)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to write %s", e.name)
+		}
+	}
+	return zipWriter.Close()
+}
@@ -0,0 +1,95 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-flutter-desktop/hover/internal/testutil"
+)
+
+func testTree(t *testing.T) string {
+	dir := testutil.Tree(t, map[string]testutil.File{
+		"app":               {Content: "app", Mode: 0755},
+		"README.txt":        {Content: "README.txt", Mode: 0600},
+		"lib/app":           {Content: "lib/app"},
+		"lib/libflutter.so": {Content: "lib/libflutter.so"},
+	})
+	err := os.Chmod(filepath.Join(dir, "lib"), 0700)
+	require.Equal(t, err, nil, "failed to change the mode of the directory: %v", err)
+	if runtime.GOOS != "windows" {
+		err = os.Symlink("libflutter.so", filepath.Join(dir, "lib", "libflutter.so.1"))
+		require.Equal(t, err, nil, "failed to create symbolic link: %v", err)
+	}
+	return dir
+}
+
+func TestWriteTarGz(t *testing.T) {
+	dir := testTree(t)
+	var buf bytes.Buffer
+	err := WriteTarGz(dir, "app-1.0.0", &buf, []string{"lib/app"})
+	require.Equal(t, err, nil, "failed to write the archive: %v", err)
+
+	gzipReader, err := gzip.NewReader(&buf)
+	require.Equal(t, err, nil, "failed to read the archive: %v", err)
+	tarReader := tar.NewReader(gzipReader)
+	headers := make(map[string]*tar.Header)
+	var names []string
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.Equal(t, err, nil, "failed to read the archive: %v", err)
+		headers[header.Name] = header
+		names = append(names, header.Name)
+	}
+	require.Equal(t, "app-1.0.0/", names[0], "the archive must have a top-level directory")
+	require.Equal(t, int64(0755), headers["app-1.0.0/app"].Mode)
+	require.Equal(t, int64(0644), headers["app-1.0.0/README.txt"].Mode)
+	require.Equal(t, int64(0755), headers["app-1.0.0/lib/app"].Mode)
+	require.Equal(t, int64(0644), headers["app-1.0.0/lib/libflutter.so"].Mode)
+	require.Equal(t, int64(0755), headers["app-1.0.0/lib/"].Mode)
+	require.Equal(t, testutil.Epoch.Unix(), headers["app-1.0.0/app"].ModTime.Unix())
+	if runtime.GOOS != "windows" {
+		require.Equal(t, "libflutter.so", headers["app-1.0.0/lib/libflutter.so.1"].Linkname)
+	}
+}
+
+func TestWriteZip(t *testing.T) {
+	dir := testTree(t)
+	var buf bytes.Buffer
+	err := WriteZip(dir, "app-1.0.0", &buf, []string{"lib/app"})
+	require.Equal(t, err, nil, "failed to write the archive: %v", err)
+
+	zipReader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.Equal(t, err, nil, "failed to read the archive: %v", err)
+	files := make(map[string]*zip.File)
+	for _, file := range zipReader.File {
+		files[file.Name] = file
+	}
+	require.Equal(t, "app-1.0.0/", zipReader.File[0].Name, "the archive must have a top-level directory")
+	require.Equal(t, os.FileMode(0755), files["app-1.0.0/app"].Mode())
+	require.Equal(t, os.FileMode(0644), files["app-1.0.0/README.txt"].Mode())
+	require.Equal(t, os.FileMode(0755), files["app-1.0.0/lib/app"].Mode())
+	require.True(t, files["app-1.0.0/lib/"].Mode().IsDir())
+	require.Equal(t, testutil.Epoch.Unix(), files["app-1.0.0/app"].Modified.Unix())
+	if runtime.GOOS != "windows" {
+		link := files["app-1.0.0/lib/libflutter.so.1"]
+		require.Equal(t, os.ModeSymlink, link.Mode()&os.ModeSymlink)
+		reader, err := link.Open()
+		require.Equal(t, err, nil, "failed to open the link: %v", err)
+		target, err := ioutil.ReadAll(reader)
+		require.Equal(t, err, nil, "failed to read the link: %v", err)
+		require.Equal(t, "libflutter.so", string(target))
+	}
+}
@@ -1,39 +1,89 @@
 package build
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 
-	"github.com/go-flutter-desktop/hover/internal/log"
+	"github.com/pkg/errors"
 )
 
 // BuildPath sets the name of the directory used to store the go-flutter project.
 // Much like android and ios are already used.
 const BuildPath = "go"
 
+// DefaultArch is the architecture hover builds for when no other architecture
+// is requested.
+const DefaultArch = "amd64"
+
+// ValidateArch checks that hover is able to build for the given architecture.
+func ValidateArch(targetArch string) error {
+	switch targetArch {
+	case "amd64", "arm64":
+		return nil
+	default:
+		return fmt.Errorf("architecture %s is not supported, only amd64 and arm64 are", targetArch)
+	}
+}
+
+// TargetDirectoryName returns the name of the directory used for a target
+// (an OS or a packaging format) built for a specific architecture.
+// Builds for the DefaultArch use the plain target name, which keeps the
+// directory layout of single architecture projects unchanged.
+func TargetDirectoryName(target, targetArch string) string {
+	if targetArch == DefaultArch {
+		return target
+	}
+	return target + "-" + targetArch
+}
+
+// flavor is the product flavor being built, see SetFlavor.
+var flavor string
+
+// SetFlavor selects the product flavor being built. The build outputs and the
+// packaging templates of a flavor are stored in directories suffixed with its
+// name. An empty name selects the default flavor.
+func SetFlavor(name string) {
+	flavor = name
+}
+
+// Flavor returns the name of the selected product flavor, it is empty for the
+// default flavor.
+func Flavor() string {
+	return flavor
+}
+
+// FlavorDirectoryName returns the name of the directory used for a target
+// (an OS or a packaging format) of the selected flavor.
+func FlavorDirectoryName(target string) string {
+	if flavor == "" {
+		return target
+	}
+	return target + "-" + flavor
+}
+
 // buildDirectoryPath returns the path in `BuildPath`/build.
 // If needed, the directory is create at the returned path.
-func buildDirectoryPath(targetOS, path string) string {
-	outputDirectoryPath, err := filepath.Abs(filepath.Join(BuildPath, "build", path, targetOS))
+func buildDirectoryPath(targetDirectory, path string) (string, error) {
+	outputDirectoryPath, err := filepath.Abs(filepath.Join(BuildPath, "build", path, targetDirectory))
 	if err != nil {
-		log.Errorf("Failed to resolve absolute path for output directory: %v", err)
-		os.Exit(1)
+		return "", errors.Wrap(err, "failed to resolve absolute path for output directory")
 	}
 	if _, err := os.Stat(outputDirectoryPath); os.IsNotExist(err) {
 		err = os.MkdirAll(outputDirectoryPath, 0775)
 		if err != nil {
-			log.Errorf("Failed to create output directory %s: %v", outputDirectoryPath, err)
-			os.Exit(1)
+			return "", errors.Wrapf(err, "failed to create output directory %s", outputDirectoryPath)
 		}
 	}
-	return outputDirectoryPath
+	return outputDirectoryPath, nil
 }
 
 // OutputDirectoryPath returns the path where the go-flutter binary and flutter
-// binaries blobs will be stored for a particular platform.
-// If needed, the directory is create at the returned path.
-func OutputDirectoryPath(targetOS string) string {
-	return buildDirectoryPath(targetOS, "outputs")
+// binaries blobs will be stored for a particular platform, architecture and
+// flavor. The same directory layout is used for the outputs of packaging
+// formats. If needed, the directory is create at the returned path.
+func OutputDirectoryPath(target, targetArch string) (string, error) {
+	return buildDirectoryPath(TargetDirectoryName(FlavorDirectoryName(target), targetArch), "outputs")
 }
 
 // IntermediatesDirectoryPath returns the path where the intermediates stored.
@@ -42,13 +92,13 @@ func OutputDirectoryPath(targetOS string) string {
 // Those intermediates include the dynamic library dependencies of go-flutter plugins.
 // hover copies these intermediates from flutter plugins folder when `hover plugins get`, and
 // copies to go-flutter's binary output folder before build.
-func IntermediatesDirectoryPath(targetOS string) string {
+func IntermediatesDirectoryPath(targetOS string) (string, error) {
 	return buildDirectoryPath(targetOS, "intermediates")
 }
 
 // OutputBinary returns the string of the executable used to launch the
 // main desktop app. (appends .exe for windows)
-func OutputBinary(executableName, targetOS string) string {
+func OutputBinary(executableName, targetOS string) (string, error) {
 	var outputBinaryName = executableName
 	switch targetOS {
 	case "darwin":
@@ -58,32 +108,36 @@ func OutputBinary(executableName, targetOS string) string {
 	case "windows":
 		outputBinaryName += ".exe"
 	default:
-		log.Errorf("Target platform %s is not supported.", targetOS)
-		os.Exit(1)
+		return "", errors.Errorf("target platform %s is not supported", targetOS)
 	}
-	return outputBinaryName
+	return outputBinaryName, nil
 }
 
 // OutputBinaryPath returns the path to the go-flutter Application for a
-// specified platform.
-func OutputBinaryPath(executableName, targetOS string) string {
-	outputBinaryPath := filepath.Join(OutputDirectoryPath(targetOS), OutputBinary(executableName, targetOS))
-	return outputBinaryPath
+// specified platform and architecture.
+func OutputBinaryPath(executableName, targetOS, targetArch string) (string, error) {
+	outputDirectoryPath, err := OutputDirectoryPath(targetOS, targetArch)
+	if err != nil {
+		return "", err
+	}
+	outputBinary, err := OutputBinary(executableName, targetOS)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(outputDirectoryPath, outputBinary), nil
 }
 
 // EngineFilename returns the name of the engine file from flutter for the
 // specified platform.
-func EngineFilename(targetOS string) string {
+func EngineFilename(targetOS string) (string, error) {
 	switch targetOS {
 	case "darwin":
-		return "FlutterEmbedder.framework"
+		return "FlutterEmbedder.framework", nil
 	case "linux":
-		return "libflutter_engine.so"
+		return "libflutter_engine.so", nil
 	case "windows":
-		return "flutter_engine.dll"
+		return "flutter_engine.dll", nil
 	default:
-		log.Errorf("%s has no implemented engine file", targetOS)
-		os.Exit(1)
-		return ""
+		return "", errors.Errorf("%s has no implemented engine file", targetOS)
 	}
 }
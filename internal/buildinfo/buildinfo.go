@@ -0,0 +1,162 @@
+// Package buildinfo describes how build outputs were produced. A
+// build-info.json file is written next to every artifact.
+package buildinfo
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// Filename is the name of the build info file written next to the artifacts.
+const Filename = "build-info.json"
+
+// Info contains the provenance of an artifact.
+type Info struct {
+	// Target is the OS or the packaging format of the artifact, e.g.
+	// linux or linux-deb.
+	Target string `json:"target"`
+	// Arch is the GOARCH the artifact was built for.
+	Arch string `json:"arch"`
+	// Version is the version number of the application.
+	Version      string       `json:"version"`
+	Inputs       Inputs       `json:"inputs"`
+	Tools        Tools        `json:"tools"`
+	Git          *Git         `json:"git,omitempty"`
+	Dependencies Dependencies `json:"dependencies"`
+	// Files contains the files produced by the build. It is left empty in
+	// the build info embedded in the binary.
+	Files []File `json:"files,omitempty"`
+}
+
+// Inputs contains the build options.
+type Inputs struct {
+	FlutterTarget   string   `json:"flutterTarget"`
+	Profile         string   `json:"profile"`
+	AOT             bool     `json:"aot"`
+	Reproducible    bool     `json:"reproducible"`
+	SourceDateEpoch int64    `json:"sourceDateEpoch,omitempty"`
+	OpenGL          string   `json:"opengl"`
+	VMArguments     []string `json:"vmArguments"`
+	GoFlutterBranch string   `json:"goFlutterBranch,omitempty"`
+}
+
+// Tools contains the versions of the tools used by the build. Versions that
+// couldn't be determined are left empty.
+type Tools struct {
+	Hover          string `json:"hover"`
+	Go             string `json:"go"`
+	Flutter        string `json:"flutter,omitempty"`
+	FlutterChannel string `json:"flutterChannel,omitempty"`
+	Engine         string `json:"engine"`
+	GoFlutter      string `json:"goFlutter"`
+}
+
+// Git contains the state of the git repository of the project.
+type Git struct {
+	Commit string `json:"commit"`
+	Dirty  bool   `json:"dirty"`
+}
+
+// Dependencies contains the versions of the dart packages (including the
+// Flutter plugins) and of the go modules of the project, indexed by name.
+type Dependencies struct {
+	DartPackages map[string]string `json:"dartPackages"`
+	GoModules    map[string]string `json:"goModules"`
+}
+
+// File is a file produced by the build.
+type File struct {
+	// Path is the slash separated path of the file, relative to the
+	// directory of the build info.
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// AddFiles adds every file of the directory tree root to the files of the
+// build info, except the build info itself.
+func (i *Info) AddFiles(root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relativePath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if relativePath == Filename {
+			return nil
+		}
+		return i.AddFile(path, relativePath)
+	})
+}
+
+// AddFile adds a file to the files of the build info, under the given
+// relative path.
+func (i *Info) AddFile(path, relativePath string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, file)
+	if err != nil {
+		return errors.Wrapf(err, "failed to hash %s", path)
+	}
+	i.Files = append(i.Files, File{
+		Path:   filepath.ToSlash(relativePath),
+		SHA256: hex.EncodeToString(h.Sum(nil)),
+		Size:   size,
+	})
+	return nil
+}
+
+// Embedded returns the build info without its files, encoded to be set in
+// the binary with the -X ldflag.
+func (i Info) Embedded() (string, error) {
+	i.Files = nil
+	data, err := json.Marshal(i)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode build info")
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Write writes the build info to the build-info.json file of dir.
+func Write(dir string, info Info) error {
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode build info")
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, Filename), append(data, '\n'), 0644)
+	if err != nil {
+		return errors.Wrap(err, "failed to write build info")
+	}
+	return nil
+}
+
+// Read reads the build-info.json file of dir.
+func Read(dir string) (Info, error) {
+	var info Info
+	data, err := ioutil.ReadFile(filepath.Join(dir, Filename))
+	if err != nil {
+		return info, err
+	}
+	err = json.Unmarshal(data, &info)
+	if err != nil {
+		return info, errors.Wrap(err, "failed to decode build info")
+	}
+	return info, nil
+}
@@ -0,0 +1,151 @@
+// Package buildmanifest records the inputs of the build steps, so that steps
+// whose inputs didn't change since the last build can be skipped.
+package buildmanifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Inputs maps the name of every input of a build step to the hash of its
+// content. Flags and other values are stored as is.
+type Inputs map[string]string
+
+// AddValue adds a plain value (a flag, an environment variable, ...) to the
+// inputs.
+func (in Inputs) AddValue(name, value string) {
+	in["value:"+name] = value
+}
+
+// AddFile adds the content hash of a file to the inputs. Missing files are
+// recorded as such.
+func (in Inputs) AddFile(path string) error {
+	hash, err := hashFile(path)
+	if os.IsNotExist(err) {
+		in["file:"+filepath.ToSlash(path)] = "missing"
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	in["file:"+filepath.ToSlash(path)] = hash
+	return nil
+}
+
+// AddDir adds the content hash of every file in a directory tree to the
+// inputs. Directories for which skip returns true are not walked. A missing
+// directory doesn't add anything.
+func (in Inputs) AddDir(dir string, skip func(path string) bool) error {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if skip != nil && skip(path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		return in.AddFile(path)
+	})
+}
+
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	h := sha256.New()
+	_, err = io.Copy(h, file)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to hash %s", path)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Manifest contains the inputs of the last successful run of every build
+// step, indexed by step name.
+type Manifest struct {
+	path  string
+	Steps map[string]Inputs `json:"steps"`
+}
+
+// Open reads the manifest stored at path. A missing or unreadable manifest
+// results in an empty manifest, every step then has to run.
+func Open(path string) *Manifest {
+	m := &Manifest{
+		path:  path,
+		Steps: make(map[string]Inputs),
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return m
+	}
+	err = json.Unmarshal(data, m)
+	if err != nil || m.Steps == nil {
+		m.Steps = make(map[string]Inputs)
+	}
+	return m
+}
+
+// Changed returns why a step has to run again with the given inputs, or an
+// empty string when the inputs are the same as the ones recorded.
+func (m *Manifest) Changed(step string, inputs Inputs) string {
+	previous, ok := m.Steps[step]
+	if !ok {
+		return "no previous build recorded"
+	}
+	var changed []string
+	for name, value := range inputs {
+		if previousValue, ok := previous[name]; !ok || previousValue != value {
+			changed = append(changed, name)
+		}
+	}
+	for name := range previous {
+		if _, ok := inputs[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	if len(changed) == 0 {
+		return ""
+	}
+	sort.Strings(changed)
+	const maxListed = 5
+	if len(changed) > maxListed {
+		return fmt.Sprintf("%d inputs changed: %s and %d more", len(changed), strings.Join(changed[:maxListed], ", "), len(changed)-maxListed)
+	}
+	return fmt.Sprintf("%d inputs changed: %s", len(changed), strings.Join(changed, ", "))
+}
+
+// Record stores the inputs of a step that ran successfully and writes the
+// manifest to disk.
+func (m *Manifest) Record(step string, inputs Inputs) error {
+	m.Steps[step] = inputs
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode build manifest")
+	}
+	err = os.MkdirAll(filepath.Dir(m.path), 0775)
+	if err != nil {
+		return errors.Wrap(err, "failed to create build manifest directory")
+	}
+	err = ioutil.WriteFile(m.path, data, 0644)
+	if err != nil {
+		return errors.Wrap(err, "failed to write build manifest")
+	}
+	return nil
+}
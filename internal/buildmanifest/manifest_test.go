@@ -0,0 +1,49 @@
+package buildmanifest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestChanged(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hover-buildmanifest")
+	require.Equal(t, err, nil, "failed to create temporary directory: %v", err)
+	defer os.RemoveAll(dir)
+
+	sourcePath := filepath.Join(dir, "main.dart")
+	err = ioutil.WriteFile(sourcePath, []byte("void main() {}"), 0644)
+	require.Equal(t, err, nil, "failed to write source file: %v", err)
+
+	inputs := func() Inputs {
+		inputs := Inputs{}
+		inputs.AddValue("debug", "false")
+		err := inputs.AddDir(dir, nil)
+		require.Equal(t, err, nil, "failed to hash directory: %v", err)
+		return inputs
+	}
+
+	manifestPath := filepath.Join(dir, "build", "manifest.json")
+	manifest := Open(manifestPath)
+	require.NotEqual(t, manifest.Changed("linux/flutter-bundle", inputs()), "")
+
+	err = manifest.Record("linux/flutter-bundle", inputs())
+	require.Equal(t, err, nil, "failed to record step: %v", err)
+
+	manifest = Open(manifestPath)
+	previous := manifest.Steps["linux/flutter-bundle"]
+	require.Equal(t, len(previous), 2)
+	require.Equal(t, manifest.Changed("linux/flutter-bundle", previous), "")
+	require.NotEqual(t, manifest.Changed("windows/flutter-bundle", previous), "")
+
+	err = ioutil.WriteFile(sourcePath, []byte("void main() { print(1); }"), 0644)
+	require.Equal(t, err, nil, "failed to write source file: %v", err)
+	changed := Inputs{}
+	changed.AddValue("debug", "false")
+	err = changed.AddFile(sourcePath)
+	require.Equal(t, err, nil, "failed to hash file: %v", err)
+	require.Equal(t, manifest.Changed("linux/flutter-bundle", changed), "1 inputs changed: file:"+filepath.ToSlash(sourcePath))
+}
@@ -33,6 +33,27 @@ type Config struct {
 	CachePathREMOVED string `yaml:"cache-path"`
 	OpenGL           string
 	Engine           string `yaml:"engine-version"`
+	Arch             string
+	Targets          []string
+	Profiles         map[string]Profile
+	Flavors          map[string]Flavor
+	DartDefines      map[string]string `yaml:"dart-defines"`
+	FlutterBuildArgs []string          `yaml:"flutter-build-args"`
+	Docker           *bool
+	EmbedBuildInfo   *bool `yaml:"embed-build-info"`
+	Hooks            Hooks
+}
+
+// Hooks contains the commands run between the build steps. The commands of
+// a hook are run by the shell, in the project directory.
+type Hooks struct {
+	PreFlutterBundle []string `yaml:"pre-flutter-bundle"`
+	PreGoBuild       []string `yaml:"pre-go-build"`
+	PostGoBuild      []string `yaml:"post-go-build"`
+	// PrePackage contains the commands run in the staging directory of a
+	// package before it is packaged, indexed by packaging format.
+	PrePackage  map[string][]string `yaml:"pre-package"`
+	PostPackage []string            `yaml:"post-package"`
 }
 
 func (c Config) GetApplicationName(projectName string) string {
@@ -66,36 +87,41 @@ func (c Config) GetLicense() string {
 
 var (
 	config         Config
+	configErr      error
 	configLoadOnce sync.Once
 )
 
-// GetConfig returns the working directory hover.yaml as a Config
-func GetConfig() Config {
+// GetConfig returns the working directory hover.yaml as a Config, with the
+// settings of the selected flavor applied.
+func GetConfig() (Config, error) {
 	configLoadOnce.Do(func() {
-		var err error
-		config, err = ReadConfigFile(filepath.Join(build.BuildPath, "hover.yaml"))
-		if err != nil {
-			if os.IsNotExist(errors.Cause(err)) {
+		config, configErr = ReadConfigFile(filepath.Join(build.BuildPath, "hover.yaml"))
+		if configErr != nil {
+			if os.IsNotExist(errors.Cause(configErr)) {
 				// TODO: Add a solution for the user. Perhaps we can let `hover
 				// init` write missing files when ran on an existing project.
 				// https://github.com/go-flutter-desktop/hover/pull/121#pullrequestreview-408680348
-				log.Warnf("Missing config: %v", err)
+				log.Warnf("Missing config: %v", configErr)
+				configErr = nil
 				return
 			}
-			log.Errorf("Failed to load config: %v", err)
-			os.Exit(1)
+			configErr = errors.Wrap(configErr, "failed to load config")
+			return
 		}
 
 		if config.CachePathREMOVED != "" {
-			log.Errorf("The hover.yaml field 'cache-path' is not used anymore. Remove it from your hover.yaml and use --cache-path instead.")
-			os.Exit(1)
-		}
-		if config.BranchREMOVED != "" {
-			log.Errorf("The hover.yaml field 'branch' is not used anymore. Remove it from your hover.yaml and use --branch instead.")
-			os.Exit(1)
+			configErr = errors.New("the hover.yaml field 'cache-path' is not used anymore. Remove it from your hover.yaml and use --cache-path instead")
+		} else if config.BranchREMOVED != "" {
+			configErr = errors.New("the hover.yaml field 'branch' is not used anymore. Remove it from your hover.yaml and use --branch instead")
 		}
 	})
-	return config
+	if configErr != nil {
+		return Config{}, configErr
+	}
+	if flavor := build.Flavor(); flavor != "" {
+		return config.withFlavor(flavor), nil
+	}
+	return config, nil
 }
 
 // ReadConfigFile reads a .yaml file at a path and return a correspond Config
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/hover/internal/build"
+)
+
+// Flavor is a variant of the app built from the same project, declared in
+// the flavors field of hover.yaml. The fields that are set override the ones
+// of hover.yaml.
+type Flavor struct {
+	Target          string
+	ApplicationName string `yaml:"application-name"`
+	ExecutableName  string `yaml:"executable-name"`
+	PackageName     string `yaml:"package-name"`
+	// Icon is the path of the PNG or SVG icon of the flavor, relative to the
+	// project directory. It replaces the icons of go/assets in the build
+	// outputs.
+	Icon string
+	// DartDefines are added to the dart-defines of hover.yaml.
+	DartDefines map[string]string `yaml:"dart-defines"`
+}
+
+var flavorNameRegexp = regexp.MustCompile(`^[a-z0-9]+$`)
+
+// SelectFlavor selects the flavor being built. The settings of the flavor are
+// applied to the config returned by GetConfig. An empty name selects the
+// default flavor, the one described by hover.yaml.
+func SelectFlavor(name string) error {
+	if name != "" {
+		config, err := GetConfig()
+		if err != nil {
+			return err
+		}
+		if _, ok := config.Flavors[name]; !ok {
+			return errors.Errorf("unknown flavor '%s', the flavors of hover.yaml are: %s", name, strings.Join(config.FlavorNames(), ", "))
+		}
+		if !flavorNameRegexp.MatchString(name) {
+			return errors.Errorf("invalid flavor name '%s', only lowercase a-z and numbers are allowed", name)
+		}
+	}
+	build.SetFlavor(name)
+	return nil
+}
+
+// FlavorNames returns the sorted names of the flavors of hover.yaml.
+func (c Config) FlavorNames() []string {
+	var names []string
+	for name := range c.Flavors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetIcon returns the path of the icon of the selected flavor. It is empty
+// when the flavor uses the icons of go/assets.
+func (c Config) GetIcon() string {
+	return c.Flavors[build.Flavor()].Icon
+}
+
+// withFlavor returns a copy of the config with the settings of a flavor
+// applied.
+func (c Config) withFlavor(name string) Config {
+	flavor := c.Flavors[name]
+	if flavor.Target != "" {
+		c.Target = flavor.Target
+	}
+	if flavor.ApplicationName != "" {
+		c.ApplicationName = flavor.ApplicationName
+	}
+	if flavor.ExecutableName != "" {
+		c.ExecutableName = flavor.ExecutableName
+	}
+	if flavor.PackageName != "" {
+		c.PackageName = flavor.PackageName
+	}
+	if len(flavor.DartDefines) > 0 {
+		dartDefines := make(map[string]string)
+		for key, value := range c.DartDefines {
+			dartDefines[key] = value
+		}
+		for key, value := range flavor.DartDefines {
+			dartDefines[key] = value
+		}
+		c.DartDefines = dartDefines
+	}
+	return c
+}
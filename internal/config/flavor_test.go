@@ -0,0 +1,32 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+const flavorsConfig = `
+application-name: Acme
+executable-name: acme
+target: lib/main_desktop.dart
+flavors:
+  beta:
+    application-name: Acme Beta
+    target: lib/main_beta.dart
+    icon: assets/icon-beta.png
+`
+
+func TestWithFlavor(t *testing.T) {
+	var c Config
+	err := yaml.Unmarshal([]byte(flavorsConfig), &c)
+	require.Equal(t, err, nil, "failed to decode config: %v", err)
+	require.Equal(t, c.FlavorNames(), []string{"beta"})
+
+	beta := c.withFlavor("beta")
+	require.Equal(t, beta.GetApplicationName("acme"), "Acme Beta")
+	require.Equal(t, beta.Target, "lib/main_beta.dart")
+	// fields left empty by the flavor are inherited from hover.yaml
+	require.Equal(t, beta.GetExecutableName("acme"), "acme")
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// BuildProfileDefault Default build profile of `hover build`
+const BuildProfileDefault = "release"
+
+// BuildProfileDebug Build profile used by `hover run` and `hover build --debug`
+const BuildProfileDebug = "debug"
+
+// Profile contains the build settings of a profile listed in the `profiles`
+// field of hover.yaml. Fields left empty are inherited from the profile named
+// in Extends.
+type Profile struct {
+	// Extends is the profile this profile is based on, defaults to release
+	// for custom profiles.
+	Extends string
+	// Mode is the runtime mode of the Flutter engine: debug, profile or
+	// release.
+	Mode string
+	// AOT compiles the dart code to a native app.so snapshot with
+	// gen_snapshot, instead of shipping the JIT kernel of the flutter bundle.
+	// It needs the profile or release mode.
+	AOT *bool `yaml:"aot"`
+	// TrackWidgetCreation is passed to `flutter build bundle`, it is needed
+	// by the Flutter inspector.
+	TrackWidgetCreation *bool `yaml:"track-widget-creation"`
+	// VMArguments are passed to the Dart VM of the built application.
+	VMArguments []string `yaml:"vm-arguments"`
+	// BuildTags are added to the go build tags.
+	BuildTags []string `yaml:"build-tags"`
+	// Ldflags are added to the go build ldflags.
+	Ldflags []string
+	// Trimpath and Race enable the go build flags of the same name.
+	Trimpath *bool
+	Race     *bool
+	// OpenGL overrides the OpenGL version of hover.yaml.
+	OpenGL string
+	// Strip removes the debug symbols from the go binary and, on linux, from
+	// the Flutter engine.
+	Strip *bool
+	// Overrides contains profile settings applied for a single target OS,
+	// indexed by GOOS.
+	Overrides map[string]Profile
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// builtinProfiles are the profiles available without configuration. They can
+// be tweaked by declaring a profile of the same name in hover.yaml.
+var builtinProfiles = map[string]Profile{
+	"debug": {
+		Mode:                "debug",
+		TrackWidgetCreation: boolPtr(true),
+		VMArguments:         []string{},
+		Strip:               boolPtr(false),
+	},
+	"profile": {
+		Mode:                "profile",
+		TrackWidgetCreation: boolPtr(false),
+		VMArguments:         []string{"--disable-dart-asserts"},
+		Strip:               boolPtr(true),
+		Overrides: map[string]Profile{
+			"windows": {Ldflags: []string{"-H=windowsgui"}},
+		},
+	},
+	"release": {
+		Mode:                "release",
+		TrackWidgetCreation: boolPtr(false),
+		VMArguments:         []string{"--disable-dart-asserts", "--disable-observatory"},
+		Strip:               boolPtr(true),
+		Overrides: map[string]Profile{
+			"windows": {Ldflags: []string{"-H=windowsgui"}},
+		},
+	},
+}
+
+// GetProfile returns the profile with the given name, with the settings of
+// the profiles it extends filled in.
+func (c Config) GetProfile(name string) (Profile, error) {
+	return c.resolveProfile(name, nil)
+}
+
+func (c Config) resolveProfile(name string, seen []string) (Profile, error) {
+	for _, s := range seen {
+		if s == name {
+			return Profile{}, errors.Errorf("profile '%s' extends itself: %s", name, strings.Join(append(seen, name), " -> "))
+		}
+	}
+	seen = append(seen, name)
+
+	builtin, isBuiltin := builtinProfiles[name]
+	profile, ok := c.Profiles[name]
+	if !ok && !isBuiltin {
+		return Profile{}, errors.Errorf("unknown profile '%s', available profiles are: %s", name, strings.Join(c.ProfileNames(), ", "))
+	}
+
+	var base Profile
+	switch {
+	case profile.Extends != "":
+		var err error
+		base, err = c.resolveProfile(profile.Extends, seen)
+		if err != nil {
+			return Profile{}, err
+		}
+	case isBuiltin:
+		base = builtin
+	default:
+		var err error
+		base, err = c.resolveProfile(BuildProfileDefault, seen)
+		if err != nil {
+			return Profile{}, err
+		}
+	}
+	merged := base.merge(profile)
+	merged.Extends = ""
+	return merged, nil
+}
+
+// ProfileNames returns the names of the built-in profiles and of the
+// profiles declared in hover.yaml.
+func (c Config) ProfileNames() []string {
+	var names []string
+	for name := range builtinProfiles {
+		names = append(names, name)
+	}
+	for name := range c.Profiles {
+		if _, ok := builtinProfiles[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ForOS returns the profile with the overrides of the target OS applied.
+func (p Profile) ForOS(targetOS string) Profile {
+	override, ok := p.Overrides[targetOS]
+	p.Overrides = nil
+	if !ok {
+		return p
+	}
+	override.Overrides = nil
+	return p.merge(override)
+}
+
+// merge returns p with the fields set in o replacing its own. Overrides of
+// the same OS are merged.
+func (p Profile) merge(o Profile) Profile {
+	if o.Mode != "" {
+		p.Mode = o.Mode
+	}
+	if o.AOT != nil {
+		p.AOT = o.AOT
+	}
+	if o.TrackWidgetCreation != nil {
+		p.TrackWidgetCreation = o.TrackWidgetCreation
+	}
+	if o.VMArguments != nil {
+		p.VMArguments = o.VMArguments
+	}
+	if o.BuildTags != nil {
+		p.BuildTags = o.BuildTags
+	}
+	if o.Ldflags != nil {
+		p.Ldflags = o.Ldflags
+	}
+	if o.Trimpath != nil {
+		p.Trimpath = o.Trimpath
+	}
+	if o.Race != nil {
+		p.Race = o.Race
+	}
+	if o.OpenGL != "" {
+		p.OpenGL = o.OpenGL
+	}
+	if o.Strip != nil {
+		p.Strip = o.Strip
+	}
+	if len(o.Overrides) > 0 {
+		overrides := make(map[string]Profile)
+		for targetOS, override := range p.Overrides {
+			overrides[targetOS] = override
+		}
+		for targetOS, override := range o.Overrides {
+			overrides[targetOS] = overrides[targetOS].merge(override)
+		}
+		p.Overrides = overrides
+	}
+	return p
+}
+
+func (p Profile) GetAOT() bool {
+	return p.AOT != nil && *p.AOT
+}
+
+func (p Profile) GetTrackWidgetCreation() bool {
+	return p.TrackWidgetCreation != nil && *p.TrackWidgetCreation
+}
+
+func (p Profile) GetTrimpath() bool {
+	return p.Trimpath != nil && *p.Trimpath
+}
+
+func (p Profile) GetRace() bool {
+	return p.Race != nil && *p.Race
+}
+
+func (p Profile) GetStrip() bool {
+	return p.Strip != nil && *p.Strip
+}
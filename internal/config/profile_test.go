@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+const profilesConfig = `
+profiles:
+  staging:
+    vm-arguments: ["--disable-dart-asserts"]
+    build-tags: ["staging"]
+    overrides:
+      linux:
+        strip: false
+  release:
+    trimpath: true
+  loop:
+    extends: loop
+`
+
+func TestGetProfile(t *testing.T) {
+	var c Config
+	err := yaml.Unmarshal([]byte(profilesConfig), &c)
+	require.Equal(t, err, nil, "failed to decode config: %v", err)
+
+	debug, err := c.GetProfile("debug")
+	require.Equal(t, err, nil, "failed to get profile: %v", err)
+	require.Equal(t, debug.GetStrip(), false)
+	require.Equal(t, debug.GetTrackWidgetCreation(), true)
+
+	staging, err := c.GetProfile("staging")
+	require.Equal(t, err, nil, "failed to get profile: %v", err)
+	require.Equal(t, staging.VMArguments, []string{"--disable-dart-asserts"})
+	require.Equal(t, staging.BuildTags, []string{"staging"})
+	// inherited from the release profile, including the hover.yaml tweaks
+	require.Equal(t, staging.GetStrip(), true)
+	require.Equal(t, staging.GetTrimpath(), true)
+	require.Equal(t, staging.ForOS("windows").Ldflags, []string{"-H=windowsgui"})
+	require.Equal(t, staging.ForOS("linux").GetStrip(), false)
+	require.Equal(t, staging.ForOS("linux").Ldflags, []string(nil))
+
+	_, err = c.GetProfile("loop")
+	require.NotEqual(t, err, nil)
+	_, err = c.GetProfile("unknown")
+	require.NotEqual(t, err, nil)
+}
@@ -0,0 +1,222 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/hover/internal/build"
+)
+
+// Names of the settings, they are the names of the command line flags and of
+// the hover.yaml fields.
+const (
+	SettingTarget         = "target"
+	SettingOpenGL         = "opengl"
+	SettingEngineVersion  = "engine-version"
+	SettingArch           = "arch"
+	SettingDocker         = "docker"
+	SettingEmbedBuildInfo = "embed-build-info"
+)
+
+// Source is where the effective value of a setting comes from.
+type Source string
+
+// Sources of the settings, from the highest to the lowest precedence.
+const (
+	SourceFlag       Source = "flag"
+	SourceEnv        Source = "env"
+	SourceHoverYAML  Source = "hover.yaml"
+	SourceUserConfig Source = "user config"
+	SourceDefault    Source = "default"
+)
+
+// Value is the effective value of a setting.
+type Value struct {
+	Name   string
+	Value  string
+	Source Source
+	// Origin is the flag, the environment variable or the file the value
+	// was read from. It is empty for default values.
+	Origin string
+}
+
+// Bool returns the value of a boolean setting.
+func (v Value) Bool() bool {
+	b, _ := strconv.ParseBool(v.Value)
+	return b
+}
+
+// setting describes how a setting is resolved.
+type setting struct {
+	name         string
+	env          string
+	isBool       bool
+	defaultValue string
+	// fromConfig returns the value of the setting in a config file, and
+	// whether the setting is set.
+	fromConfig func(Config) (string, bool)
+}
+
+func stringField(value string) (string, bool) {
+	return value, value != ""
+}
+
+func boolField(value *bool) (string, bool) {
+	if value == nil {
+		return "", false
+	}
+	return strconv.FormatBool(*value), true
+}
+
+var settings = []setting{
+	{
+		name:         SettingTarget,
+		env:          "HOVER_TARGET",
+		defaultValue: BuildTargetDefault,
+		fromConfig:   func(c Config) (string, bool) { return stringField(c.Target) },
+	},
+	{
+		name:         SettingOpenGL,
+		env:          "HOVER_OPENGL",
+		defaultValue: BuildOpenGlVersionDefault,
+		fromConfig:   func(c Config) (string, bool) { return stringField(c.OpenGL) },
+	},
+	{
+		name:         SettingEngineVersion,
+		env:          "HOVER_ENGINE_VERSION",
+		defaultValue: BuildEngineDefault,
+		fromConfig:   func(c Config) (string, bool) { return stringField(c.Engine) },
+	},
+	{
+		name:         SettingArch,
+		env:          "HOVER_ARCH",
+		defaultValue: build.DefaultArch,
+		fromConfig:   func(c Config) (string, bool) { return stringField(c.Arch) },
+	},
+	{
+		name:         SettingDocker,
+		env:          "HOVER_DOCKER",
+		isBool:       true,
+		defaultValue: "false",
+		fromConfig:   func(c Config) (string, bool) { return boolField(c.Docker) },
+	},
+	{
+		name:         SettingEmbedBuildInfo,
+		env:          "HOVER_EMBED_BUILD_INFO",
+		isBool:       true,
+		defaultValue: "false",
+		fromConfig:   func(c Config) (string, bool) { return boolField(c.EmbedBuildInfo) },
+	},
+}
+
+// Resolve returns the effective value of a setting that isn't set on the
+// command line. The value is taken from the first source setting it: the
+// HOVER_* environment variable, hover.yaml, the user config and the default
+// value.
+func Resolve(name string) (Value, error) {
+	hoverConfig, userConfig, err := configs()
+	if err != nil {
+		return Value{}, err
+	}
+	for _, s := range settings {
+		if s.name == name {
+			return s.resolve(nil, hoverConfig, userConfig)
+		}
+	}
+	return Value{}, errors.Errorf("unknown setting %s", name)
+}
+
+// ResolveAll returns the effective value of every setting. The values of the
+// command line flags, indexed by setting name, take precedence over every
+// other source.
+func ResolveAll(flags map[string]string) ([]Value, error) {
+	hoverConfig, userConfig, err := configs()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]Value, len(settings))
+	for i, s := range settings {
+		values[i], err = s.resolve(flags, hoverConfig, userConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// configs returns hover.yaml and the user config.
+func configs() (Config, Config, error) {
+	hoverConfig, err := GetConfig()
+	if err != nil {
+		return Config{}, Config{}, err
+	}
+	userConfig, err := GetUserConfig()
+	if err != nil {
+		return Config{}, Config{}, err
+	}
+	return hoverConfig, userConfig, nil
+}
+
+func (s setting) resolve(flags map[string]string, hoverConfig, userConfig Config) (Value, error) {
+	if value, ok := flags[s.name]; ok {
+		return Value{Name: s.name, Value: value, Source: SourceFlag, Origin: "--" + s.name}, nil
+	}
+	if value, ok := os.LookupEnv(s.env); ok && value != "" {
+		if s.isBool {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return Value{}, errors.Errorf("invalid value '%s' for %s, use true or false", value, s.env)
+			}
+			value = strconv.FormatBool(b)
+		}
+		return Value{Name: s.name, Value: value, Source: SourceEnv, Origin: s.env}, nil
+	}
+	if value, ok := s.fromConfig(hoverConfig); ok {
+		return Value{Name: s.name, Value: value, Source: SourceHoverYAML, Origin: filepath.Join(build.BuildPath, "hover.yaml")}, nil
+	}
+	if value, ok := s.fromConfig(userConfig); ok {
+		return Value{Name: s.name, Value: value, Source: SourceUserConfig, Origin: UserConfigPath()}, nil
+	}
+	return Value{Name: s.name, Value: s.defaultValue, Source: SourceDefault}, nil
+}
+
+var (
+	userConfig         Config
+	userConfigErr      error
+	userConfigLoadOnce sync.Once
+)
+
+// UserConfigPath returns the path of the user config, the config shared by
+// the projects of the user. It is empty when the user config directory is
+// unknown.
+func UserConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "hover", "config.yaml")
+}
+
+// GetUserConfig returns the user config. It holds the same fields as
+// hover.yaml, and is empty when the file doesn't exist.
+func GetUserConfig() (Config, error) {
+	userConfigLoadOnce.Do(func() {
+		path := UserConfigPath()
+		if path == "" {
+			return
+		}
+		userConfig, userConfigErr = ReadConfigFile(path)
+		if userConfigErr != nil {
+			if os.IsNotExist(errors.Cause(userConfigErr)) {
+				userConfigErr = nil
+				return
+			}
+			userConfigErr = errors.Wrapf(userConfigErr, "failed to load the user config %s", path)
+		}
+	})
+	return userConfig, userConfigErr
+}
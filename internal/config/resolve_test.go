@@ -0,0 +1,63 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestResolve(t *testing.T) {
+	var hoverConfig, userConfig Config
+	err := yaml.Unmarshal([]byte("opengl: \"none\"\ndocker: false\n"), &hoverConfig)
+	require.Equal(t, err, nil, "failed to decode config: %v", err)
+	err = yaml.Unmarshal([]byte("opengl: \"2.1\"\narch: arm64\ndocker: true\n"), &userConfig)
+	require.Equal(t, err, nil, "failed to decode config: %v", err)
+
+	resolve := func(s setting, hoverConfig, userConfig Config) Value {
+		value, err := s.resolve(nil, hoverConfig, userConfig)
+		require.Equal(t, err, nil, "failed to resolve %s: %v", s.name, err)
+		return value
+	}
+
+	var openGL, arch, docker setting
+	for _, s := range settings {
+		switch s.name {
+		case SettingOpenGL:
+			openGL = s
+		case SettingArch:
+			arch = s
+		case SettingDocker:
+			docker = s
+		}
+	}
+
+	// hover.yaml takes precedence over the user config, including for false
+	// booleans
+	require.Equal(t, resolve(openGL, hoverConfig, userConfig).Value, "none")
+	require.Equal(t, resolve(openGL, hoverConfig, userConfig).Source, SourceHoverYAML)
+	require.Equal(t, resolve(docker, hoverConfig, userConfig).Bool(), false)
+	require.Equal(t, resolve(arch, hoverConfig, userConfig).Source, SourceUserConfig)
+	require.Equal(t, resolve(arch, hoverConfig, Config{}).Value, "amd64")
+	require.Equal(t, resolve(arch, hoverConfig, Config{}).Source, SourceDefault)
+
+	os.Setenv("HOVER_DOCKER", "maybe")
+	_, err = docker.resolve(nil, hoverConfig, userConfig)
+	require.NotEqual(t, err, nil, "an invalid boolean must be rejected")
+	os.Unsetenv("HOVER_DOCKER")
+
+	os.Setenv("HOVER_OPENGL", "3.2")
+	defer os.Unsetenv("HOVER_OPENGL")
+	require.Equal(t, resolve(openGL, hoverConfig, userConfig).Value, "3.2")
+	require.Equal(t, resolve(openGL, hoverConfig, userConfig).Source, SourceEnv)
+
+	// a flag set to its default value still takes precedence
+	value, err := openGL.resolve(map[string]string{SettingOpenGL: BuildOpenGlVersionDefault}, hoverConfig, userConfig)
+	require.Equal(t, err, nil, "failed to resolve %s: %v", SettingOpenGL, err)
+	require.Equal(t, value.Value, BuildOpenGlVersionDefault)
+	require.Equal(t, value.Source, SourceFlag)
+	value, err = openGL.resolve(map[string]string{SettingArch: "arm64"}, hoverConfig, userConfig)
+	require.Equal(t, err, nil, "failed to resolve %s: %v", SettingOpenGL, err)
+	require.Equal(t, value.Source, SourceEnv)
+}
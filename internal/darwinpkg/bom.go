@@ -0,0 +1,326 @@
+package darwinpkg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Types of the paths of a bill of materials.
+const (
+	bomTypeFile = 1
+	bomTypeDir  = 2
+	bomTypeLink = 3
+)
+
+const (
+	// bomHeaderSize is the space reserved for the header at the start of
+	// the file.
+	bomHeaderSize = 512
+	// pathsBlockSize is the size of the nodes of the Paths tree.
+	pathsBlockSize = 4096
+	// pathsPerLeaf is the number of paths of the leaves of the Paths tree.
+	pathsPerLeaf = 256
+)
+
+// bomStore is a BOMStore file: numbered blocks of data and named variables
+// pointing to blocks.
+type bomStore struct {
+	// blocks are the blocks by index, the first block is always null.
+	blocks [][]byte
+	vars   []bomVar
+}
+
+type bomVar struct {
+	name  string
+	block uint32
+}
+
+func (s *bomStore) addBlock(data []byte) uint32 {
+	s.blocks = append(s.blocks, data)
+	return uint32(len(s.blocks) - 1)
+}
+
+func (s *bomStore) addVar(name string, data []byte) {
+	s.vars = append(s.vars, bomVar{name: name, block: s.addBlock(data)})
+}
+
+// bomPath is a path listed in a bill of materials.
+type bomPath struct {
+	id         uint32
+	parentID   uint32
+	name       string
+	info       os.FileInfo
+	link       string
+	checksum   uint32
+	executable bool
+}
+
+// WriteBom writes the bill of materials of the files of root to w, as done
+// by `mkbom -u 0 -g 80`. The modes of the files are the ones of the
+// Payload written by WritePayload with the same executables.
+func WriteBom(root string, w io.Writer, executables []string) error {
+	paths, err := bomPaths(root, executableSet(executables))
+	if err != nil {
+		return errors.Wrap(err, "failed to list the files of the bill of materials")
+	}
+	store := &bomStore{blocks: [][]byte{nil}}
+
+	var info bytes.Buffer
+	binary.Write(&info, binary.BigEndian, []uint32{1, uint32(len(paths)), 0})
+	store.addVar("BomInfo", info.Bytes())
+	pathsRoot, err := pathsTree(store, paths)
+	if err != nil {
+		return err
+	}
+	store.addVar("Paths", bomTree(pathsRoot, pathsBlockSize, uint32(len(paths))))
+	store.addVar("HLIndex", bomTree(emptyPathsNode(store, pathsBlockSize), pathsBlockSize, 0))
+	var vIndex bytes.Buffer
+	binary.Write(&vIndex, binary.BigEndian, []uint32{1, store.addBlock(bomTree(emptyPathsNode(store, 128), 128, 0)), 0})
+	vIndex.WriteByte(0)
+	store.addVar("VIndex", vIndex.Bytes())
+	store.addVar("Size64", bomTree(emptyPathsNode(store, 128), 128, 0))
+
+	_, err = w.Write(store.bytes())
+	return err
+}
+
+// bomPaths returns the paths of the files of root, numbered in breadth-first
+// order. Sorted by parent and name, the order of the keys of the Paths tree,
+// the paths keep that order.
+func bomPaths(root string, isExecutable map[string]bool) ([]*bomPath, error) {
+	children := make(map[string][]*bomPath)
+	var rootPath *bomPath
+	err := walk(root, func(name, filePath string, info os.FileInfo) error {
+		p := &bomPath{name: path.Base(name), info: info, executable: isExecutable[name]}
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(filePath)
+			if err != nil {
+				return err
+			}
+			p.link = link
+			p.checksum = cksum([]byte(link))
+		case info.Mode().IsRegular():
+			data, err := ioutil.ReadFile(filePath)
+			if err != nil {
+				return err
+			}
+			p.checksum = cksum(data)
+		}
+		if name == "." {
+			rootPath = p
+		} else {
+			children[path.Dir(name)] = append(children[path.Dir(name)], p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rootPath.id = 1
+	paths := []*bomPath{rootPath}
+	names := map[*bomPath]string{rootPath: "."}
+	for i := 0; i < len(paths); i++ {
+		parent := paths[i]
+		list := children[names[parent]]
+		sort.Slice(list, func(i, j int) bool { return list[i].name < list[j].name })
+		for _, child := range list {
+			child.id = uint32(len(paths) + 1)
+			child.parentID = parent.id
+			names[child] = path.Join(names[parent], child.name)
+			paths = append(paths, child)
+		}
+	}
+	return paths, nil
+}
+
+// pathsTree adds the nodes of the Paths tree to the store and returns the
+// block of its root.
+func pathsTree(store *bomStore, paths []*bomPath) (uint32, error) {
+	type leaf struct {
+		block   uint32
+		lastKey uint32
+	}
+	var leaves []leaf
+	for start := 0; start < len(paths); start += pathsPerLeaf {
+		end := start + pathsPerLeaf
+		if end > len(paths) {
+			end = len(paths)
+		}
+		var indices []uint32
+		for _, p := range paths[start:end] {
+			pathInfo := store.addBlock(p.pathInfo())
+			var pathID bytes.Buffer
+			binary.Write(&pathID, binary.BigEndian, []uint32{p.id, pathInfo})
+			var key bytes.Buffer
+			binary.Write(&key, binary.BigEndian, p.parentID)
+			key.WriteString(p.name)
+			key.WriteByte(0)
+			indices = append(indices, store.addBlock(pathID.Bytes()), store.addBlock(key.Bytes()))
+		}
+		leaves = append(leaves, leaf{block: store.addBlock(pathsNode(true, indices)), lastKey: indices[len(indices)-1]})
+	}
+	// the leaves are linked to their siblings once all of them exist
+	for i, l := range leaves {
+		var forward, backward uint32
+		if i+1 < len(leaves) {
+			forward = leaves[i+1].block
+		}
+		if i > 0 {
+			backward = leaves[i-1].block
+		}
+		node := store.blocks[l.block]
+		binary.BigEndian.PutUint32(node[4:], forward)
+		binary.BigEndian.PutUint32(node[8:], backward)
+	}
+	if len(leaves) == 1 {
+		return leaves[0].block, nil
+	}
+	if 12+8*len(leaves) > pathsBlockSize {
+		return 0, errors.Errorf("too many files for the bill of materials: %d", len(paths))
+	}
+	// the branch points to the leaves and to their last key
+	var indices []uint32
+	for _, l := range leaves {
+		indices = append(indices, l.block, l.lastKey)
+	}
+	return store.addBlock(pathsNode(false, indices)), nil
+}
+
+// pathsNode returns a node of a tree holding pairs of block indices.
+func pathsNode(isLeaf bool, indices []uint32) []byte {
+	data := make([]byte, pathsBlockSize)
+	if isLeaf {
+		binary.BigEndian.PutUint16(data, 1)
+	}
+	binary.BigEndian.PutUint16(data[2:], uint16(len(indices)/2))
+	for i, index := range indices {
+		binary.BigEndian.PutUint32(data[12+4*i:], index)
+	}
+	return data
+}
+
+// emptyPathsNode adds an empty leaf to the store.
+func emptyPathsNode(store *bomStore, blockSize int) uint32 {
+	data := make([]byte, blockSize)
+	binary.BigEndian.PutUint16(data, 1)
+	return store.addBlock(data)
+}
+
+// bomTree returns the header of a tree.
+func bomTree(child uint32, blockSize int, pathCount uint32) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("tree")
+	binary.Write(&buf, binary.BigEndian, []uint32{1, child, uint32(blockSize), pathCount})
+	buf.WriteByte(0)
+	return buf.Bytes()
+}
+
+// pathInfo returns the attributes of a path.
+func (p *bomPath) pathInfo() []byte {
+	var buf bytes.Buffer
+	var typ uint8 = bomTypeFile
+	var size uint32
+	switch {
+	case p.info.IsDir():
+		typ = bomTypeDir
+	case p.link != "":
+		typ = bomTypeLink
+		size = uint32(len(p.link))
+	default:
+		size = uint32(p.info.Size())
+	}
+	buf.Write([]byte{typ, 1})
+	// architecture, unused for the installed files
+	binary.Write(&buf, binary.BigEndian, uint16(3))
+	binary.Write(&buf, binary.BigEndian, uint16(fileMode(p.info, p.executable)))
+	binary.Write(&buf, binary.BigEndian, []uint32{ownerUID, ownerGID, uint32(p.info.ModTime().Unix()), size})
+	buf.WriteByte(1)
+	binary.Write(&buf, binary.BigEndian, p.checksum)
+	if p.link == "" {
+		binary.Write(&buf, binary.BigEndian, uint32(0))
+	} else {
+		binary.Write(&buf, binary.BigEndian, uint32(len(p.link)+1))
+		buf.WriteString(p.link)
+		buf.WriteByte(0)
+	}
+	return buf.Bytes()
+}
+
+// bytes encodes the store: the header, the blocks, the variables and the
+// table of the blocks.
+func (s *bomStore) bytes() []byte {
+	var blocks bytes.Buffer
+	addresses := make([]uint32, len(s.blocks))
+	for i, block := range s.blocks {
+		if i == 0 {
+			continue
+		}
+		addresses[i] = uint32(bomHeaderSize + blocks.Len())
+		blocks.Write(block)
+	}
+
+	var vars bytes.Buffer
+	binary.Write(&vars, binary.BigEndian, uint32(len(s.vars)))
+	for _, v := range s.vars {
+		binary.Write(&vars, binary.BigEndian, v.block)
+		vars.WriteByte(uint8(len(v.name)))
+		vars.WriteString(v.name)
+	}
+
+	var index bytes.Buffer
+	binary.Write(&index, binary.BigEndian, uint32(len(s.blocks)))
+	for i, block := range s.blocks {
+		binary.Write(&index, binary.BigEndian, []uint32{addresses[i], uint32(len(block))})
+	}
+	// empty free list
+	binary.Write(&index, binary.BigEndian, uint32(0))
+
+	varsOffset := uint32(bomHeaderSize + blocks.Len())
+	indexOffset := varsOffset + uint32(vars.Len())
+	header := make([]byte, bomHeaderSize)
+	copy(header, "BOMStore")
+	binary.BigEndian.PutUint32(header[8:], 1)
+	binary.BigEndian.PutUint32(header[12:], uint32(len(s.blocks)-1))
+	binary.BigEndian.PutUint32(header[16:], indexOffset)
+	binary.BigEndian.PutUint32(header[20:], uint32(index.Len()))
+	binary.BigEndian.PutUint32(header[24:], varsOffset)
+	binary.BigEndian.PutUint32(header[28:], uint32(vars.Len()))
+
+	var buf bytes.Buffer
+	buf.Write(header)
+	buf.Write(blocks.Bytes())
+	buf.Write(vars.Bytes())
+	buf.Write(index.Bytes())
+	return buf.Bytes()
+}
+
+// cksum returns the POSIX checksum of data, as printed by cksum.
+func cksum(data []byte) uint32 {
+	var crc uint32
+	update := func(b byte) {
+		crc ^= uint32(b) << 24
+		for i := 0; i < 8; i++ {
+			if crc&0x80000000 != 0 {
+				crc = crc<<1 ^ 0x04c11db7
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	for _, b := range data {
+		update(b)
+	}
+	for length := len(data); length > 0; length >>= 8 {
+		update(byte(length))
+	}
+	return ^crc
+}
@@ -0,0 +1,160 @@
+package darwinpkg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-flutter-desktop/hover/internal/testutil"
+)
+
+// testExecutable is the app binary of testTree, staged without exec bits.
+const testExecutable = "Applications/App.app/Contents/MacOS/App"
+
+// testTree creates a tree with enough files to split the Paths tree of its
+// bill of materials, and returns its directory and the names of its files.
+func testTree(t *testing.T) (string, []string) {
+	files := map[string]testutil.File{testExecutable: {Content: "binary"}}
+	names := []string{".", "./" + testExecutable}
+	for _, dir := range []string{"Applications", "Applications/App.app", "Applications/App.app/Contents", "Applications/App.app/Contents/MacOS"} {
+		names = append(names, "./"+dir)
+	}
+	for i := 0; i < 300; i++ {
+		name := fmt.Sprintf("Applications/App.app/Contents/MacOS/asset%03d", i)
+		files[name] = testutil.File{Content: name}
+		names = append(names, "./"+name)
+	}
+	sort.Strings(names)
+	return testutil.Tree(t, files), names
+}
+
+func TestWritePayload(t *testing.T) {
+	dir, names := testTree(t)
+
+	var buf bytes.Buffer
+	err := WritePayload(dir, &buf, []string{testExecutable})
+	require.Equal(t, err, nil, "failed to write the payload: %v", err)
+	gzipReader, err := gzip.NewReader(&buf)
+	require.Equal(t, err, nil, "failed to read the payload: %v", err)
+	payload, err := ioutil.ReadAll(gzipReader)
+	require.Equal(t, err, nil, "failed to read the payload: %v", err)
+
+	var payloadNames []string
+	modes := make(map[string]int)
+	for len(payload) > 0 {
+		require.Equal(t, "070707", string(payload[:6]))
+		field := func(offset, length int) int {
+			value, err := strconv.ParseUint(string(payload[offset:offset+length]), 8, 64)
+			require.Equal(t, err, nil, "invalid cpio header: %v", err)
+			return int(value)
+		}
+		require.Equal(t, 80, field(30, 6), "the files must belong to the admin group")
+		nameSize, fileSize := field(59, 6), field(65, 11)
+		payloadNames = append(payloadNames, string(payload[76:76+nameSize-1]))
+		modes[payloadNames[len(payloadNames)-1]] = field(18, 6)
+		payload = payload[76+nameSize+fileSize:]
+	}
+	require.Equal(t, append(names, "TRAILER!!!"), payloadNames)
+	require.Equal(t, 0100755, modes["./"+testExecutable])
+	require.Equal(t, 0100644, modes["./Applications/App.app/Contents/MacOS/asset000"])
+	require.Equal(t, 040755, modes["./Applications"])
+}
+
+func TestWriteBom(t *testing.T) {
+	dir, names := testTree(t)
+
+	var buf bytes.Buffer
+	err := WriteBom(dir, &buf, []string{testExecutable})
+	require.Equal(t, err, nil, "failed to write the bill of materials: %v", err)
+	bom := buf.Bytes()
+	require.Equal(t, "BOMStore", string(bom[:8]))
+
+	u32 := func(data []byte) uint32 { return binary.BigEndian.Uint32(data) }
+	indexOffset := u32(bom[16:])
+	block := func(index uint32) []byte {
+		pointer := bom[indexOffset+4+8*index:]
+		return bom[u32(pointer) : u32(pointer)+u32(pointer[4:])]
+	}
+	vars := bom[u32(bom[24:]):]
+	var pathsTree []byte
+	for i, offset := uint32(0), uint32(4); i < u32(vars); i++ {
+		name := string(vars[offset+5 : offset+5+uint32(vars[offset+4])])
+		if name == "Paths" {
+			pathsTree = block(u32(vars[offset:]))
+		}
+		offset += 5 + uint32(vars[offset+4])
+	}
+	require.Equal(t, "tree", string(pathsTree[:4]))
+	require.Equal(t, uint32(len(names)), u32(pathsTree[16:]))
+
+	// walk the leaves like lsbom, from the first one
+	node := block(u32(pathsTree[8:]))
+	require.Equal(t, uint16(0), binary.BigEndian.Uint16(node), "the paths must be split in several leaves")
+	for binary.BigEndian.Uint16(node) == 0 {
+		node = block(u32(node[12:]))
+	}
+	paths := map[uint32]string{0: ""}
+	var bomNames []string
+	modes := make(map[string]uint16)
+	for {
+		count := int(binary.BigEndian.Uint16(node[2:]))
+		for i := 0; i < count; i++ {
+			pathID := block(u32(node[12+8*i:]))
+			id := u32(pathID)
+			key := block(u32(node[16+8*i:]))
+			name := string(bytes.TrimRight(key[4:], "\x00"))
+			if parent := paths[u32(key)]; parent != "" {
+				name = parent + "/" + name
+			}
+			paths[id] = name
+			bomNames = append(bomNames, name)
+			modes[name] = binary.BigEndian.Uint16(block(u32(pathID[4:]))[4:])
+		}
+		forward := u32(node[4:])
+		if forward == 0 {
+			break
+		}
+		node = block(forward)
+	}
+	sort.Strings(bomNames)
+	require.Equal(t, names, bomNames)
+	require.Equal(t, uint16(0100755), modes["./"+testExecutable])
+	require.Equal(t, uint16(0100644), modes["./Applications/App.app/Contents/MacOS/asset000"])
+}
+
+func TestWriteXar(t *testing.T) {
+	dir := testutil.Tree(t, map[string]testutil.File{
+		"Distribution":         {Content: "<installer-gui-script/>"},
+		"base.pkg/PackageInfo": {Content: "<pkg-info/>"},
+	})
+
+	var buf bytes.Buffer
+	err := WriteXar(dir, &buf, testutil.Epoch)
+	require.Equal(t, err, nil, "failed to write the archive: %v", err)
+	archive := buf.Bytes()
+	require.Equal(t, "xar!", string(archive[:4]))
+	tocLength := binary.BigEndian.Uint64(archive[8:])
+	zlibReader, err := zlib.NewReader(bytes.NewReader(archive[xarHeaderSize : xarHeaderSize+tocLength]))
+	require.Equal(t, err, nil, "failed to read the table of contents: %v", err)
+	var toc xarTOC
+	err = xml.NewDecoder(zlibReader).Decode(&toc)
+	require.Equal(t, err, nil, "failed to decode the table of contents: %v", err)
+	require.Equal(t, testutil.Epoch.UTC().Format("2006-01-02T15:04:05Z"), toc.TOC.CreationTime)
+
+	require.Equal(t, 2, len(toc.TOC.Files))
+	require.Equal(t, "Distribution", toc.TOC.Files[0].Name)
+	require.Equal(t, "base.pkg", toc.TOC.Files[1].Name)
+	packageInfo := toc.TOC.Files[1].Files[0]
+	require.Equal(t, "PackageInfo", packageInfo.Name)
+	heap := archive[xarHeaderSize+tocLength:]
+	require.Equal(t, "<pkg-info/>", string(heap[packageInfo.Data.Offset:packageInfo.Data.Offset+packageInfo.Data.Length]))
+}
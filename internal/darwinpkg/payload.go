@@ -0,0 +1,120 @@
+// Package darwinpkg writes the parts of macOS flat installer packages: the
+// cpio payload, the bill of materials and the xar archive, without the cpio,
+// mkbom and xar tools.
+package darwinpkg
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// The files of the packages are installed owned by root and the admin group.
+const (
+	ownerUID = 0
+	ownerGID = 80
+)
+
+// WritePayload writes the Payload of a component package to w: the gzip
+// compressed cpio archive, in the odc format, of the files of root.
+// executables are the slash separated paths, relative to root, of the files
+// installed with mode 0755 whatever their mode on the host.
+func WritePayload(root string, w io.Writer, executables []string) error {
+	isExecutable := executableSet(executables)
+	gzipWriter := gzip.NewWriter(w)
+	inode := 0
+	err := walk(root, func(name string, path string, info os.FileInfo) error {
+		var data []byte
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			data = []byte(link)
+		case info.Mode().IsRegular():
+			var err error
+			data, err = ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+		}
+		inode++
+		return writeOdcEntry(gzipWriter, name, inode, fileMode(info, isExecutable[name]), info.ModTime().Unix(), data)
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to archive the payload")
+	}
+	err = writeOdcEntry(gzipWriter, "TRAILER!!!", 0, 0, 0, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to archive the payload")
+	}
+	return gzipWriter.Close()
+}
+
+// writeOdcEntry writes a file of a cpio archive in the odc format.
+func writeOdcEntry(w io.Writer, name string, inode int, mode uint32, modTime int64, data []byte) error {
+	nlink := 1
+	if mode&0170000 == 040000 {
+		nlink = 2
+	}
+	header := fmt.Sprintf("070707%06o%06o%06o%06o%06o%06o%06o%011o%06o%011o%s\x00",
+		0, inode&0777777, mode, ownerUID, ownerGID, nlink, 0, modTime, len(name)+1, len(data), name)
+	_, err := io.WriteString(w, header)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// walk calls fn for root and every file below it, in lexical order. The names
+// of the files are relative to root, prefixed with ./ like the output of
+// `find .`.
+func walk(root string, fn func(name, path string, info os.FileInfo) error) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		name := "."
+		if relativePath != "." {
+			name = "./" + filepath.ToSlash(relativePath)
+		}
+		return fn(name, path, info)
+	})
+}
+
+// executableSet returns the names, as passed to the functions of walk, of
+// the executables.
+func executableSet(executables []string) map[string]bool {
+	isExecutable := make(map[string]bool, len(executables))
+	for _, executable := range executables {
+		isExecutable["./"+executable] = true
+	}
+	return isExecutable
+}
+
+// fileMode returns the mode of a file with the file type bits of stat: 0755
+// for directories and executables, 0644 for other files. The packages don't
+// depend on the umask of the machine they are written on.
+func fileMode(info os.FileInfo, executable bool) uint32 {
+	switch {
+	case info.IsDir():
+		return 040755
+	case info.Mode()&os.ModeSymlink != 0:
+		return 0120777
+	case executable || info.Mode()&0111 != 0:
+		return 0100755
+	default:
+		return 0100644
+	}
+}
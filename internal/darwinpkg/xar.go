@@ -0,0 +1,180 @@
+package darwinpkg
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// xarHeaderSize is the size of the header of xar archives.
+const xarHeaderSize = 28
+
+// xarChecksumSHA1 is the checksum algorithm of the table of contents.
+const xarChecksumSHA1 = 1
+
+// xarTimeFormat is the format of the dates of the table of contents.
+const xarTimeFormat = "2006-01-02T15:04:05Z"
+
+type xarTOC struct {
+	XMLName xml.Name `xml:"xar"`
+	TOC     struct {
+		Checksum struct {
+			Style  string `xml:"style,attr"`
+			Offset int64  `xml:"offset"`
+			Size   int64  `xml:"size"`
+		} `xml:"checksum"`
+		CreationTime string     `xml:"creation-time"`
+		Files        []*xarFile `xml:"file"`
+	} `xml:"toc"`
+}
+
+type xarFile struct {
+	ID    int        `xml:"id,attr"`
+	Data  *xarData   `xml:"data,omitempty"`
+	CTime string     `xml:"ctime"`
+	MTime string     `xml:"mtime"`
+	ATime string     `xml:"atime"`
+	Group string     `xml:"group"`
+	GID   int        `xml:"gid"`
+	User  string     `xml:"user"`
+	UID   int        `xml:"uid"`
+	Mode  string     `xml:"mode"`
+	Link  *xarLink   `xml:"link,omitempty"`
+	Type  string     `xml:"type"`
+	Name  string     `xml:"name"`
+	Files []*xarFile `xml:"file"`
+}
+
+type xarData struct {
+	Length   int64 `xml:"length"`
+	Offset   int64 `xml:"offset"`
+	Size     int64 `xml:"size"`
+	Encoding struct {
+		Style string `xml:"style,attr"`
+	} `xml:"encoding"`
+	ExtractedChecksum xarChecksum `xml:"extracted-checksum"`
+	ArchivedChecksum  xarChecksum `xml:"archived-checksum"`
+}
+
+type xarLink struct {
+	Type   string `xml:"type,attr"`
+	Target string `xml:",chardata"`
+}
+
+type xarChecksum struct {
+	Style string `xml:"style,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteXar writes the xar archive of the files of root to w, as done by
+// `xar --compression none -cf`. The files are stored uncompressed and owned
+// by root. creationTime dates the archive.
+func WriteXar(root string, w io.Writer, creationTime time.Time) error {
+	toc := xarTOC{}
+	toc.TOC.Checksum.Style = "sha1"
+	toc.TOC.Checksum.Size = sha1.Size
+	toc.TOC.CreationTime = creationTime.UTC().Format(xarTimeFormat)
+
+	// the heap starts with the checksum of the table of contents
+	var heap bytes.Buffer
+	heap.Write(make([]byte, sha1.Size))
+	directories := map[string]*xarFile{}
+	id := 0
+	err := walk(root, func(name, filePath string, info os.FileInfo) error {
+		if name == "." {
+			return nil
+		}
+		id++
+		modTime := info.ModTime().UTC().Format(xarTimeFormat)
+		file := &xarFile{
+			ID:    id,
+			CTime: modTime,
+			MTime: modTime,
+			ATime: modTime,
+			Group: "wheel",
+			User:  "root",
+			Mode:  fmt.Sprintf("%04o", info.Mode().Perm()),
+			Name:  path.Base(name),
+		}
+		switch {
+		case info.IsDir():
+			file.Type = "directory"
+			directories[path.Clean(name)] = file
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(filePath)
+			if err != nil {
+				return err
+			}
+			file.Type = "symlink"
+			file.Link = &xarLink{Type: "file", Target: link}
+		default:
+			data, err := ioutil.ReadFile(filePath)
+			if err != nil {
+				return err
+			}
+			checksum := sha1.Sum(data)
+			file.Type = "file"
+			file.Data = &xarData{
+				Length:            int64(len(data)),
+				Offset:            int64(heap.Len()),
+				Size:              int64(len(data)),
+				ExtractedChecksum: xarChecksum{Style: "sha1", Value: hex.EncodeToString(checksum[:])},
+				ArchivedChecksum:  xarChecksum{Style: "sha1", Value: hex.EncodeToString(checksum[:])},
+			}
+			file.Data.Encoding.Style = "application/octet-stream"
+			heap.Write(data)
+		}
+		if parent, ok := directories[path.Dir(name)]; ok {
+			parent.Files = append(parent.Files, file)
+		} else {
+			toc.TOC.Files = append(toc.TOC.Files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to archive the package")
+	}
+
+	tocXML, err := xml.MarshalIndent(toc, "", " ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode the table of contents")
+	}
+	tocXML = append([]byte(xml.Header), tocXML...)
+	var compressedTOC bytes.Buffer
+	zlibWriter := zlib.NewWriter(&compressedTOC)
+	_, err = zlibWriter.Write(tocXML)
+	if err != nil {
+		return err
+	}
+	err = zlibWriter.Close()
+	if err != nil {
+		return err
+	}
+	tocChecksum := sha1.Sum(compressedTOC.Bytes())
+	heapBytes := heap.Bytes()
+	copy(heapBytes, tocChecksum[:])
+
+	var header bytes.Buffer
+	header.WriteString("xar!")
+	binary.Write(&header, binary.BigEndian, []uint16{xarHeaderSize, 1})
+	binary.Write(&header, binary.BigEndian, []uint64{uint64(compressedTOC.Len()), uint64(len(tocXML))})
+	binary.Write(&header, binary.BigEndian, uint32(xarChecksumSHA1))
+	for _, data := range [][]byte{header.Bytes(), compressedTOC.Bytes(), heapBytes} {
+		_, err = w.Write(data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+// Package deb writes Debian binary packages (.deb) without dpkg-deb.
+package deb
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ulikunitz/xz"
+)
+
+// ControlDirectory is the directory of the staged tree holding the control
+// file and the maintainer scripts.
+const ControlDirectory = "DEBIAN"
+
+// maintainerScripts are the control files that are executed by dpkg.
+var maintainerScripts = map[string]bool{
+	"preinst":  true,
+	"postinst": true,
+	"prerm":    true,
+	"postrm":   true,
+	"config":   true,
+}
+
+// Build writes the package of the staged tree root to w. The DEBIAN directory
+// of the tree holds the control file and the maintainer scripts, the other
+// files are installed by the package, owned by root. The Installed-Size field
+// and the md5sums control file are computed from the staged files. modTime
+// dates the archive members, the staged files keep their modification time.
+// executables are the slash separated paths, relative to root, of the files
+// installed with mode 0755 whatever their mode on the host.
+func Build(root string, w io.Writer, modTime time.Time, executables []string) error {
+	modTime = modTime.Truncate(time.Second)
+	data, installedSize, md5sums, err := dataArchive(root, executables)
+	if err != nil {
+		return err
+	}
+	control, err := controlArchive(root, installedSize, md5sums, modTime)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "!<arch>\n")
+	if err != nil {
+		return err
+	}
+	members := []struct {
+		name string
+		data []byte
+	}{
+		{"debian-binary", []byte("2.0\n")},
+		{"control.tar.gz", control},
+		{"data.tar.xz", data},
+	}
+	for _, member := range members {
+		err = writeArMember(w, member.name, member.data, modTime)
+		if err != nil {
+			return errors.Wrapf(err, "failed to write %s", member.name)
+		}
+	}
+	return nil
+}
+
+// writeArMember writes a file of an ar archive, the data of members are
+// aligned to two bytes.
+func writeArMember(w io.Writer, name string, data []byte, modTime time.Time) error {
+	header := fmt.Sprintf("%-16s%-12d%-6d%-6d%-8o%-10d`\n", name, modTime.Unix(), 0, 0, 0100644, len(data))
+	_, err := io.WriteString(w, header)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	if err != nil {
+		return err
+	}
+	if len(data)%2 != 0 {
+		_, err = w.Write([]byte{'\n'})
+	}
+	return err
+}
+
+// dataArchive returns the xz compressed tar archive of the installed files,
+// their size in KiB as computed by dpkg, and the content of the md5sums
+// control file.
+func dataArchive(root string, executables []string) ([]byte, int64, []byte, error) {
+	isExecutable := make(map[string]bool, len(executables))
+	for _, executable := range executables {
+		isExecutable[executable] = true
+	}
+	var buf bytes.Buffer
+	xzWriter, err := xz.NewWriter(&buf)
+	if err != nil {
+		return nil, 0, nil, errors.Wrap(err, "failed to create the xz writer")
+	}
+	tarWriter := tar.NewWriter(xzWriter)
+	var installedSize int64
+	var md5sums bytes.Buffer
+
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		relativePath = filepath.ToSlash(relativePath)
+		if relativePath == ControlDirectory {
+			return filepath.SkipDir
+		}
+
+		var link string
+		if info.Mode()&os.ModeSymlink != 0 {
+			link, err = os.Readlink(path)
+			if err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = "./" + relativePath
+		if relativePath == "." {
+			// the root of the tree is / on the installed system
+			header.Name = "./"
+			header.Mode = 0755
+		} else if info.IsDir() {
+			header.Name += "/"
+			header.Mode = 0755
+		} else if info.Mode().IsRegular() {
+			header.Mode = 0644
+			if isExecutable[relativePath] || info.Mode()&0111 != 0 {
+				header.Mode = 0755
+			}
+		}
+		header.Uid, header.Gid = 0, 0
+		header.Uname, header.Gname = "root", "root"
+		header.ModTime = header.ModTime.Truncate(time.Second)
+		header.AccessTime, header.ChangeTime = time.Time{}, time.Time{}
+		header.Format = tar.FormatGNU
+		err = tarWriter.WriteHeader(header)
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			installedSize++
+			return nil
+		}
+		installedSize += (info.Size() + 1023) / 1024
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		h := md5.New()
+		_, err = io.Copy(tarWriter, io.TeeReader(file, h))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&md5sums, "%s  %s\n", hex.EncodeToString(h.Sum(nil)), relativePath)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, nil, errors.Wrap(err, "failed to archive the package files")
+	}
+	err = tarWriter.Close()
+	if err != nil {
+		return nil, 0, nil, errors.Wrap(err, "failed to archive the package files")
+	}
+	err = xzWriter.Close()
+	if err != nil {
+		return nil, 0, nil, errors.Wrap(err, "failed to compress the package files")
+	}
+	return buf.Bytes(), installedSize, md5sums.Bytes(), nil
+}
+
+// controlArchive returns the gzip compressed tar archive of the control files.
+func controlArchive(root string, installedSize int64, md5sums []byte, modTime time.Time) ([]byte, error) {
+	controlDirectoryPath := filepath.Join(root, ControlDirectory)
+	control, err := ioutil.ReadFile(filepath.Join(controlDirectoryPath, "control"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the control file")
+	}
+	files := map[string][]byte{
+		"control": withInstalledSize(control, installedSize),
+		"md5sums": md5sums,
+	}
+	entries, err := ioutil.ReadDir(controlDirectoryPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the control files")
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == "control" || entry.Name() == "md5sums" || strings.HasSuffix(entry.Name(), ".tmpl") {
+			continue
+		}
+		files[entry.Name()], err = ioutil.ReadFile(filepath.Join(controlDirectoryPath, entry.Name()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read the control file %s", entry.Name())
+		}
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	err = tarWriter.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     "./",
+		Mode:     0755,
+		ModTime:  modTime,
+		Uname:    "root",
+		Gname:    "root",
+		Format:   tar.FormatGNU,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		mode := int64(0644)
+		if maintainerScripts[name] {
+			mode = 0755
+		}
+		err = tarWriter.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     "./" + name,
+			Mode:     mode,
+			Size:     int64(len(files[name])),
+			ModTime:  modTime,
+			Uname:    "root",
+			Gname:    "root",
+			Format:   tar.FormatGNU,
+		})
+		if err != nil {
+			return nil, err
+		}
+		_, err = tarWriter.Write(files[name])
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = tarWriter.Close()
+	if err != nil {
+		return nil, err
+	}
+	err = gzipWriter.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// withInstalledSize adds the Installed-Size field to a control file, unless
+// it is already set.
+func withInstalledSize(control []byte, installedSize int64) []byte {
+	lines := strings.Split(strings.TrimRight(string(control), "\n"), "\n")
+	for _, line := range lines {
+		if strings.HasPrefix(strings.ToLower(line), "installed-size:") {
+			return []byte(strings.Join(lines, "\n") + "\n")
+		}
+	}
+	lines = append(lines, fmt.Sprintf("Installed-Size: %d", installedSize))
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
@@ -0,0 +1,97 @@
+package deb
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/ulikunitz/xz"
+
+	"github.com/go-flutter-desktop/hover/internal/testutil"
+)
+
+func TestBuild(t *testing.T) {
+	files := map[string]testutil.File{
+		"DEBIAN/control":  {Content: "Package: app\nVersion: 1.0.0\n"},
+		"DEBIAN/postinst": {Content: "#!/bin/sh\n"},
+		"usr/bin/app":     {Content: "#!/bin/sh\nexec /usr/lib/app/app\n"},
+		"usr/lib/app/app": {Content: strings.Repeat("x", 2000)},
+	}
+	dir := testutil.Tree(t, files)
+
+	var buf bytes.Buffer
+	err := Build(dir, &buf, testutil.Epoch, []string{"usr/bin/app", "usr/lib/app/app"})
+	require.Equal(t, err, nil, "failed to build the package: %v", err)
+
+	members := readAr(t, buf.Bytes())
+	require.Equal(t, []string{"debian-binary", "control.tar.gz", "data.tar.xz"}, []string{members[0].name, members[1].name, members[2].name})
+	require.Equal(t, "2.0\n", string(members[0].data))
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(members[1].data))
+	require.Equal(t, err, nil, "failed to read control.tar.gz: %v", err)
+	control := readTar(t, gzipReader)
+	// 5 directories, 2 KiB for usr/lib/app/app and 1 KiB for usr/bin/app
+	require.Equal(t, "Package: app\nVersion: 1.0.0\nInstalled-Size: 8\n", control["./control"].content)
+	require.Equal(t, int64(0755), control["./postinst"].header.Mode)
+	require.Contains(t, control["./md5sums"].content, "  usr/lib/app/app\n")
+	require.NotContains(t, control["./md5sums"].content, "DEBIAN")
+
+	xzReader, err := xz.NewReader(bytes.NewReader(members[2].data))
+	require.Equal(t, err, nil, "failed to read data.tar.xz: %v", err)
+	data := readTar(t, xzReader)
+	_, ok := data["./DEBIAN/"]
+	require.False(t, ok, "the control directory is part of the data")
+	require.Equal(t, files["usr/lib/app/app"].Content, data["./usr/lib/app/app"].content)
+	require.Equal(t, "root", data["./usr/bin/app"].header.Uname)
+	require.Equal(t, 0, data["./usr/bin/app"].header.Uid)
+	require.Equal(t, int64(0755), data["./usr/bin/app"].header.Mode)
+	require.Equal(t, int64(0755), data["./usr/lib/app/app"].header.Mode)
+}
+
+type arMember struct {
+	name string
+	data []byte
+}
+
+func readAr(t *testing.T, archive []byte) []arMember {
+	require.Equal(t, "!<arch>\n", string(archive[:8]))
+	archive = archive[8:]
+	var members []arMember
+	for len(archive) > 0 {
+		header := string(archive[:60])
+		size, err := strconv.Atoi(strings.TrimSpace(header[48:58]))
+		require.Equal(t, err, nil, "invalid member size: %v", err)
+		members = append(members, arMember{
+			name: strings.TrimSpace(header[:16]),
+			data: archive[60 : 60+size],
+		})
+		archive = archive[60+size+size%2:]
+	}
+	return members
+}
+
+type tarEntry struct {
+	header  *tar.Header
+	content string
+}
+
+func readTar(t *testing.T, r io.Reader) map[string]tarEntry {
+	entries := make(map[string]tarEntry)
+	tarReader := tar.NewReader(r)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return entries
+		}
+		require.Equal(t, err, nil, "failed to read the tar archive: %v", err)
+		content, err := ioutil.ReadAll(tarReader)
+		require.Equal(t, err, nil, "failed to read %s: %v", header.Name, err)
+		entries[header.Name] = tarEntry{header: header, content: string(content)}
+	}
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/go-flutter-desktop/hover/internal/build"
 	"github.com/go-flutter-desktop/hover/internal/flutterversion"
 	"github.com/go-flutter-desktop/hover/internal/log"
 )
@@ -106,7 +107,7 @@ func printDownloadPercent(done chan chan struct{}, path string, expectedSize int
 		var percent = float64(size) / float64(expectedSize) * 100
 
 		// We use '\033[2K\r' to avoid carriage return, it will print above previous.
-		fmt.Printf("\033[2K\r %.0f %% / 100 %%", percent)
+		fmt.Fprintf(log.CommandOutput(), "\033[2K\r %.0f %% / 100 %%", percent)
 
 		if completedCh != nil {
 			close(completedCh)
@@ -194,55 +195,106 @@ func downloadFile(filepath string, url string) error {
 	return nil
 }
 
+// Engine runtime modes. The debug engine runs the JIT kernel of the flutter
+// bundle, the profile and release engines run AOT snapshots compiled with
+// gen_snapshot.
+const (
+	ModeDebug   = "debug"
+	ModeProfile = "profile"
+	ModeRelease = "release"
+)
+
+// ValidateMode returns an error when mode isn't a known engine runtime mode.
+func ValidateMode(mode string) error {
+	switch mode {
+	case ModeDebug, ModeProfile, ModeRelease:
+		return nil
+	default:
+		return errors.Errorf("unknown engine mode '%s', valid modes are: debug, profile, release", mode)
+	}
+}
+
 //noinspection GoNameStartsWithPackageName
-func EngineCachePath(targetOS, cachePath string) string {
-	return filepath.Join(cachePath, "hover", "engine", targetOS)
+func EngineCachePath(targetOS, targetArch, mode, cachePath string) string {
+	name := build.TargetDirectoryName(targetOS, targetArch)
+	if mode != ModeDebug {
+		name += "-" + mode
+	}
+	return filepath.Join(cachePath, "hover", "engine", name)
+}
+
+// GenSnapshotPath returns the path of the gen_snapshot binary shipped with
+// the profile and release engines.
+func GenSnapshotPath(targetOS, targetArch, mode, cachePath string) string {
+	name := "gen_snapshot"
+	if targetOS == "windows" {
+		name += ".exe"
+	}
+	return filepath.Join(EngineCachePath(targetOS, targetArch, mode, cachePath), "artifacts", name)
+}
+
+// enginePlatform returns the name flutter uses for the engine artifacts of a
+// platform, e.g.: linux-x64 or linux-arm64.
+func enginePlatform(targetOS, targetArch string) (string, error) {
+	switch targetArch {
+	case "amd64":
+		return targetOS + "-x64", nil
+	case "arm64":
+		return targetOS + "-arm64", nil
+	default:
+		return "", errors.Errorf("cannot download engine for architecture %s", targetArch)
+	}
 }
 
 // ValidateOrUpdateEngine validates the engine we have cached matches the
 // flutter version, or otherwise downloads a new engine. The engine cache
-// location is set by the the user.
-func ValidateOrUpdateEngine(targetOS, cachePath, requiredEngineVersion string) {
-	engineCachePath := EngineCachePath(targetOS, cachePath)
+// location is set by the the user. Profile and release engines come with the
+// gen_snapshot binary used to compile AOT snapshots.
+func ValidateOrUpdateEngine(targetOS, targetArch, mode, cachePath, requiredEngineVersion string) error {
+	err := ValidateMode(mode)
+	if err != nil {
+		return err
+	}
+	engineCachePath := EngineCachePath(targetOS, targetArch, mode, cachePath)
 
 	if strings.Contains(engineCachePath, " ") {
 		log.Errorf("Cannot save the engine to '%s', engine cache is not compatible with path containing spaces.", cachePath)
 		log.Errorf("       Please run hover with a another engine cache path. Example:")
 		log.Errorf("              %s", log.Au().Magenta("hover run --cache-path \"C:\\cache\""))
 		log.Errorf("       The --cache-path flag will have to be provided to every build and run command.")
-		os.Exit(1)
+		return errors.Errorf("engine cache path '%s' contains spaces", engineCachePath)
 	}
 
 	cachedEngineVersionPath := filepath.Join(engineCachePath, "version")
 	cachedEngineVersionBytes, err := ioutil.ReadFile(cachedEngineVersionPath)
 	if err != nil && !os.IsNotExist(err) {
-		log.Errorf("Failed to read cached engine version: %v", err)
-		os.Exit(1)
+		return errors.Wrap(err, "failed to read cached engine version")
 	}
 	cachedEngineVersion := string(cachedEngineVersionBytes)
 	if len(requiredEngineVersion) == 0 {
-		requiredEngineVersion = flutterversion.FlutterRequiredEngineVersion()
+		requiredEngineVersion, err = flutterversion.FlutterRequiredEngineVersion()
+		if err != nil {
+			return err
+		}
 	}
 
 	if cachedEngineVersion != "" {
 		if cachedEngineVersion == requiredEngineVersion {
 			log.Printf("Using engine from cache")
-			return
+			return nil
 		}
 
 		// Engine is outdated, we remove the old engine and continue to download
 		// the new engine.
 		err = os.RemoveAll(engineCachePath)
 		if err != nil {
-			log.Errorf("Failed to remove outdated engine: %v", err)
-			os.Exit(1)
+			return errors.Wrap(err, "failed to remove outdated engine")
 		}
 	}
 
 	err = os.MkdirAll(engineCachePath, 0775)
 	if err != nil {
-		log.Errorf("Failed to create engine cache directory: %v", err)
-		os.Exit(1)
+		return errors.Wrap(err, "failed to create engine cache directory")
 	}
 
 	targetedDomain := "https://storage.googleapis.com"
@@ -251,11 +303,20 @@ func ValidateOrUpdateEngine(targetOS, cachePath, requiredEngineVersion string) {
 		targetedDomain = envURLFlutter
 	}
 
-	// TODO: support more arch's than x64?
-	var platform = targetOS + "-x64"
+	platform, err := enginePlatform(targetOS, targetArch)
+	if err != nil {
+		return err
+	}
+
+	// The profile and release artifacts are stored next to the debug ones,
+	// in a directory suffixed with the mode.
+	modePlatform := platform
+	if mode != ModeDebug {
+		modePlatform += "-" + mode
+	}
 
 	// Build the URL for downloading the correct engine
-	var engineDownloadURL = fmt.Sprintf(targetedDomain+"/flutter_infra/flutter/%s/%s/", requiredEngineVersion, platform)
+	var engineDownloadURL = fmt.Sprintf(targetedDomain+"/flutter_infra/flutter/%s/%s/", requiredEngineVersion, modePlatform)
 	switch targetOS {
 	case "darwin":
 		engineDownloadURL += "FlutterEmbedder.framework.zip"
@@ -264,16 +325,15 @@ func ValidateOrUpdateEngine(targetOS, cachePath, requiredEngineVersion string) {
 	case "windows":
 		engineDownloadURL += platform + "-embedder.zip"
 	default:
-		log.Errorf("Cannot run on %s, download engine not implemented.", targetOS)
-		os.Exit(1)
+		return errors.Errorf("cannot run on %s, download engine not implemented", targetOS)
 	}
 
 	icudtlDownloadURL := fmt.Sprintf(targetedDomain+"/flutter_infra/flutter/%s/%s/artifacts.zip", requiredEngineVersion, platform)
+	genSnapshotDownloadURL := fmt.Sprintf(targetedDomain+"/flutter_infra/flutter/%s/%s/artifacts.zip", requiredEngineVersion, modePlatform)
 
 	dir, err := ioutil.TempDir("", "hover-engine-download")
 	if err != nil {
-		log.Errorf("Failed to create tmp dir for engine download: %v", err)
-		os.Exit(1)
+		return errors.Wrap(err, "failed to create tmp dir for engine download")
 	}
 	defer os.RemoveAll(dir)
 
@@ -285,12 +345,12 @@ func ValidateOrUpdateEngine(targetOS, cachePath, requiredEngineVersion string) {
 	engineZipPath := filepath.Join(dir, "engine.zip")
 	engineExtractPath := filepath.Join(dir, "engine")
 	artifactsZipPath := filepath.Join(dir, "artifacts.zip")
+	genSnapshotZipPath := filepath.Join(dir, "gen_snapshot.zip")
 
-	log.Printf("Downloading engine for platform %s at version %s...", platform, requiredEngineVersion)
+	log.Printf("Downloading %s engine for platform %s at version %s...", mode, platform, requiredEngineVersion)
 	err = downloadFile(engineZipPath, engineDownloadURL)
 	if err != nil {
-		log.Errorf("Failed to download engine: %v", err)
-		os.Exit(1)
+		return errors.Wrap(err, "failed to download engine")
 	}
 
 	// TODO, optimization: make artifacts download a separate function, it doesn't need to be
@@ -298,8 +358,15 @@ func ValidateOrUpdateEngine(targetOS, cachePath, requiredEngineVersion string) {
 	log.Printf("Downloading artifacts at version %s...", requiredEngineVersion)
 	err = downloadFile(artifactsZipPath, icudtlDownloadURL)
 	if err != nil {
-		log.Errorf("Failed to download artifacts: %v", err)
-		os.Exit(1)
+		return errors.Wrap(err, "failed to download artifacts")
+	}
+
+	if mode != ModeDebug {
+		log.Printf("Downloading gen_snapshot at version %s...", requiredEngineVersion)
+		err = downloadFile(genSnapshotZipPath, genSnapshotDownloadURL)
+		if err != nil {
+			return errors.Wrap(err, "failed to download gen_snapshot")
+		}
 	}
 
 	_, err = unzip(engineZipPath, engineExtractPath) // engineCachePath)
@@ -313,14 +380,29 @@ func ValidateOrUpdateEngine(targetOS, cachePath, requiredEngineVersion string) {
 		log.Warnf("%v", err)
 	}
 
-	switch platform {
-	case "darwin-x64":
+	if mode != ModeDebug {
+		_, err = unzip(genSnapshotZipPath, artifactsCachePath)
+		if err != nil {
+			return errors.Wrap(err, "failed to unzip gen_snapshot")
+		}
+		genSnapshotPath := GenSnapshotPath(targetOS, targetArch, mode, cachePath)
+		_, err = os.Stat(genSnapshotPath)
+		if err != nil {
+			return errors.Wrapf(err, "gen_snapshot not found in the %s artifacts", modePlatform)
+		}
+		err = os.Chmod(genSnapshotPath, 0755)
+		if err != nil {
+			return errors.Wrap(err, "failed to make gen_snapshot executable")
+		}
+	}
+
+	switch targetOS {
+	case "darwin":
 		frameworkZipPath := filepath.Join(engineExtractPath, "FlutterEmbedder.framework.zip")
 		frameworkDestPath := filepath.Join(engineCachePath, "FlutterEmbedder.framework")
 		_, err = unzip(frameworkZipPath, frameworkDestPath)
 		if err != nil {
-			log.Errorf("Failed to unzip engine framework: %v", err)
-			os.Exit(1)
+			return errors.Wrap(err, "failed to unzip engine framework")
 		}
 
 		createSymLink("A", frameworkDestPath+"/Versions/Current")
@@ -329,32 +411,29 @@ func ValidateOrUpdateEngine(targetOS, cachePath, requiredEngineVersion string) {
 		createSymLink("Versions/Current/Modules", frameworkDestPath+"/Modules")
 		createSymLink("Versions/Current/Resources", frameworkDestPath+"/Resources")
 
-	case "linux-x64":
+	case "linux":
 		err := moveFile(
 			filepath.Join(engineExtractPath, "libflutter_engine.so"),
 			filepath.Join(engineCachePath, "/libflutter_engine.so"),
 		)
 		if err != nil {
-			log.Errorf("Failed to move downloaded libflutter_engine.so: %v", err)
-			os.Exit(1)
+			return errors.Wrap(err, "failed to move downloaded libflutter_engine.so")
 		}
 
-	case "windows-x64":
+	case "windows":
 		err := moveFile(
 			filepath.Join(engineExtractPath, "flutter_engine.dll"),
 			filepath.Join(engineCachePath, "/flutter_engine.dll"),
 		)
 		if err != nil {
-			log.Errorf("Failed to move downloaded flutter_engine.dll: %v", err)
-			os.Exit(1)
+			return errors.Wrap(err, "failed to move downloaded flutter_engine.dll")
 		}
 	}
 
 	err = ioutil.WriteFile(cachedEngineVersionPath, []byte(requiredEngineVersion), 0664)
 	if err != nil {
-		log.Errorf("Failed to write version file: %v", err)
-		os.Exit(1)
+		return errors.Wrap(err, "failed to write version file")
 	}
 
-	return
+	return nil
 }
@@ -2,27 +2,25 @@
 package fileutils
 
 import (
-	"os"
 	"sync"
 
 	rice "github.com/GeertJohan/go.rice"
-	"github.com/go-flutter-desktop/hover/internal/log"
+	"github.com/pkg/errors"
 )
 
 var (
 	assetsBox     *rice.Box
+	assetsBoxErr  error
 	assetsBoxOnce sync.Once
 )
 
 // AssetsBox hover's assets box
-func AssetsBox() *rice.Box {
+func AssetsBox() (*rice.Box, error) {
 	assetsBoxOnce.Do(func() {
-		var err error
-		assetsBox, err = rice.FindBox("../../assets")
-		if err != nil {
-			log.Errorf("Failed to find hover assets: %v", err)
-			os.Exit(1)
+		assetsBox, assetsBoxErr = rice.FindBox("../../assets")
+		if assetsBoxErr != nil {
+			assetsBoxErr = errors.Wrap(assetsBoxErr, "failed to find hover assets")
 		}
 	})
-	return assetsBox
+	return assetsBox, assetsBoxErr
 }
@@ -29,9 +29,9 @@ func init() {
 	}
 	file6 := &embedded.EmbeddedFile{
 		Filename:    "app/hover.yaml.tmpl",
-		FileModTime: time.Unix(1590426180, 0),
+		FileModTime: time.Unix(1792153495, 0),
 
-		Content: string("#application-name: \"{{.applicationName}}\" # Uncomment to modify this value.\n#executable-name: \"{{.executableName}}\" # Uncomment to modify this value. Only lowercase a-z, numbers, underscores and no spaces\n#package-name: \"{{.packageName}}\" # Uncomment to modify this value. Only lowercase a-z, numbers and no underscores or spaces\nlicense: \"\" # MANDATORY: Fill in your SPDX license name: https://spdx.org/licenses\ntarget: lib/main_desktop.dart\n# opengl: \"none\" # Uncomment this line if you have trouble with your OpenGL driver (https://github.com/go-flutter-desktop/go-flutter/issues/272)\ndocker: false\nengine-version: \"\" # change to a engine version commit\n"),
+		Content: string("#application-name: \"{{.applicationName}}\" # Uncomment to modify this value.\n#executable-name: \"{{.executableName}}\" # Uncomment to modify this value. Only lowercase a-z, numbers, underscores and no spaces\n#package-name: \"{{.packageName}}\" # Uncomment to modify this value. Only lowercase a-z, numbers and no underscores or spaces\nlicense: \"\" # MANDATORY: Fill in your SPDX license name: https://spdx.org/licenses\ntarget: lib/main_desktop.dart\n# opengl: \"none\" # Uncomment this line if you have trouble with your OpenGL driver (https://github.com/go-flutter-desktop/go-flutter/issues/272)\n# arch: \"arm64\" # Uncomment this line to build for another architecture than amd64 by default\n# targets: [\"linux-deb\", \"windows-msi\"] # Uncomment this line to set the targets built by running `hover build`\n# profiles: # Uncomment these lines to add build profiles, selected with `hover build --profile staging`\n#   staging:\n#     extends: release\n#     vm-arguments: [\"--disable-dart-asserts\"] # keeps the observatory enabled\n#     build-tags: [\"staging\"]\n# dart-defines: # Uncomment these lines to set compile-time variables of the dart code, read with String.fromEnvironment\n#   API_URL: https://api.example.com\n# flutter-build-args: [] # Uncomment this line to pass extra arguments to `flutter build bundle`\n# flavors: # Uncomment these lines to build variants of the app, selected with `hover build --flavor beta`\n#   beta:\n#     target: lib/main_beta.dart\n#     application-name: \"{{.applicationName}} Beta\"\n#     executable-name: \"{{.executableName}}beta\"\n#     package-name: \"{{.packageName}}beta\"\n#     icon: go/assets/icon-beta.png\n# embed-build-info: true # Uncomment this line to embed the build info in the binary, print it with `yourApplicationName --build-info`\n# hooks: # Uncomment these lines to run commands between the build steps, see the README for their environment\n#   pre-go-build: [\"cd go && go generate ./...\"]\n#   pre-package:\n#     linux-deb: [\"cp LICENSE $HOVER_STAGING_DIR/\"]\ndocker: false\nengine-version: \"\" # change to a engine version commit\n"),
 	}
 	file7 := &embedded.EmbeddedFile{
 		Filename:    "app/icon.png",
@@ -41,9 +41,9 @@ func init() {
 	}
 	file8 := &embedded.EmbeddedFile{
 		Filename:    "app/main.go",
-		FileModTime: time.Unix(1579687590, 0),
+		FileModTime: time.Unix(1792155205, 0),
 
-		Content: string("package main\n\nimport (\n\t\"fmt\"\n\t\"image\"\n\t_ \"image/png\"\n\t\"os\"\n\t\"path/filepath\"\n\t\"strings\"\n\n\t\"github.com/go-flutter-desktop/go-flutter\"\n\t\"github.com/pkg/errors\"\n)\n\n// vmArguments may be set by hover at compile-time\nvar vmArguments string\n\nfunc main() {\n\t// DO NOT EDIT, add options in options.go\n\tmainOptions := []flutter.Option{\n\t\tflutter.OptionVMArguments(strings.Split(vmArguments, \";\")),\n\t\tflutter.WindowIcon(iconProvider),\n\t}\n\terr := flutter.Run(append(options, mainOptions...)...)\n\tif err != nil {\n\t\tfmt.Println(err)\n\t\tos.Exit(1)\n\t}\n}\n\nfunc iconProvider() ([]image.Image, error) {\n\texecPath, err := os.Executable()\n\tif err != nil {\n\t\treturn nil, errors.Wrap(err, \"failed to resolve executable path\")\n\t}\n\texecPath, err = filepath.EvalSymlinks(execPath)\n\tif err != nil {\n\t\treturn nil, errors.Wrap(err, \"failed to eval symlinks for executable path\")\n\t}\n\timgFile, err := os.Open(filepath.Join(filepath.Dir(execPath), \"assets\", \"icon.png\"))\n\tif err != nil {\n\t\treturn nil, errors.Wrap(err, \"failed to open assets/icon.png\")\n\t}\n\timg, _, err := image.Decode(imgFile)\n\tif err != nil {\n\t\treturn nil, errors.Wrap(err, \"failed to decode image\")\n\t}\n\treturn []image.Image{img}, nil\n}\n"),
+		Content: string("package main\n\nimport (\n\t\"encoding/base64\"\n\t\"fmt\"\n\t\"image\"\n\t_ \"image/png\"\n\t\"os\"\n\t\"path/filepath\"\n\t\"strings\"\n\n\t\"github.com/go-flutter-desktop/go-flutter\"\n\t\"github.com/pkg/errors\"\n)\n\n// vmArguments may be set by hover at compile-time\nvar vmArguments string\n\n// buildInfo may be set by hover at compile-time, it contains the base64\n// encoded build-info.json of the build.\nvar buildInfo string\n\nfunc main() {\n\tif len(os.Args) == 2 && os.Args[1] == \"--build-info\" {\n\t\tinfo, err := base64.StdEncoding.DecodeString(buildInfo)\n\t\tif err != nil || len(info) == 0 {\n\t\t\tfmt.Println(\"no build info embedded, build with `hover build --embed-build-info`\")\n\t\t\tos.Exit(1)\n\t\t}\n\t\tfmt.Println(string(info))\n\t\treturn\n\t}\n\n\t// DO NOT EDIT, add options in options.go\n\tmainOptions := []flutter.Option{\n\t\tflutter.OptionVMArguments(resolveVMArguments(strings.Split(vmArguments, \";\"))),\n\t\tflutter.WindowIcon(iconProvider),\n\t}\n\terr := flutter.Run(append(options, mainOptions...)...)\n\tif err != nil {\n\t\tfmt.Println(err)\n\t\tos.Exit(1)\n\t}\n}\n\n// resolveVMArguments makes the path of the AOT snapshot set by hover absolute,\n// the snapshot is placed next to the executable.\nfunc resolveVMArguments(args []string) []string {\n\tconst aotLibraryFlag = \"--aot-shared-library-name=\"\n\tfor i, arg := range args {\n\t\tif !strings.HasPrefix(arg, aotLibraryFlag) || filepath.IsAbs(strings.TrimPrefix(arg, aotLibraryFlag)) {\n\t\t\tcontinue\n\t\t}\n\t\texecPath, err := os.Executable()\n\t\tif err != nil {\n\t\t\tcontinue\n\t\t}\n\t\texecPath, err = filepath.EvalSymlinks(execPath)\n\t\tif err != nil {\n\t\t\tcontinue\n\t\t}\n\t\targs[i] = aotLibraryFlag + filepath.Join(filepath.Dir(execPath), strings.TrimPrefix(arg, aotLibraryFlag))\n\t}\n\treturn args\n}\n\n// iconProvider loads the icon of the window: the assets/icon.png and the\n// assets/icon-<size>.png images generated by hover, the window system picks\n// the size it displays.\nfunc iconProvider() ([]image.Image, error) {\n\texecPath, err := os.Executable()\n\tif err != nil {\n\t\treturn nil, errors.Wrap(err, \"failed to resolve executable path\")\n\t}\n\texecPath, err = filepath.EvalSymlinks(execPath)\n\tif err != nil {\n\t\treturn nil, errors.Wrap(err, \"failed to eval symlinks for executable path\")\n\t}\n\tassetsPath := filepath.Join(filepath.Dir(execPath), \"assets\")\n\ticonPaths, err := filepath.Glob(filepath.Join(assetsPath, \"icon-*.png\"))\n\tif err != nil {\n\t\treturn nil, errors.Wrap(err, \"failed to list the icons\")\n\t}\n\tvar images []image.Image\n\tfor _, iconPath := range append([]string{filepath.Join(assetsPath, \"icon.png\")}, iconPaths...) {\n\t\timgFile, err := os.Open(iconPath)\n\t\tif err != nil {\n\t\t\treturn nil, errors.Wrapf(err, \"failed to open assets/%s\", filepath.Base(iconPath))\n\t\t}\n\t\timg, _, err := image.Decode(imgFile)\n\t\timgFile.Close()\n\t\tif err != nil {\n\t\t\treturn nil, errors.Wrapf(err, \"failed to decode assets/%s\", filepath.Base(iconPath))\n\t\t}\n\t\timages = append(images, img)\n\t}\n\treturn images, nil\n}\n"),
 	}
 	file9 := &embedded.EmbeddedFile{
 		Filename:    "app/main_desktop.dart",
@@ -82,72 +82,162 @@ func init() {
 		Content: string("<pkg-info format-version=\"2\" identifier=\"{{.organizationName}}.base.pkg\" version=\"{{.version}}\" install-location=\"/\" auth=\"root\">\n\t<bundle-version>\n\t\t<bundle id=\"{{.organizationName}}\" CFBundleIdentifier=\"{{.organizationName}}.{{.packageName}}\" path=\"./Applications/{{.applicationName}} {{.version}}.app\" CFBundleVersion=\"{{.version}}\"/>\n    </bundle-version>\n</pkg-info>\n"),
 	}
 	filej := &embedded.EmbeddedFile{
+		Filename:    "packaging/darwin-zip/README.txt.tmpl",
+		FileModTime: time.Unix(1792155388, 0),
+
+		Content: string("{{.applicationName}} {{.version}}\n\n{{.description}}\n\nTo install {{.applicationName}}, drag \"{{.applicationName}} {{.version}}.app\" to the Applications folder.\n\nLicense: {{.license}}\n"),
+	}
+	filel := &embedded.EmbeddedFile{
 		Filename:    "packaging/linux/app.desktop.tmpl",
 		FileModTime: time.Unix(1588579782, 0),
 
 		Content: string("[Desktop Entry]\nVersion=1.0\nType=Application\nTerminal=false\nCategories=\nName={{.applicationName}}\nIcon={{.iconPath}}\nExec={{.executablePath}}\n"),
 	}
-	filek := &embedded.EmbeddedFile{
+	filem := &embedded.EmbeddedFile{
 		Filename:    "packaging/linux/bin.tmpl",
 		FileModTime: time.Unix(1588579782, 0),
 
 		Content: string("#!/bin/sh\n/usr/lib/{{.packageName}}/{{.executableName}}\n"),
 	}
-	filem := &embedded.EmbeddedFile{
+	fileo := &embedded.EmbeddedFile{
 		Filename:    "packaging/linux-appimage/AppRun.tmpl",
 		FileModTime: time.Unix(1588579782, 0),
 
 		Content: string("#!/bin/sh\ncd \"$(dirname \"$0\")\"\nexec ./build/{{.executableName}}\n"),
 	}
-	fileo := &embedded.EmbeddedFile{
+	fileq := &embedded.EmbeddedFile{
 		Filename:    "packaging/linux-deb/control.tmpl",
-		FileModTime: time.Unix(1588579782, 0),
+		FileModTime: time.Unix(1792151750, 0),
 
-		Content: string("Package: {{.packageName}}\nArchitecture: amd64\nMaintainer: @{{.author}}\nPriority: optional\nVersion: {{.version}}\nDescription: {{.description}}\n"),
+		Content: string("Package: {{.packageName}}\nArchitecture: {{.arch}}\nMaintainer: @{{.author}}\nPriority: optional\nVersion: {{.version}}\nDescription: {{.description}}\n"),
 	}
-	fileq := &embedded.EmbeddedFile{
+	files := &embedded.EmbeddedFile{
+		Filename:    "packaging/linux-flatpak/manifest.yml.tmpl",
+		FileModTime: time.Unix(1792155460, 0),
+
+		Content: string("app-id: {{.organizationName}}.{{.packageName}}\nruntime: org.freedesktop.Platform\nruntime-version: '23.08'\nsdk: org.freedesktop.Sdk\ncommand: {{.executableName}}\nrename-desktop-file: {{.packageName}}.desktop\nrename-icon: {{.packageName}}\nfinish-args:\n  - --share=ipc\n  - --socket=x11\n  - --device=dri\n  - --share=network # Remove this line if the app doesn't use the network\nmodules:\n  - name: {{.packageName}}\n    buildsystem: simple\n    build-commands:\n      - mkdir -p /app/lib /app/bin /app/share/applications\n      - cp -r build /app/lib/{{.packageName}}\n      - ln -s /app/lib/{{.packageName}}/{{.executableName}} /app/bin/{{.executableName}}\n      - install -m644 {{.packageName}}.desktop /app/share/applications/{{.packageName}}.desktop\n      - cp -r icons /app/share/icons\n    sources:\n      - type: dir\n        path: build\n        dest: build\n      - type: dir\n        path: icons\n        dest: icons\n      - type: file\n        path: {{.packageName}}.desktop\n"),
+	}
+	fileu := &embedded.EmbeddedFile{
+		Filename:    "packaging/linux-nix/default.nix.tmpl",
+		FileModTime: time.Unix(1792156461, 0),
+
+		Content: string("{ pkgs ? import <nixpkgs> { } }:\n\nlet\n  # the libraries the app loads at runtime, the flutter engine and GLFW\n  # load libGL with dlopen\n  runtimeLibraries = with pkgs; [\n    libGL\n    xorg.libX11\n    xorg.libXcursor\n    xorg.libXi\n    xorg.libXinerama\n    xorg.libXrandr\n    xorg.libXxf86vm\n    stdenv.cc.cc.lib\n  ];\nin\npkgs.stdenv.mkDerivation {\n  pname = \"{{.packageName}}\";\n  version = \"{{.version}}\";\n\n  # src.nix is written by hover, it refers to the tarball of the build\n  # output next to this file and to its hash\n  src = import ./src.nix;\n\n  nativeBuildInputs = with pkgs; [ autoPatchelfHook makeWrapper ];\n  buildInputs = runtimeLibraries;\n\n  dontConfigure = true;\n  dontBuild = true;\n\n  installPhase = ''\n    runHook preInstall\n    mkdir -p $out/lib $out/bin\n    cp -r lib $out/lib/{{.packageName}}\n    cp -r share $out/share\n    makeWrapper $out/lib/{{.packageName}}/{{.executableName}} $out/bin/{{.executableName}} \\\n      --prefix LD_LIBRARY_PATH : ${pkgs.lib.makeLibraryPath runtimeLibraries}\n    runHook postInstall\n  '';\n\n  meta = with pkgs.lib; {\n    description = \"{{.description}}\";\n    platforms = platforms.linux;\n    mainProgram = \"{{.executableName}}\";\n  };\n}\n"),
+	}
+	filev := &embedded.EmbeddedFile{
+		Filename:    "packaging/linux-nix/flake.nix.tmpl",
+		FileModTime: time.Unix(1792156461, 0),
+
+		Content: string("{\n  description = \"{{.description}}\";\n\n  inputs.nixpkgs.url = \"github:NixOS/nixpkgs/nixos-unstable\";\n\n  outputs = { self, nixpkgs }:\n    let\n      system = \"{{.arch}}-linux\";\n      pkgs = nixpkgs.legacyPackages.${system};\n    in\n    {\n      packages.${system}.default = import ./default.nix { inherit pkgs; };\n    };\n}\n"),
+	}
+	filex := &embedded.EmbeddedFile{
 		Filename:    "packaging/linux-pkg/PKGBUILD.tmpl",
-		FileModTime: time.Unix(1588579782, 0),
+		FileModTime: time.Unix(1792155843, 0),
 
-		Content: string("pkgname={{.packageName}}\npkgver={{.version}}\npkgrel={{.release}}\npkgdesc=\"{{.description}}\"\narch=(\"x86_64\")\nlicense=('{{.license}}')\n\npackage() {\n    mkdir -p $pkgdir/\n    cp * $pkgdir/ -r\n}\n"),
+		Content: string("pkgname={{.packageName}}\npkgver={{.version}}\npkgrel={{.release}}\npkgdesc=\"{{.description}}\"\narch=(\"{{.arch}}\")\nlicense=('{{.license}}')\n# depends=() # Uncomment this line to add the dependencies of the package\n# install={{.packageName}}.install # Uncomment this line to run an install script, placed next to the PKGBUILD\n"),
 	}
-	files := &embedded.EmbeddedFile{
+	filez := &embedded.EmbeddedFile{
 		Filename:    "packaging/linux-rpm/app.spec.tmpl",
-		FileModTime: time.Unix(1588579782, 0),
+		FileModTime: time.Unix(1792154841, 0),
 
-		Content: string("Name: {{.packageName}}\nVersion: {{.version}}\nRelease: {{.release}}\nSummary: {{.description}}\nLicense: {{.license}}\n\n%description\n{{.description}}\n\n%install\nmkdir -p $RPM_BUILD_ROOT%{_bindir}\nmkdir -p $RPM_BUILD_ROOT/usr/lib/{{.packageName}}\nmkdir -p $RPM_BUILD_ROOT%{_datadir}/applications\ncp -R $RPM_BUILD_DIR/{{.packageName}}-{{.version}}-{{.release}}.x86_64/* $RPM_BUILD_ROOT\nchmod 0755 $RPM_BUILD_ROOT%{_bindir}/{{.executableName}}\nchmod 0755 $RPM_BUILD_ROOT%{_datadir}/applications/{{.executableName}}.desktop\n\n%files\n%{_bindir}/{{.executableName}}\n/usr/lib/{{.packageName}}/\n%{_datadir}/applications/{{.executableName}}.desktop\n"),
+		Content: string("Name: {{.packageName}}\nVersion: {{.version}}\nRelease: {{.release}}\nSummary: {{.description}}\nLicense: {{.license}}\n\n%description\n{{.description}}\n\n%files\n%{_bindir}/{{.executableName}}\n/usr/lib/{{.packageName}}/\n%{_datadir}/applications/{{.executableName}}.desktop\n%{_datadir}/icons/hicolor/*/apps/{{.packageName}}.png\n"),
 	}
-	fileu := &embedded.EmbeddedFile{
+	file11 := &embedded.EmbeddedFile{
 		Filename:    "packaging/linux-snap/snapcraft.yaml.tmpl",
 		FileModTime: time.Unix(1588579782, 0),
 
 		Content: string("name: {{.packageName}}\nbase: core18\nversion: '{{.version}}'\nsummary: {{.description}}\ndescription: |\n  {{.description}}\nconfinement: devmode\ngrade: devel\napps:\n  {{.packageName}}:\n    command: {{.executableName}}\n    desktop: local/{{.executableName}}.desktop\nparts:\n  desktop:\n    plugin: dump\n    source: snap\n  assets:\n    plugin: dump\n    source: build/assets\n  app:\n    plugin: dump\n    source: build\n    stage-packages:\n      - libx11-6\n      - libxrandr2\n      - libxcursor1\n      - libxinerama1\n"),
 	}
-	filew := &embedded.EmbeddedFile{
+	file13 := &embedded.EmbeddedFile{
+		Filename:    "packaging/linux-tar/README.txt.tmpl",
+		FileModTime: time.Unix(1792155388, 0),
+
+		Content: string("{{.applicationName}} {{.version}}\n\n{{.description}}\n\nTo start {{.applicationName}}, run ./{{.executableName}} from this directory.\nThe directory can be moved anywhere, e.g. to ~/.local/share/{{.packageName}}.\nLink the launcher into a directory of your PATH to start it from a terminal:\n\n    ln -s \"$PWD/{{.executableName}}\" ~/.local/bin/{{.executableName}}\n\nLicense: {{.license}}\n"),
+	}
+	file14 := &embedded.EmbeddedFile{
+		Filename:    "packaging/linux-tar/launcher.tmpl",
+		FileModTime: time.Unix(1792155388, 0),
+
+		Content: string("#!/bin/sh\nexec \"$(dirname \"$(readlink -f \"$0\")\")/lib/{{.executableName}}\" \"$@\"\n"),
+	}
+	file17 := &embedded.EmbeddedFile{
+		Filename:    "packaging/publish-manifests/homebrew/{{.packageName}}.rb.tmpl",
+		FileModTime: time.Unix(1792156343, 0),
+
+		Content: string("{{- $dmg := artifacts \"darwin-dmg\" -}}\ncask \"{{.packageName}}\" do\n  version \"{{.version}}\"\n{{- if and (index $dmg \"amd64\") (index $dmg \"arm64\")}}\n\n  on_intel do\n    url \"{{(index $dmg \"amd64\").URL}}\"\n    sha256 \"{{(index $dmg \"amd64\").SHA256}}\"\n  end\n  on_arm do\n    url \"{{(index $dmg \"arm64\").URL}}\"\n    sha256 \"{{(index $dmg \"arm64\").SHA256}}\"\n  end\n{{- else}}{{with or (index $dmg \"amd64\") (index $dmg \"arm64\")}}\n  url \"{{.URL}}\"\n  sha256 \"{{.SHA256}}\"\n{{- end}}{{end}}\n\n  name {{quote .applicationName}}\n  desc {{quote .description}}\n  homepage {{quote .homepage}}\n\n  app \"{{.applicationName}} {{.version}}.app\", target: \"{{.applicationName}}.app\"\nend\n"),
+	}
+	file19 := &embedded.EmbeddedFile{
+		Filename:    "packaging/publish-manifests/scoop/{{.packageName}}.json.tmpl",
+		FileModTime: time.Unix(1792156343, 0),
+
+		Content: string("{{- $zip := artifacts \"windows-zip\" -}}\n{\n    \"version\": \"{{.version}}\",\n    \"description\": {{quote .description}},\n    \"homepage\": {{quote .homepage}},\n    \"license\": {{quote .license}},\n    \"architecture\": {\n{{- with index $zip \"amd64\"}}\n        \"64bit\": {\n            \"url\": {{quote .URL}},\n            \"hash\": \"{{.SHA256}}\"\n        }{{if index $zip \"arm64\"}},{{end}}\n{{- end}}\n{{- with index $zip \"arm64\"}}\n        \"arm64\": {\n            \"url\": {{quote .URL}},\n            \"hash\": \"{{.SHA256}}\"\n        }\n{{- end}}\n    },\n    \"extract_dir\": \"{{.packageName}}-{{.version}}\",\n    \"bin\": [\n        [\n            \"app\\\\{{.executableName}}.exe\",\n            \"{{.executableName}}\"\n        ]\n    ],\n    \"shortcuts\": [\n        [\n            \"app\\\\{{.executableName}}.exe\",\n            {{quote .applicationName}}\n        ]\n    ]\n}\n"),
+	}
+	file1b := &embedded.EmbeddedFile{
+		Filename:    "packaging/publish-manifests/winget/{{.organizationName}}.{{.packageName}}.installer.yaml.tmpl",
+		FileModTime: time.Unix(1792156343, 0),
+
+		Content: string("# yaml-language-server: $schema=https://aka.ms/winget-manifest.installer.1.6.0.schema.json\nPackageIdentifier: {{.organizationName}}.{{.packageName}}\nPackageVersion: {{.version}}\nInstallerType: wix\nInstallers:\n{{- range $arch, $msi := artifacts \"windows-msi\"}}\n  - Architecture: {{if eq $arch \"amd64\"}}x64{{else}}{{$arch}}{{end}}\n    InstallerUrl: {{$msi.URL}}\n    InstallerSha256: {{$msi.SHA256}}\n{{- end}}\nManifestType: installer\nManifestVersion: 1.6.0\n"),
+	}
+	file1c := &embedded.EmbeddedFile{
+		Filename:    "packaging/publish-manifests/winget/{{.organizationName}}.{{.packageName}}.locale.en-US.yaml.tmpl",
+		FileModTime: time.Unix(1792156358, 0),
+
+		Content: string("{{- /* the manifests of winget are skipped together with the installer manifest */ -}}\n{{- $msi := artifacts \"windows-msi\" -}}\n# yaml-language-server: $schema=https://aka.ms/winget-manifest.defaultLocale.1.6.0.schema.json\nPackageIdentifier: {{.organizationName}}.{{.packageName}}\nPackageVersion: {{.version}}\nPackageLocale: en-US\nPublisher: {{quote .author}}\nPackageName: {{quote .applicationName}}\n{{- if .homepage}}\nPackageUrl: {{.homepage}}\n{{- end}}\nLicense: {{quote .license}}\nShortDescription: {{quote .description}}\nManifestType: defaultLocale\nManifestVersion: 1.6.0\n"),
+	}
+	file1d := &embedded.EmbeddedFile{
+		Filename:    "packaging/publish-manifests/winget/{{.organizationName}}.{{.packageName}}.yaml.tmpl",
+		FileModTime: time.Unix(1792156358, 0),
+
+		Content: string("{{- /* the manifests of winget are skipped together with the installer manifest */ -}}\n{{- $msi := artifacts \"windows-msi\" -}}\n# yaml-language-server: $schema=https://aka.ms/winget-manifest.version.1.6.0.schema.json\nPackageIdentifier: {{.organizationName}}.{{.packageName}}\nPackageVersion: {{.version}}\nDefaultLocale: en-US\nManifestType: version\nManifestVersion: 1.6.0\n"),
+	}
+	file1f := &embedded.EmbeddedFile{
 		Filename:    "packaging/windows-msi/app.wxs.tmpl",
 		FileModTime: time.Unix(1589984168, 0),
 
 		Content: string("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<Wix xmlns=\"http://schemas.microsoft.com/wix/2006/wi\">\n    <Product Id=\"*\" UpgradeCode=\"{{.upgradeCode}}\" Version=\"{{.version}}\" Language=\"1033\" Name=\"{{.applicationName}}\" Manufacturer=\"{{.author}}\">\n        <Package InstallerVersion=\"300\" Compressed=\"yes\"/>\n        <Media Id=\"1\" Cabinet=\"{{.packageName}}.cab\" EmbedCab=\"yes\" />\n        <Directory Id=\"TARGETDIR\" Name=\"SourceDir\">\n            <Directory Id=\"ProgramFilesFolder\">\n                <Directory Id=\"APPLICATIONROOTDIRECTORY\" Name=\"{{.applicationName}}\">\n                    <Directory Id=\"ASSETSDIRECTORY\" Name=\"assets\"/>\n                    <Directory Id=\"FLUTTERASSETSDIRECTORY\" Name=\"flutter_assets\">\n                        <?include directories.wxi ?>\n                    </Directory>\n                </Directory>\n            </Directory>\n            <Directory Id=\"ProgramMenuFolder\">\n                <Directory Id=\"ApplicationProgramsFolder\" Name=\"{{.applicationName}}\"/>\n            </Directory>\n        </Directory>\n        <Icon Id=\"ShortcutIcon\" SourceFile=\"build{{.pathSeparator}}assets{{.pathSeparator}}icon.ico\"/>\n        <Property Id=\"ARPPRODUCTICON\" Value=\"ShortcutIcon\"/>\n        <DirectoryRef Id=\"APPLICATIONROOTDIRECTORY\">\n            <Component Id=\"{{.executableName}}.exe\" Guid=\"*\">\n                <File Id=\"{{.executableName}}.exe\" Source=\"build{{.pathSeparator}}{{.executableName}}.exe\" KeyPath=\"yes\"/>\n            </Component>\n            <Component Id=\"flutter_engine.dll\" Guid=\"*\">\n                <File Id=\"flutter_engine.dll\" Source=\"build{{.pathSeparator}}flutter_engine.dll\" KeyPath=\"yes\"/>\n            </Component>\n            <Component Id=\"icudtl.dat\" Guid=\"*\">\n                <File Id=\"icudtl.dat\" Source=\"build{{.pathSeparator}}icudtl.dat\" KeyPath=\"yes\"/>\n            </Component>\n        </DirectoryRef>\n        <DirectoryRef Id=\"ASSETSDIRECTORY\">\n            <Component Id=\"icon.png\" Guid=\"*\">\n                <File Id=\"icon.png\" Source=\"build{{.pathSeparator}}assets{{.pathSeparator}}icon.png\" KeyPath=\"yes\"/>\n            </Component>\n            <Component Id=\"icon.ico\" Guid=\"*\">\n                <File Id=\"icon.ico\" Source=\"build{{.pathSeparator}}assets{{.pathSeparator}}icon.ico\" KeyPath=\"yes\"/>\n            </Component>\n        </DirectoryRef>\n        <?include directory_refs.wxi ?>\n        <DirectoryRef Id=\"ApplicationProgramsFolder\">\n            <Component Id=\"ApplicationShortcut\" Guid=\"*\">\n                <Shortcut Id=\"ApplicationStartMenuShortcut\"\n                          Name=\"{{.applicationName}}\"\n                          Description=\"{{.description}}\"\n                          Target=\"[#{{.executableName}}.exe]\"\n                          WorkingDirectory=\"APPLICATIONROOTDIRECTORY\"\n                          Icon=\"ShortcutIcon\"/>\n                <RemoveFolder Id=\"CleanUpShortCut\" On=\"uninstall\"/>\n                <RegistryValue Root=\"HKCU\" Key=\"Software\\{{.author}}\\{{.packageName}}\" Name=\"installed\" Type=\"integer\" Value=\"1\" KeyPath=\"yes\"/>\n            </Component>\n        </DirectoryRef>\n        <Feature Id=\"MainApplication\" Title=\"{{.applicationName}}\" Level=\"1\">\n            <ComponentRef Id=\"{{.executableName}}.exe\"/>\n            <ComponentRef Id=\"flutter_engine.dll\"/>\n            <ComponentRef Id=\"icudtl.dat\"/>\n            <ComponentRef Id=\"icon.png\"/>\n            <ComponentRef Id=\"ApplicationShortcut\"/>\n            <?include component_refs.wxi ?>\n        </Feature>\n    </Product>\n</Wix>\n"),
 	}
-	filey := &embedded.EmbeddedFile{
+	file1h := &embedded.EmbeddedFile{
+		Filename:    "packaging/windows-msix/AppxManifest.xml.tmpl",
+		FileModTime: time.Unix(1792156196, 0),
+
+		Content: string("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<Package\n  xmlns=\"http://schemas.microsoft.com/appx/manifest/foundation/windows10\"\n  xmlns:uap=\"http://schemas.microsoft.com/appx/manifest/uap/windows10\"\n  xmlns:rescap=\"http://schemas.microsoft.com/appx/manifest/foundation/windows10/restrictedcapabilities\"\n  IgnorableNamespaces=\"uap rescap\">\n  <!-- The Publisher must be the subject of the certificate the package is signed with. -->\n  <Identity Name=\"{{.organizationName}}.{{.packageName}}\" Publisher=\"CN={{.author}}\" Version=\"{{.version}}.0\" ProcessorArchitecture=\"{{.arch}}\"/>\n  <Properties>\n    <DisplayName>{{.applicationName}}</DisplayName>\n    <PublisherDisplayName>{{.author}}</PublisherDisplayName>\n    <Logo>images\\StoreLogo.png</Logo>\n  </Properties>\n  <Dependencies>\n    <TargetDeviceFamily Name=\"Windows.Desktop\" MinVersion=\"10.0.17763.0\" MaxVersionTested=\"10.0.22621.0\"/>\n  </Dependencies>\n  <Resources>\n    <Resource Language=\"en-us\"/>\n  </Resources>\n  <Applications>\n    <Application Id=\"App\" Executable=\"{{.executableName}}.exe\" EntryPoint=\"Windows.FullTrustApplication\">\n      <uap:VisualElements DisplayName=\"{{.applicationName}}\" Description=\"{{.description}}\" BackgroundColor=\"transparent\" Square150x150Logo=\"images\\Square150x150Logo.png\" Square44x44Logo=\"images\\Square44x44Logo.png\"/>\n    </Application>\n  </Applications>\n  <Capabilities>\n    <rescap:Capability Name=\"runFullTrust\"/>\n  </Capabilities>\n</Package>\n"),
+	}
+	file1j := &embedded.EmbeddedFile{
+		Filename:    "packaging/windows-nsis/app.nsi.tmpl",
+		FileModTime: time.Unix(1792155991, 0),
+
+		Content: string("; Installer of {{.applicationName}}, built with makensis.\n; OUTFILE is defined by hover, uninstall-files.nsh lists the installed files.\nUnicode true\nSetCompressor /SOLID lzma\n\n!define UNINSTALL_KEY \"Software\\Microsoft\\Windows\\CurrentVersion\\Uninstall\\{{.organizationName}}.{{.packageName}}\"\n\nName \"{{.applicationName}}\"\nOutFile \"${OUTFILE}\"\n\n; Administrators choose between an install for all users, into Program Files,\n; and an install for the current user, into %LOCALAPPDATA%. The directory of\n; the previous install is reused to upgrade in place.\n!define MULTIUSER_EXECUTIONLEVEL Highest\n!define MULTIUSER_MUI\n!define MULTIUSER_INSTALLMODE_COMMANDLINE\n!define MULTIUSER_USE_PROGRAMFILES64\n!define MULTIUSER_INSTALLMODE_INSTDIR \"{{.applicationName}}\"\n!define MULTIUSER_INSTALLMODE_INSTDIR_REGISTRY_KEY \"${UNINSTALL_KEY}\"\n!define MULTIUSER_INSTALLMODE_INSTDIR_REGISTRY_VALUENAME \"InstallLocation\"\n!include MultiUser.nsh\n!include MUI2.nsh\n!include LogicLib.nsh\n!include FileFunc.nsh\n\n!define MUI_ICON \"icon.ico\"\n!define MUI_UNICON \"icon.ico\"\n\n!insertmacro MUI_PAGE_WELCOME\n!insertmacro MULTIUSER_PAGE_INSTALLMODE\n!insertmacro MUI_PAGE_DIRECTORY\n!insertmacro MUI_PAGE_COMPONENTS\n!insertmacro MUI_PAGE_INSTFILES\n!define MUI_FINISHPAGE_RUN \"$INSTDIR\\{{.executableName}}.exe\"\n!insertmacro MUI_PAGE_FINISH\n\n!insertmacro MUI_UNPAGE_CONFIRM\n!insertmacro MUI_UNPAGE_INSTFILES\n\n!insertmacro MUI_LANGUAGE \"English\"\n\nFunction .onInit\n  !insertmacro MULTIUSER_INIT\nFunctionEnd\n\nFunction un.onInit\n  !insertmacro MULTIUSER_UNINIT\nFunctionEnd\n\nSection \"{{.applicationName}}\" SectionApplication\n  SectionIn RO\n\n  ; Remove the files of the previous version before upgrading.\n  ReadRegStr $0 SHCTX \"${UNINSTALL_KEY}\" \"UninstallString\"\n  ReadRegStr $1 SHCTX \"${UNINSTALL_KEY}\" \"InstallLocation\"\n  ${If} $0 != \"\"\n  ${AndIf} ${FileExists} \"$1\\uninstall.exe\"\n    ExecWait '$0 /S _?=$1'\n  ${EndIf}\n\n  SetOutPath \"$INSTDIR\"\n  File /r \"build\\*.*\"\n  File \"icon.ico\"\n  WriteUninstaller \"$INSTDIR\\uninstall.exe\"\n\n  CreateShortCut \"$SMPROGRAMS\\{{.applicationName}}.lnk\" \"$INSTDIR\\{{.executableName}}.exe\" \"\" \"$INSTDIR\\icon.ico\"\n\n  WriteRegStr SHCTX \"${UNINSTALL_KEY}\" \"DisplayName\" \"{{.applicationName}}\"\n  WriteRegStr SHCTX \"${UNINSTALL_KEY}\" \"DisplayVersion\" \"{{.version}}\"\n  WriteRegStr SHCTX \"${UNINSTALL_KEY}\" \"Publisher\" \"{{.author}}\"\n  WriteRegStr SHCTX \"${UNINSTALL_KEY}\" \"DisplayIcon\" \"$INSTDIR\\icon.ico\"\n  WriteRegStr SHCTX \"${UNINSTALL_KEY}\" \"InstallLocation\" \"$INSTDIR\"\n  WriteRegStr SHCTX \"${UNINSTALL_KEY}\" \"UninstallString\" '\"$INSTDIR\\uninstall.exe\" /$MultiUser.InstallMode'\n  WriteRegStr SHCTX \"${UNINSTALL_KEY}\" \"QuietUninstallString\" '\"$INSTDIR\\uninstall.exe\" /$MultiUser.InstallMode /S'\n  WriteRegDWORD SHCTX \"${UNINSTALL_KEY}\" \"NoModify\" 1\n  WriteRegDWORD SHCTX \"${UNINSTALL_KEY}\" \"NoRepair\" 1\n  ${GetSize} \"$INSTDIR\" \"/S=0K\" $0 $1 $2\n  IntFmt $0 \"0x%08X\" $0\n  WriteRegDWORD SHCTX \"${UNINSTALL_KEY}\" \"EstimatedSize\" \"$0\"\nSectionEnd\n\nSection \"Desktop shortcut\" SectionDesktopShortcut\n  CreateShortCut \"$DESKTOP\\{{.applicationName}}.lnk\" \"$INSTDIR\\{{.executableName}}.exe\" \"\" \"$INSTDIR\\icon.ico\"\nSectionEnd\n\nSection \"Uninstall\"\n  Delete \"$SMPROGRAMS\\{{.applicationName}}.lnk\"\n  Delete \"$DESKTOP\\{{.applicationName}}.lnk\"\n\n  ; Only the installed files are removed, the install directory may contain\n  ; other files.\n  !include \"uninstall-files.nsh\"\n  Delete \"$INSTDIR\\icon.ico\"\n  Delete \"$INSTDIR\\uninstall.exe\"\n  RMDir \"$INSTDIR\"\n\n  DeleteRegKey SHCTX \"${UNINSTALL_KEY}\"\nSectionEnd\n"),
+	}
+	file1l := &embedded.EmbeddedFile{
+		Filename:    "packaging/windows-zip/README.txt.tmpl",
+		FileModTime: time.Unix(1792155388, 0),
+
+		Content: string("{{.applicationName}} {{.version}}\r\n\r\n{{.description}}\r\n\r\nTo start {{.applicationName}}, double-click {{.executableName}}.bat in this folder.\r\nThe folder can be moved anywhere, the files of the app are in the app folder.\r\n\r\nLicense: {{.license}}\r\n"),
+	}
+	file1m := &embedded.EmbeddedFile{
+		Filename:    "packaging/windows-zip/launcher.bat.tmpl",
+		FileModTime: time.Unix(1792155388, 0),
+
+		Content: string("@echo off\r\nstart \"\" \"%~dp0app\\{{.executableName}}.exe\" %*\r\n"),
+	}
+	file1o := &embedded.EmbeddedFile{
 		Filename:    "plugin/README.md.dlib.tmpl",
 		FileModTime: time.Unix(1579687590, 0),
 
 		Content: string("The `dlib` folder is used for the plugins which use `cgo`.\n\nIf your go-flutter plugin dose't use `cgo`, just ignore this file and the `dlib` folder.\n\nWhen you need to link prebuild dynamic libraries and frameworks,\nyou should copy the prebuild dynamic libraries and frameworks to `dlib`/${os} folder.\n\n`hover plugins get` copy this files to path `./go/build/intermediates` of go-flutter app project.\n`hover run` copy files from `./go/build/intermediates/${targetOS}` to `./go/build/outputs/${targetOS}`.\nAnd `-L{./go/build/outputs/${targetOS}}` is appended to `cgoLdflags` automatically.\nAlso `-F{./go/build/outputs/${targetOS}}` is appended to `cgoLdflags` on Mac OS\n\nAttention: `hover` can't resolve the conflicts\nif two different go-flutter plugins have file with the same name in there dlib folder\n"),
 	}
-	filez := &embedded.EmbeddedFile{
+	file1p := &embedded.EmbeddedFile{
 		Filename:    "plugin/README.md.tmpl",
 		FileModTime: time.Unix(1579687590, 0),
 
 		Content: string("# {{.pluginName}}\n\nThis Go package implements the host-side of the Flutter [{{.pluginName}}](https://{{.urlVSCRepo}}) plugin.\n\n## Usage\n\nImport as:\n\n```go\nimport {{.pluginName}} \"{{.urlVSCRepo}}/go\"\n```\n\nThen add the following option to your go-flutter [application options](https://github.com/go-flutter-desktop/go-flutter/wiki/Plugin-info):\n\n```go\nflutter.AddPlugin(&{{.pluginName}}.{{.structName}}{}),\n```\n"),
 	}
-	file10 := &embedded.EmbeddedFile{
+	file1q := &embedded.EmbeddedFile{
 		Filename:    "plugin/import.go.tmpl.tmpl",
 		FileModTime: time.Unix(1579687590, 0),
 
 		Content: string("package main\n\n// DO NOT EDIT, this file is generated by hover at compile-time for the {{.pluginName}} plugin.\n\nimport (\n\tflutter \"github.com/go-flutter-desktop/go-flutter\"\n\t{{.pluginName}} \"{{.urlVSCRepo}}/go\"\n)\n\nfunc init() {\n\t// Only the init function can be tweaked by plugin maker.\n\toptions = append(options, flutter.AddPlugin(&{{.pluginName}}.{{.structName}}{}))\n}\n"),
 	}
-	file11 := &embedded.EmbeddedFile{
+	file1r := &embedded.EmbeddedFile{
 		Filename:    "plugin/plugin.go.tmpl",
 		FileModTime: time.Unix(1579687590, 0),
 
@@ -165,7 +255,7 @@ func init() {
 	}
 	dir3 := &embedded.EmbeddedDir{
 		Filename:   "app",
-		DirModTime: time.Unix(1590426180, 0),
+		DirModTime: time.Unix(1792155205, 0),
 		ChildFiles: []*embedded.EmbeddedFile{
 			file4, // "app/gitignore"
 			file5, // "app/go.mod"
@@ -203,149 +293,289 @@ func init() {
 		},
 	}
 	diri := &embedded.EmbeddedDir{
+		Filename:   "packaging/darwin-zip",
+		DirModTime: time.Unix(1792155388, 0),
+		ChildFiles: []*embedded.EmbeddedFile{
+			filej, // "packaging/darwin-zip/README.txt.tmpl"
+
+		},
+	}
+	dirk := &embedded.EmbeddedDir{
 		Filename:   "packaging/linux",
 		DirModTime: time.Unix(1588579782, 0),
 		ChildFiles: []*embedded.EmbeddedFile{
-			filej, // "packaging/linux/app.desktop.tmpl"
-			filek, // "packaging/linux/bin.tmpl"
+			filel, // "packaging/linux/app.desktop.tmpl"
+			filem, // "packaging/linux/bin.tmpl"
 
 		},
 	}
-	dirl := &embedded.EmbeddedDir{
+	dirn := &embedded.EmbeddedDir{
 		Filename:   "packaging/linux-appimage",
 		DirModTime: time.Unix(1588579782, 0),
 		ChildFiles: []*embedded.EmbeddedFile{
-			filem, // "packaging/linux-appimage/AppRun.tmpl"
+			fileo, // "packaging/linux-appimage/AppRun.tmpl"
 
 		},
 	}
-	dirn := &embedded.EmbeddedDir{
+	dirp := &embedded.EmbeddedDir{
 		Filename:   "packaging/linux-deb",
-		DirModTime: time.Unix(1588579782, 0),
+		DirModTime: time.Unix(1792151750, 0),
 		ChildFiles: []*embedded.EmbeddedFile{
-			fileo, // "packaging/linux-deb/control.tmpl"
+			fileq, // "packaging/linux-deb/control.tmpl"
 
 		},
 	}
-	dirp := &embedded.EmbeddedDir{
+	dirr := &embedded.EmbeddedDir{
+		Filename:   "packaging/linux-flatpak",
+		DirModTime: time.Unix(1792155460, 0),
+		ChildFiles: []*embedded.EmbeddedFile{
+			files, // "packaging/linux-flatpak/manifest.yml.tmpl"
+
+		},
+	}
+	dirt := &embedded.EmbeddedDir{
+		Filename:   "packaging/linux-nix",
+		DirModTime: time.Unix(1792156461, 0),
+		ChildFiles: []*embedded.EmbeddedFile{
+			fileu, // "packaging/linux-nix/default.nix.tmpl"
+			filev, // "packaging/linux-nix/flake.nix.tmpl"
+
+		},
+	}
+	dirw := &embedded.EmbeddedDir{
 		Filename:   "packaging/linux-pkg",
-		DirModTime: time.Unix(1588579782, 0),
+		DirModTime: time.Unix(1792155843, 0),
 		ChildFiles: []*embedded.EmbeddedFile{
-			fileq, // "packaging/linux-pkg/PKGBUILD.tmpl"
+			filex, // "packaging/linux-pkg/PKGBUILD.tmpl"
 
 		},
 	}
-	dirr := &embedded.EmbeddedDir{
+	diry := &embedded.EmbeddedDir{
 		Filename:   "packaging/linux-rpm",
-		DirModTime: time.Unix(1588579782, 0),
+		DirModTime: time.Unix(1792154841, 0),
 		ChildFiles: []*embedded.EmbeddedFile{
-			files, // "packaging/linux-rpm/app.spec.tmpl"
+			filez, // "packaging/linux-rpm/app.spec.tmpl"
 
 		},
 	}
-	dirt := &embedded.EmbeddedDir{
+	dir10 := &embedded.EmbeddedDir{
 		Filename:   "packaging/linux-snap",
 		DirModTime: time.Unix(1588579782, 0),
 		ChildFiles: []*embedded.EmbeddedFile{
-			fileu, // "packaging/linux-snap/snapcraft.yaml.tmpl"
+			file11, // "packaging/linux-snap/snapcraft.yaml.tmpl"
+
+		},
+	}
+	dir12 := &embedded.EmbeddedDir{
+		Filename:   "packaging/linux-tar",
+		DirModTime: time.Unix(1792155388, 0),
+		ChildFiles: []*embedded.EmbeddedFile{
+			file13, // "packaging/linux-tar/README.txt.tmpl"
+			file14, // "packaging/linux-tar/launcher.tmpl"
 
 		},
 	}
-	dirv := &embedded.EmbeddedDir{
+	dir15 := &embedded.EmbeddedDir{
+		Filename:   "packaging/publish-manifests",
+		DirModTime: time.Unix(1792156343, 0),
+		ChildFiles: []*embedded.EmbeddedFile{},
+	}
+	dir16 := &embedded.EmbeddedDir{
+		Filename:   "packaging/publish-manifests/homebrew",
+		DirModTime: time.Unix(1792156343, 0),
+		ChildFiles: []*embedded.EmbeddedFile{
+			file17, // "packaging/publish-manifests/homebrew/{{.packageName}}.rb.tmpl"
+
+		},
+	}
+	dir18 := &embedded.EmbeddedDir{
+		Filename:   "packaging/publish-manifests/scoop",
+		DirModTime: time.Unix(1792156343, 0),
+		ChildFiles: []*embedded.EmbeddedFile{
+			file19, // "packaging/publish-manifests/scoop/{{.packageName}}.json.tmpl"
+
+		},
+	}
+	dir1a := &embedded.EmbeddedDir{
+		Filename:   "packaging/publish-manifests/winget",
+		DirModTime: time.Unix(1792156358, 0),
+		ChildFiles: []*embedded.EmbeddedFile{
+			file1b, // "packaging/publish-manifests/winget/{{.organizationName}}.{{.packageName}}.installer.yaml.tmpl"
+			file1c, // "packaging/publish-manifests/winget/{{.organizationName}}.{{.packageName}}.locale.en-US.yaml.tmpl"
+			file1d, // "packaging/publish-manifests/winget/{{.organizationName}}.{{.packageName}}.yaml.tmpl"
+
+		},
+	}
+	dir1e := &embedded.EmbeddedDir{
 		Filename:   "packaging/windows-msi",
 		DirModTime: time.Unix(1589984168, 0),
 		ChildFiles: []*embedded.EmbeddedFile{
-			filew, // "packaging/windows-msi/app.wxs.tmpl"
+			file1f, // "packaging/windows-msi/app.wxs.tmpl"
+
+		},
+	}
+	dir1g := &embedded.EmbeddedDir{
+		Filename:   "packaging/windows-msix",
+		DirModTime: time.Unix(1792156196, 0),
+		ChildFiles: []*embedded.EmbeddedFile{
+			file1h, // "packaging/windows-msix/AppxManifest.xml.tmpl"
+
+		},
+	}
+	dir1i := &embedded.EmbeddedDir{
+		Filename:   "packaging/windows-nsis",
+		DirModTime: time.Unix(1792155991, 0),
+		ChildFiles: []*embedded.EmbeddedFile{
+			file1j, // "packaging/windows-nsis/app.nsi.tmpl"
+
+		},
+	}
+	dir1k := &embedded.EmbeddedDir{
+		Filename:   "packaging/windows-zip",
+		DirModTime: time.Unix(1792155388, 0),
+		ChildFiles: []*embedded.EmbeddedFile{
+			file1l, // "packaging/windows-zip/README.txt.tmpl"
+			file1m, // "packaging/windows-zip/launcher.bat.tmpl"
 
 		},
 	}
-	dirx := &embedded.EmbeddedDir{
+	dir1n := &embedded.EmbeddedDir{
 		Filename:   "plugin",
 		DirModTime: time.Unix(1579687590, 0),
 		ChildFiles: []*embedded.EmbeddedFile{
-			filey,  // "plugin/README.md.dlib.tmpl"
-			filez,  // "plugin/README.md.tmpl"
-			file10, // "plugin/import.go.tmpl.tmpl"
-			file11, // "plugin/plugin.go.tmpl"
+			file1o, // "plugin/README.md.dlib.tmpl"
+			file1p, // "plugin/README.md.tmpl"
+			file1q, // "plugin/import.go.tmpl.tmpl"
+			file1r, // "plugin/plugin.go.tmpl"
 
 		},
 	}
 
 	// link ChildDirs
 	dir1.ChildDirs = []*embedded.EmbeddedDir{
-		dir3, // "app"
-		dirb, // "packaging"
-		dirx, // "plugin"
+		dir3,  // "app"
+		dirb,  // "packaging"
+		dir1n, // "plugin"
 
 	}
 	dir3.ChildDirs = []*embedded.EmbeddedDir{}
 	dirb.ChildDirs = []*embedded.EmbeddedDir{
-		dird, // "packaging/darwin-bundle"
-		dirf, // "packaging/darwin-pkg"
-		diri, // "packaging/linux"
-		dirl, // "packaging/linux-appimage"
-		dirn, // "packaging/linux-deb"
-		dirp, // "packaging/linux-pkg"
-		dirr, // "packaging/linux-rpm"
-		dirt, // "packaging/linux-snap"
-		dirv, // "packaging/windows-msi"
+		dird,  // "packaging/darwin-bundle"
+		dirf,  // "packaging/darwin-pkg"
+		diri,  // "packaging/darwin-zip"
+		dirk,  // "packaging/linux"
+		dirn,  // "packaging/linux-appimage"
+		dirp,  // "packaging/linux-deb"
+		dirr,  // "packaging/linux-flatpak"
+		dirt,  // "packaging/linux-nix"
+		dirw,  // "packaging/linux-pkg"
+		diry,  // "packaging/linux-rpm"
+		dir10, // "packaging/linux-snap"
+		dir12, // "packaging/linux-tar"
+		dir15, // "packaging/publish-manifests"
+		dir1e, // "packaging/windows-msi"
+		dir1g, // "packaging/windows-msix"
+		dir1i, // "packaging/windows-nsis"
+		dir1k, // "packaging/windows-zip"
 
 	}
 	dird.ChildDirs = []*embedded.EmbeddedDir{}
 	dirf.ChildDirs = []*embedded.EmbeddedDir{}
 	diri.ChildDirs = []*embedded.EmbeddedDir{}
-	dirl.ChildDirs = []*embedded.EmbeddedDir{}
+	dirk.ChildDirs = []*embedded.EmbeddedDir{}
 	dirn.ChildDirs = []*embedded.EmbeddedDir{}
 	dirp.ChildDirs = []*embedded.EmbeddedDir{}
 	dirr.ChildDirs = []*embedded.EmbeddedDir{}
 	dirt.ChildDirs = []*embedded.EmbeddedDir{}
-	dirv.ChildDirs = []*embedded.EmbeddedDir{}
-	dirx.ChildDirs = []*embedded.EmbeddedDir{}
+	dirw.ChildDirs = []*embedded.EmbeddedDir{}
+	diry.ChildDirs = []*embedded.EmbeddedDir{}
+	dir10.ChildDirs = []*embedded.EmbeddedDir{}
+	dir12.ChildDirs = []*embedded.EmbeddedDir{}
+	dir15.ChildDirs = []*embedded.EmbeddedDir{
+		dir16, // "packaging/publish-manifests/homebrew"
+		dir18, // "packaging/publish-manifests/scoop"
+		dir1a, // "packaging/publish-manifests/winget"
+
+	}
+	dir16.ChildDirs = []*embedded.EmbeddedDir{}
+	dir18.ChildDirs = []*embedded.EmbeddedDir{}
+	dir1a.ChildDirs = []*embedded.EmbeddedDir{}
+	dir1e.ChildDirs = []*embedded.EmbeddedDir{}
+	dir1g.ChildDirs = []*embedded.EmbeddedDir{}
+	dir1i.ChildDirs = []*embedded.EmbeddedDir{}
+	dir1k.ChildDirs = []*embedded.EmbeddedDir{}
+	dir1n.ChildDirs = []*embedded.EmbeddedDir{}
 
 	// register embeddedBox
 	embedded.RegisterEmbeddedBox(`../../assets`, &embedded.EmbeddedBox{
 		Name: `../../assets`,
 		Time: time.Unix(1588579782, 0),
 		Dirs: map[string]*embedded.EmbeddedDir{
-			"":                         dir1,
-			"app":                      dir3,
-			"packaging":                dirb,
-			"packaging/darwin-bundle":  dird,
-			"packaging/darwin-pkg":     dirf,
-			"packaging/linux":          diri,
-			"packaging/linux-appimage": dirl,
-			"packaging/linux-deb":      dirn,
-			"packaging/linux-pkg":      dirp,
-			"packaging/linux-rpm":      dirr,
-			"packaging/linux-snap":     dirt,
-			"packaging/windows-msi":    dirv,
-			"plugin":                   dirx,
+			"":                                     dir1,
+			"app":                                  dir3,
+			"packaging":                            dirb,
+			"packaging/darwin-bundle":              dird,
+			"packaging/darwin-pkg":                 dirf,
+			"packaging/darwin-zip":                 diri,
+			"packaging/linux":                      dirk,
+			"packaging/linux-appimage":             dirn,
+			"packaging/linux-deb":                  dirp,
+			"packaging/linux-flatpak":              dirr,
+			"packaging/linux-nix":                  dirt,
+			"packaging/linux-pkg":                  dirw,
+			"packaging/linux-rpm":                  diry,
+			"packaging/linux-snap":                 dir10,
+			"packaging/linux-tar":                  dir12,
+			"packaging/publish-manifests":          dir15,
+			"packaging/publish-manifests/homebrew": dir16,
+			"packaging/publish-manifests/scoop":    dir18,
+			"packaging/publish-manifests/winget":   dir1a,
+			"packaging/windows-msi":                dir1e,
+			"packaging/windows-msix":               dir1g,
+			"packaging/windows-nsis":               dir1i,
+			"packaging/windows-zip":                dir1k,
+			"plugin":                               dir1n,
 		},
 		Files: map[string]*embedded.EmbeddedFile{
-			"README.md":                                file2,
-			"app/gitignore":                            file4,
-			"app/go.mod":                               file5,
-			"app/hover.yaml.tmpl":                      file6,
-			"app/icon.png":                             file7,
-			"app/main.go":                              file8,
-			"app/main_desktop.dart":                    file9,
-			"app/options.go":                           filea,
-			"packaging/README.md":                      filec,
-			"packaging/darwin-bundle/Info.plist.tmpl":  filee,
-			"packaging/darwin-pkg/Distribution.tmpl":   fileg,
-			"packaging/darwin-pkg/PackageInfo.tmpl":    fileh,
-			"packaging/linux/app.desktop.tmpl":         filej,
-			"packaging/linux/bin.tmpl":                 filek,
-			"packaging/linux-appimage/AppRun.tmpl":     filem,
-			"packaging/linux-deb/control.tmpl":         fileo,
-			"packaging/linux-pkg/PKGBUILD.tmpl":        fileq,
-			"packaging/linux-rpm/app.spec.tmpl":        files,
-			"packaging/linux-snap/snapcraft.yaml.tmpl": fileu,
-			"packaging/windows-msi/app.wxs.tmpl":       filew,
-			"plugin/README.md.dlib.tmpl":               filey,
-			"plugin/README.md.tmpl":                    filez,
-			"plugin/import.go.tmpl.tmpl":               file10,
-			"plugin/plugin.go.tmpl":                    file11,
+			"README.md":                                                                                        file2,
+			"app/gitignore":                                                                                    file4,
+			"app/go.mod":                                                                                       file5,
+			"app/hover.yaml.tmpl":                                                                              file6,
+			"app/icon.png":                                                                                     file7,
+			"app/main.go":                                                                                      file8,
+			"app/main_desktop.dart":                                                                            file9,
+			"app/options.go":                                                                                   filea,
+			"packaging/README.md":                                                                              filec,
+			"packaging/darwin-bundle/Info.plist.tmpl":                                                          filee,
+			"packaging/darwin-pkg/Distribution.tmpl":                                                           fileg,
+			"packaging/darwin-pkg/PackageInfo.tmpl":                                                            fileh,
+			"packaging/darwin-zip/README.txt.tmpl":                                                             filej,
+			"packaging/linux/app.desktop.tmpl":                                                                 filel,
+			"packaging/linux/bin.tmpl":                                                                         filem,
+			"packaging/linux-appimage/AppRun.tmpl":                                                             fileo,
+			"packaging/linux-deb/control.tmpl":                                                                 fileq,
+			"packaging/linux-flatpak/manifest.yml.tmpl":                                                        files,
+			"packaging/linux-nix/default.nix.tmpl":                                                             fileu,
+			"packaging/linux-nix/flake.nix.tmpl":                                                               filev,
+			"packaging/linux-pkg/PKGBUILD.tmpl":                                                                filex,
+			"packaging/linux-rpm/app.spec.tmpl":                                                                filez,
+			"packaging/linux-snap/snapcraft.yaml.tmpl":                                                         file11,
+			"packaging/linux-tar/README.txt.tmpl":                                                              file13,
+			"packaging/linux-tar/launcher.tmpl":                                                                file14,
+			"packaging/publish-manifests/homebrew/{{.packageName}}.rb.tmpl":                                    file17,
+			"packaging/publish-manifests/scoop/{{.packageName}}.json.tmpl":                                     file19,
+			"packaging/publish-manifests/winget/{{.organizationName}}.{{.packageName}}.installer.yaml.tmpl":    file1b,
+			"packaging/publish-manifests/winget/{{.organizationName}}.{{.packageName}}.locale.en-US.yaml.tmpl": file1c,
+			"packaging/publish-manifests/winget/{{.organizationName}}.{{.packageName}}.yaml.tmpl":              file1d,
+			"packaging/windows-msi/app.wxs.tmpl":                                                               file1f,
+			"packaging/windows-msix/AppxManifest.xml.tmpl":                                                     file1h,
+			"packaging/windows-nsis/app.nsi.tmpl":                                                              file1j,
+			"packaging/windows-zip/README.txt.tmpl":                                                            file1l,
+			"packaging/windows-zip/launcher.bat.tmpl":                                                          file1m,
+			"plugin/README.md.dlib.tmpl":                                                                       file1o,
+			"plugin/README.md.tmpl":                                                                            file1p,
+			"plugin/import.go.tmpl.tmpl":                                                                       file1q,
+			"plugin/plugin.go.tmpl":                                                                            file1r,
 		},
 	})
 }
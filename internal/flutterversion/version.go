@@ -3,28 +3,82 @@ package flutterversion
 import (
 	"bytes"
 	"encoding/json"
-	"os"
 	"os/exec"
+	"path/filepath"
+
+	"github.com/pkg/errors"
 
 	"github.com/go-flutter-desktop/hover/internal/build"
-	"github.com/go-flutter-desktop/hover/internal/log"
 )
 
 // FlutterRequiredEngineVersion returns the commit hash of the engine in use
-func FlutterRequiredEngineVersion() string {
-	return readFlutterVersion().EngineRevision
+func FlutterRequiredEngineVersion() (string, error) {
+	response, err := readFlutterVersion()
+	if err != nil {
+		return "", err
+	}
+	return response.EngineRevision, nil
+}
+
+// Version contains the versions of the flutter installation
+type Version struct {
+	Framework string
+	Channel   string
+	Engine    string
+}
+
+// FlutterVersion returns the versions of the flutter installation
+func FlutterVersion() (Version, error) {
+	response, err := readFlutterVersion()
+	if err != nil {
+		return Version{}, err
+	}
+	return Version{
+		Framework: response.FrameworkVersion,
+		Channel:   response.Channel,
+		Engine:    response.EngineRevision,
+	}, nil
 }
 
 // FlutterChannel returns the channel of the flutter installation
-func FlutterChannel() string {
-	return readFlutterVersion().Channel
+func FlutterChannel() (string, error) {
+	response, err := readFlutterVersion()
+	if err != nil {
+		return "", err
+	}
+	return response.Channel, nil
 }
 
-func readFlutterVersion() flutterVersionResponse {
-	out, err := exec.Command(build.FlutterBin(), "--version", "--machine").Output()
+// FlutterRoot returns the directory of the flutter installation
+func FlutterRoot() (string, error) {
+	response, err := readFlutterVersion()
+	if err != nil {
+		return "", err
+	}
+	if response.FlutterRoot != "" {
+		return response.FlutterRoot, nil
+	}
+	// older flutter versions don't report their root, it's the parent of the
+	// bin directory containing the flutter executable.
+	flutterBin, err := build.FlutterBin()
+	if err != nil {
+		return "", err
+	}
+	flutterBin, err = filepath.EvalSymlinks(flutterBin)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to resolve the flutter executable")
+	}
+	return filepath.Dir(filepath.Dir(flutterBin)), nil
+}
+
+func readFlutterVersion() (flutterVersionResponse, error) {
+	flutterBin, err := build.FlutterBin()
+	if err != nil {
+		return flutterVersionResponse{}, err
+	}
+	out, err := exec.Command(flutterBin, "--version", "--machine").Output()
 	if err != nil {
-		log.Errorf("Failed to run %s: %v", log.Au().Magenta("flutter --version --machine"), err)
-		os.Exit(1)
+		return flutterVersionResponse{}, errors.Wrap(err, "failed to run `flutter --version --machine`")
 	}
 
 	// Read bytes from the stdout until we receive what looks like the start of
@@ -34,8 +88,7 @@ func readFlutterVersion() flutterVersionResponse {
 	for {
 		b, err := outputBuffer.ReadByte()
 		if err != nil {
-			log.Errorf("Failed to run %s: did not return information in json", log.Au().Magenta("flutter --version --machine"))
-			os.Exit(1)
+			return flutterVersionResponse{}, errors.New("failed to run `flutter --version --machine`: did not return information in json")
 		}
 		if b == '{' {
 			outputBuffer.UnreadByte()
@@ -46,13 +99,14 @@ func readFlutterVersion() flutterVersionResponse {
 	var response flutterVersionResponse
 	err = json.NewDecoder(outputBuffer).Decode(&response)
 	if err != nil {
-		log.Errorf("Failed parsing json: %v", err)
-		os.Exit(1)
+		return flutterVersionResponse{}, errors.Wrap(err, "failed parsing json")
 	}
-	return response
+	return response, nil
 }
 
 type flutterVersionResponse struct {
-	Channel        string
-	EngineRevision string
+	FrameworkVersion string
+	Channel          string
+	EngineRevision   string
+	FlutterRoot      string
 }
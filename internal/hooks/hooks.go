@@ -0,0 +1,87 @@
+// Package hooks runs the commands configured in the hooks field of
+// hover.yaml between the build steps.
+package hooks
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/hover/internal/log"
+)
+
+// Names of the hooks.
+const (
+	PreFlutterBundle = "pre-flutter-bundle"
+	PreGoBuild       = "pre-go-build"
+	PostGoBuild      = "post-go-build"
+	PrePackage       = "pre-package"
+	PostPackage      = "post-package"
+)
+
+// Env describes the build step a hook runs for. It is passed to the hook
+// commands as HOVER_* environment variables, empty fields are not set.
+type Env struct {
+	// TargetOS is set as HOVER_TARGET_OS.
+	TargetOS string
+	// Arch is set as HOVER_ARCH.
+	Arch string
+	// Version is the version number of the application, set as
+	// HOVER_VERSION.
+	Version string
+	// OutputDir is the output directory of the build or of the packaging
+	// format, set as HOVER_OUTPUT_DIR.
+	OutputDir string
+	// StagingDir is the temporary directory the package is assembled in,
+	// set as HOVER_STAGING_DIR.
+	StagingDir string
+	// Format is the packaging format, e.g. linux-deb, set as HOVER_FORMAT.
+	Format string
+	// Artifact is the path of the packaged file, set as HOVER_ARTIFACT.
+	Artifact string
+}
+
+func (e Env) environ(name string) []string {
+	env := append(os.Environ(), "HOVER_HOOK="+name)
+	vars := []struct{ name, value string }{
+		{"HOVER_TARGET_OS", e.TargetOS},
+		{"HOVER_ARCH", e.Arch},
+		{"HOVER_VERSION", e.Version},
+		{"HOVER_OUTPUT_DIR", e.OutputDir},
+		{"HOVER_STAGING_DIR", e.StagingDir},
+		{"HOVER_FORMAT", e.Format},
+		{"HOVER_ARTIFACT", e.Artifact},
+	}
+	for _, v := range vars {
+		if v.value != "" {
+			env = append(env, v.name+"="+v.value)
+		}
+	}
+	return env
+}
+
+// Run runs the commands of a hook one after the other, in the project
+// directory. The commands are run by the shell (cmd on windows). A command
+// exiting with a non-zero status stops the hook and fails the build.
+func Run(ctx context.Context, name string, commands []string, env Env) error {
+	for _, command := range commands {
+		log.Infof("Running %s hook: %s", name, command)
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+		} else {
+			cmd = exec.CommandContext(ctx, "sh", "-c", command)
+		}
+		cmd.Env = env.environ(name)
+		cmd.Stdout = log.CommandOutput()
+		cmd.Stderr = os.Stderr
+		err := cmd.Run()
+		if err != nil {
+			return errors.Wrapf(err, "%s hook `%s` failed", name, command)
+		}
+	}
+	return nil
+}
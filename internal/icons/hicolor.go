@@ -0,0 +1,39 @@
+package icons
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// hicolorSizes are the sizes of the icons of the hicolor theme.
+var hicolorSizes = []int{16, 22, 24, 32, 48, 64, 128, 256, 512}
+
+// WriteHicolor writes the icon in the directories of the sizes of the
+// freedesktop hicolor icon theme, e.g. dir/48x48/apps/name.png. Desktop
+// entries then refer to the icon by its name.
+func (i *Icon) WriteHicolor(dir, name string) error {
+	for _, size := range hicolorSizes {
+		appsDirectory := filepath.Join(dir, fmt.Sprintf("%dx%d", size, size), "apps")
+		err := os.MkdirAll(appsDirectory, 0755)
+		if err != nil {
+			return errors.Wrap(err, "failed to create the icon directory")
+		}
+		file, err := os.Create(filepath.Join(appsDirectory, name+".png"))
+		if err != nil {
+			return errors.Wrap(err, "failed to create the icon")
+		}
+		err = i.WritePNG(file, size)
+		if err != nil {
+			file.Close()
+			return errors.Wrapf(err, "failed to encode the %dx%d icon", size, size)
+		}
+		err = file.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package icons
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// icnsTypes are the types of the PNG images of .icns files, by size. The
+// images of the retina displays (@2x) have their own types.
+var icnsTypes = []struct {
+	osType string
+	size   int
+}{
+	{"icp4", 16},
+	{"icp5", 32},
+	{"ic11", 32},
+	{"ic12", 64},
+	{"ic07", 128},
+	{"ic08", 256},
+	{"ic13", 256},
+	{"ic09", 512},
+	{"ic14", 512},
+	{"ic10", 1024},
+}
+
+// WriteIcns writes the icon as a .icns file, the icon of macOS app bundles,
+// with images from 16x16 to 1024x1024 pixels.
+func (i *Icon) WriteIcns(w io.Writer) error {
+	images := make(map[int][]byte)
+	var entries bytes.Buffer
+	for _, t := range icnsTypes {
+		data, ok := images[t.size]
+		if !ok {
+			var buf bytes.Buffer
+			err := i.WritePNG(&buf, t.size)
+			if err != nil {
+				return errors.Wrapf(err, "failed to encode the %dx%d icon", t.size, t.size)
+			}
+			data = buf.Bytes()
+			images[t.size] = data
+		}
+		entries.WriteString(t.osType)
+		binary.Write(&entries, binary.BigEndian, uint32(8+len(data)))
+		entries.Write(data)
+	}
+
+	var header bytes.Buffer
+	header.WriteString("icns")
+	binary.Write(&header, binary.BigEndian, uint32(8+entries.Len()))
+	_, err := w.Write(header.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(entries.Bytes())
+	return err
+}
@@ -0,0 +1,42 @@
+package icons
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// icoSizes are the sizes of the images of .ico files, Windows picks the one
+// matching the size it displays the icon at.
+var icoSizes = []int{16, 20, 24, 32, 40, 48, 64, 128, 256}
+
+// WriteIco writes the icon as a .ico file, with PNG images from 16x16 to
+// 256x256 pixels.
+func (i *Icon) WriteIco(w io.Writer) error {
+	var entries, images bytes.Buffer
+	offset := 6 + 16*len(icoSizes)
+	for _, size := range icoSizes {
+		var data bytes.Buffer
+		err := i.WritePNG(&data, size)
+		if err != nil {
+			return errors.Wrapf(err, "failed to encode the %dx%d icon", size, size)
+		}
+		// the width and the height of 256 pixels are written as 0
+		entries.Write([]byte{byte(size), byte(size), 0, 0})
+		binary.Write(&entries, binary.LittleEndian, []uint16{1, 32})
+		binary.Write(&entries, binary.LittleEndian, []uint32{uint32(data.Len()), uint32(offset + images.Len())})
+		images.Write(data.Bytes())
+	}
+
+	var header bytes.Buffer
+	binary.Write(&header, binary.LittleEndian, []uint16{0, 1, uint16(len(icoSizes))})
+	for _, data := range [][]byte{header.Bytes(), entries.Bytes(), images.Bytes()} {
+		_, err := w.Write(data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
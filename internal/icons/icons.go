@@ -0,0 +1,163 @@
+// Package icons generates the icons of the packages of an app, in the sizes
+// and formats of each platform, from the icon of its assets directory.
+package icons
+
+import (
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/nfnt/resize"
+	"github.com/pkg/errors"
+)
+
+// Icon is the icon of an app, from the icon.svg or the icon.png of its assets
+// directory. The icon-<size>.png images of the directory override the icon at
+// their size.
+type Icon struct {
+	source    image.Image
+	svg       *svgDocument
+	overrides map[int]image.Image
+}
+
+var overrideRegexp = regexp.MustCompile(`^icon-([0-9]+)\.png$`)
+
+// Load reads the icon of an assets directory. The icon.svg is preferred to
+// the icon.png.
+func Load(assetsDirectory string) (*Icon, error) {
+	icon := &Icon{overrides: make(map[int]image.Image)}
+	svgData, err := ioutil.ReadFile(filepath.Join(assetsDirectory, "icon.svg"))
+	switch {
+	case err == nil:
+		icon.svg, err = parseSVG(svgData)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse icon.svg")
+		}
+	case os.IsNotExist(err):
+		icon.source, err = decodePNG(filepath.Join(assetsDirectory, "icon.png"))
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Wrap(err, "failed to read icon.svg")
+	}
+
+	files, err := ioutil.ReadDir(assetsDirectory)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the icons")
+	}
+	for _, file := range files {
+		match := overrideRegexp.FindStringSubmatch(file.Name())
+		if match == nil {
+			continue
+		}
+		size, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid size of %s", file.Name())
+		}
+		img, err := decodePNG(filepath.Join(assetsDirectory, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if img.Bounds().Dx() != size || img.Bounds().Dy() != size {
+			return nil, errors.Errorf("%s must be %dx%d pixels, not %dx%d", file.Name(), size, size, img.Bounds().Dx(), img.Bounds().Dy())
+		}
+		icon.overrides[size] = img
+	}
+	return icon, nil
+}
+
+func decodePNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open %s", filepath.Base(path))
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode %s", filepath.Base(path))
+	}
+	return img, nil
+}
+
+// Image returns the icon at size x size pixels: its override, the icon.svg
+// rendered at that size, or the icon.png resized. Icons that aren't square
+// are centered on a transparent background.
+func (i *Icon) Image(size int) (image.Image, error) {
+	if img, ok := i.overrides[size]; ok {
+		return img, nil
+	}
+	if i.svg != nil {
+		img, err := i.svg.render(size)
+		return img, errors.Wrap(err, "failed to render icon.svg")
+	}
+	bounds := i.source.Bounds()
+	if bounds.Dx() == size && bounds.Dy() == size {
+		return i.source, nil
+	}
+	width, height := size, size
+	if bounds.Dx() > bounds.Dy() {
+		height = bounds.Dy() * size / bounds.Dx()
+	} else if bounds.Dy() > bounds.Dx() {
+		width = bounds.Dx() * size / bounds.Dy()
+	}
+	resized := resize.Resize(uint(width), uint(height), i.source, resize.Lanczos3)
+	if width == size && height == size {
+		return resized, nil
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	offset := image.Pt((size-width)/2, (size-height)/2)
+	draw.Draw(img, resized.Bounds().Add(offset), resized, resized.Bounds().Min, draw.Src)
+	return img, nil
+}
+
+// WritePNG writes the icon resized to size x size pixels to w as a PNG.
+func (i *Icon) WritePNG(w io.Writer, size int) error {
+	img, err := i.Image(size)
+	if err != nil {
+		return err
+	}
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	return encoder.Encode(w, img)
+}
+
+// windowIconSizes are the sizes of the images of the icon of the window,
+// the app picks the one matching the size it is displayed at.
+var windowIconSizes = []int{16, 24, 32, 48, 64, 128, 256}
+
+// WriteWindowIcons writes the images the app loads as the icon of its window
+// to the assets directory of a build: the icon-<size>.png images of the
+// sizes that aren't overridden, and the icon.png when the icon is an SVG.
+func (i *Icon) WriteWindowIcons(assetsDirectory string) error {
+	images := map[string]int{"icon.png": 512}
+	if i.svg == nil {
+		images = make(map[string]int)
+	}
+	for _, size := range windowIconSizes {
+		if _, ok := i.overrides[size]; !ok {
+			images["icon-"+strconv.Itoa(size)+".png"] = size
+		}
+	}
+	for name, size := range images {
+		file, err := os.Create(filepath.Join(assetsDirectory, name))
+		if err != nil {
+			return errors.Wrapf(err, "failed to create %s", name)
+		}
+		err = i.WritePNG(file, size)
+		if err != nil {
+			file.Close()
+			return errors.Wrapf(err, "failed to encode %s", name)
+		}
+		err = file.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
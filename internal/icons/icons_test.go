@@ -0,0 +1,104 @@
+package icons
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testIcon(t *testing.T) *Icon {
+	dir, err := ioutil.TempDir("", "hover-icons")
+	require.Equal(t, err, nil, "failed to create temporary directory: %v", err)
+	defer os.RemoveAll(dir)
+	img := image.NewNRGBA(image.Rect(0, 0, 300, 200))
+	for x := 0; x < 300; x++ {
+		for y := 0; y < 200; y++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 255, A: 255})
+		}
+	}
+	file, err := os.Create(filepath.Join(dir, "icon.png"))
+	require.Equal(t, err, nil, "failed to create the icon: %v", err)
+	err = png.Encode(file, img)
+	require.Equal(t, err, nil, "failed to encode the icon: %v", err)
+	err = file.Close()
+	require.Equal(t, err, nil, "failed to close the icon: %v", err)
+	icon, err := Load(dir)
+	require.Equal(t, err, nil, "failed to load the icon: %v", err)
+	return icon
+}
+
+// pngSize returns the size of a PNG image.
+func pngSize(t *testing.T, data []byte) int {
+	config, err := png.DecodeConfig(bytes.NewReader(data))
+	require.Equal(t, err, nil, "failed to decode the image: %v", err)
+	require.Equal(t, config.Width, config.Height, "the image must be square")
+	return config.Width
+}
+
+func TestImage(t *testing.T) {
+	icon := testIcon(t)
+	img, err := icon.Image(30)
+	require.Equal(t, err, nil, "failed to resize the icon: %v", err)
+	require.Equal(t, image.Rect(0, 0, 30, 30), img.Bounds())
+	// the icon is centered, the rows above and below it are transparent
+	_, _, _, alpha := img.At(15, 0).RGBA()
+	require.Equal(t, uint32(0), alpha)
+	_, _, _, alpha = img.At(15, 15).RGBA()
+	require.Equal(t, uint32(0xffff), alpha)
+}
+
+func TestWriteIcns(t *testing.T) {
+	var buf bytes.Buffer
+	err := testIcon(t).WriteIcns(&buf)
+	require.Equal(t, err, nil, "failed to write the icns: %v", err)
+	data := buf.Bytes()
+	require.Equal(t, "icns", string(data[:4]))
+	require.Equal(t, uint32(len(data)), binary.BigEndian.Uint32(data[4:]))
+
+	sizes := make(map[string]int)
+	for entries := data[8:]; len(entries) > 0; {
+		length := binary.BigEndian.Uint32(entries[4:])
+		sizes[string(entries[:4])] = pngSize(t, entries[8:length])
+		entries = entries[length:]
+	}
+	require.Equal(t, map[string]int{
+		"icp4": 16, "icp5": 32, "ic11": 32, "ic12": 64, "ic07": 128,
+		"ic08": 256, "ic13": 256, "ic09": 512, "ic14": 512, "ic10": 1024,
+	}, sizes)
+}
+
+func TestWriteIco(t *testing.T) {
+	var buf bytes.Buffer
+	err := testIcon(t).WriteIco(&buf)
+	require.Equal(t, err, nil, "failed to write the ico: %v", err)
+	data := buf.Bytes()
+	require.Equal(t, []uint16{0, 1, uint16(len(icoSizes))}, []uint16{
+		binary.LittleEndian.Uint16(data), binary.LittleEndian.Uint16(data[2:]), binary.LittleEndian.Uint16(data[4:]),
+	})
+	for i, size := range icoSizes {
+		entry := data[6+16*i:]
+		require.Equal(t, byte(size), entry[0])
+		length := binary.LittleEndian.Uint32(entry[8:])
+		offset := binary.LittleEndian.Uint32(entry[12:])
+		require.Equal(t, size, pngSize(t, data[offset:offset+length]))
+	}
+}
+
+func TestWriteHicolor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hover-icons")
+	require.Equal(t, err, nil, "failed to create temporary directory: %v", err)
+	defer os.RemoveAll(dir)
+	err = testIcon(t).WriteHicolor(dir, "app")
+	require.Equal(t, err, nil, "failed to write the hicolor icons: %v", err)
+	data, err := ioutil.ReadFile(filepath.Join(dir, "48x48", "apps", "app.png"))
+	require.Equal(t, err, nil, "failed to read the icon: %v", err)
+	require.Equal(t, 48, pngSize(t, data))
+}
@@ -0,0 +1,489 @@
+package icons
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+)
+
+type point struct {
+	x, y float64
+}
+
+// matrix is the affine transformation mapping (x, y) to
+// (a*x + c*y + e, b*x + d*y + f), the matrix(a b c d e f) of SVG.
+type matrix struct {
+	a, b, c, d, e, f float64
+}
+
+var identity = matrix{a: 1, d: 1}
+
+// mul returns the transformation applying n, then m.
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		a: m.a*n.a + m.c*n.b,
+		b: m.b*n.a + m.d*n.b,
+		c: m.a*n.c + m.c*n.d,
+		d: m.b*n.c + m.d*n.d,
+		e: m.a*n.e + m.c*n.f + m.e,
+		f: m.b*n.e + m.d*n.f + m.f,
+	}
+}
+
+func (m matrix) apply(p point) point {
+	return point{x: m.a*p.x + m.c*p.y + m.e, y: m.b*p.x + m.d*p.y + m.f}
+}
+
+func (m matrix) invert() matrix {
+	det := m.a*m.d - m.b*m.c
+	if det == 0 {
+		return identity
+	}
+	return matrix{
+		a: m.d / det,
+		b: -m.b / det,
+		c: -m.c / det,
+		d: m.a / det,
+		e: (m.c*m.f - m.d*m.e) / det,
+		f: (m.b*m.e - m.a*m.f) / det,
+	}
+}
+
+// scale returns the factor by which the transformation scales lengths, for
+// the widths of the strokes.
+func (m matrix) scale() float64 {
+	return math.Sqrt(math.Abs(m.a*m.d - m.b*m.c))
+}
+
+// Kinds of the segments of paths. Quadratic curves and arcs are converted to
+// cubic curves.
+const (
+	segmentMove = iota
+	segmentLine
+	segmentCubic
+	segmentClose
+)
+
+type segment struct {
+	kind int
+	// points are the control points and the end point of cubic curves, or
+	// the end point of the other segments at index 0.
+	points [3]point
+}
+
+type path []segment
+
+func (p path) transform(m matrix) path {
+	transformed := make(path, len(p))
+	for i, s := range p {
+		transformed[i] = s
+		for j := range s.points {
+			transformed[i].points[j] = m.apply(s.points[j])
+		}
+	}
+	return transformed
+}
+
+// finite reports whether the coordinates of the path are finite numbers, the
+// huge coordinates of a path may overflow once transformed.
+func (p path) finite() bool {
+	for _, s := range p {
+		for _, q := range s.points {
+			if math.IsNaN(q.x) || math.IsInf(q.x, 0) || math.IsNaN(q.y) || math.IsInf(q.y, 0) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// bounds returns the bounding box of the points of the path, for the
+// gradients relative to the bounding box of the shape.
+func (p path) bounds() (min, max point) {
+	min = point{math.Inf(1), math.Inf(1)}
+	max = point{math.Inf(-1), math.Inf(-1)}
+	for _, s := range p {
+		count := 1
+		if s.kind == segmentCubic {
+			count = 3
+		} else if s.kind == segmentClose {
+			count = 0
+		}
+		for _, pt := range s.points[:count] {
+			min = point{math.Min(min.x, pt.x), math.Min(min.y, pt.y)}
+			max = point{math.Max(max.x, pt.x), math.Max(max.y, pt.y)}
+		}
+	}
+	return min, max
+}
+
+type polyline struct {
+	points []point
+	closed bool
+}
+
+// flatten approximates the curves of the path with lines, with an error of
+// a fraction of a pixel.
+func (p path) flatten() []polyline {
+	var polylines []polyline
+	var current polyline
+	var start point
+	end := func() {
+		if len(current.points) > 0 {
+			polylines = append(polylines, current)
+		}
+		current = polyline{}
+	}
+	last := func() point {
+		if len(current.points) == 0 {
+			return start
+		}
+		return current.points[len(current.points)-1]
+	}
+	for _, s := range p {
+		switch s.kind {
+		case segmentMove:
+			end()
+			start = s.points[0]
+			current.points = []point{start}
+		case segmentLine:
+			if len(current.points) == 0 {
+				current.points = []point{start}
+			}
+			current.points = append(current.points, s.points[0])
+		case segmentCubic:
+			p0 := last()
+			if len(current.points) == 0 {
+				current.points = []point{start}
+			}
+			p1, p2, p3 := s.points[0], s.points[1], s.points[2]
+			length := distance(p0, p1) + distance(p1, p2) + distance(p2, p3)
+			steps := int(math.Ceil(math.Sqrt(length))) + 1
+			if steps > 200 {
+				steps = 200
+			}
+			for i := 1; i <= steps; i++ {
+				t := float64(i) / float64(steps)
+				u := 1 - t
+				current.points = append(current.points, point{
+					x: u*u*u*p0.x + 3*u*u*t*p1.x + 3*u*t*t*p2.x + t*t*t*p3.x,
+					y: u*u*u*p0.y + 3*u*u*t*p1.y + 3*u*t*t*p2.y + t*t*t*p3.y,
+				})
+			}
+		case segmentClose:
+			if len(current.points) > 0 {
+				current.closed = true
+				end()
+				// drawing after a close starts at the start of the subpath
+				current.points = []point{start}
+			}
+		}
+	}
+	end()
+	return polylines
+}
+
+func distance(p, q point) float64 {
+	return math.Hypot(q.x-p.x, q.y-p.y)
+}
+
+// subsamples is the number of scanlines per row of pixels, the vertical
+// resolution of the antialiasing.
+const subsamples = 16
+
+type edge struct {
+	x0, y0, x1, y1 float64
+	direction      int
+}
+
+// coverage returns the fraction of each pixel of a size x size image covered
+// by polygons, filled with the nonzero or the evenodd rule.
+func coverage(polygons [][]point, evenOdd bool, size int) []float32 {
+	var edges []edge
+	for _, polygon := range polygons {
+		for i := range polygon {
+			p, q := polygon[i], polygon[(i+1)%len(polygon)]
+			switch {
+			case p.y < q.y:
+				edges = append(edges, edge{p.x, p.y, q.x, q.y, 1})
+			case p.y > q.y:
+				edges = append(edges, edge{q.x, q.y, p.x, p.y, -1})
+			}
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].y0 < edges[j].y0 })
+
+	type crossing struct {
+		x         float64
+		direction int
+	}
+	cov := make([]float32, size*size)
+	row := make([]float32, size)
+	var active []edge
+	var crossings []crossing
+	next := 0
+	for y := 0; y < size; y++ {
+		// the active edges are the ones crossing the row
+		remaining := active[:0]
+		for _, e := range active {
+			if e.y1 > float64(y) {
+				remaining = append(remaining, e)
+			}
+		}
+		active = remaining
+		for next < len(edges) && edges[next].y0 < float64(y+1) {
+			if edges[next].y1 > float64(y) {
+				active = append(active, edges[next])
+			}
+			next++
+		}
+		if len(active) == 0 {
+			continue
+		}
+
+		for i := range row {
+			row[i] = 0
+		}
+		for k := 0; k < subsamples; k++ {
+			scanY := float64(y) + (float64(k)+0.5)/subsamples
+			crossings = crossings[:0]
+			for _, e := range active {
+				if e.y0 <= scanY && scanY < e.y1 {
+					crossings = append(crossings, crossing{
+						x:         e.x0 + (scanY-e.y0)*(e.x1-e.x0)/(e.y1-e.y0),
+						direction: e.direction,
+					})
+				}
+			}
+			sort.Slice(crossings, func(i, j int) bool { return crossings[i].x < crossings[j].x })
+			winding := 0
+			for i, c := range crossings {
+				winding += c.direction
+				inside := winding != 0
+				if evenOdd {
+					inside = winding%2 != 0
+				}
+				if inside && i+1 < len(crossings) {
+					addSpan(row, c.x, crossings[i+1].x, 1.0/subsamples)
+				}
+			}
+		}
+		for x, value := range row {
+			if value > 1 {
+				value = 1
+			}
+			cov[y*size+x] = value
+		}
+	}
+	return cov
+}
+
+// addSpan adds the coverage of the span from x0 to x1 of a scanline to the
+// pixels of a row. The span is clamped to the row, spans with NaN bounds
+// are ignored.
+func addSpan(row []float32, x0, x1 float64, weight float32) {
+	if math.IsNaN(x0) || math.IsNaN(x1) {
+		return
+	}
+	x0 = math.Max(x0, 0)
+	x1 = math.Min(x1, float64(len(row)))
+	if x1 <= x0 {
+		return
+	}
+	i0, i1 := int(x0), int(x1)
+	if i0 == i1 {
+		row[i0] += float32(x1-x0) * weight
+		return
+	}
+	row[i0] += float32(float64(i0+1)-x0) * weight
+	for i := i0 + 1; i < i1; i++ {
+		row[i] += weight
+	}
+	if i1 < len(row) {
+		row[i1] += float32(x1-float64(i1)) * weight
+	}
+}
+
+// strokePolygons returns the polygons covering the stroke of polylines. The
+// polygons have the same orientation, so that their union is filled with the
+// nonzero rule.
+func strokePolygons(polylines []polyline, width float64, lineCap, lineJoin string, miterLimit float64) [][]point {
+	halfWidth := width / 2
+	var polygons [][]point
+	add := func(polygon ...point) {
+		area := 0.0
+		for i := range polygon {
+			p, q := polygon[i], polygon[(i+1)%len(polygon)]
+			area += p.x*q.y - q.x*p.y
+		}
+		if area < 0 {
+			for i, j := 0, len(polygon)-1; i < j; i, j = i+1, j-1 {
+				polygon[i], polygon[j] = polygon[j], polygon[i]
+			}
+		}
+		polygons = append(polygons, polygon)
+	}
+	for _, line := range polylines {
+		// consecutive duplicate points have no direction
+		points := []point{line.points[0]}
+		for _, p := range line.points[1:] {
+			if distance(p, points[len(points)-1]) > 1e-9 {
+				points = append(points, p)
+			}
+		}
+		if line.closed && len(points) > 1 && distance(points[0], points[len(points)-1]) < 1e-9 {
+			points = points[:len(points)-1]
+		}
+		if len(points) < 2 {
+			if lineCap == "round" {
+				add(circlePolygon(points[0], halfWidth)...)
+			}
+			continue
+		}
+		segmentCount := len(points) - 1
+		if line.closed {
+			segmentCount = len(points)
+		}
+		normal := func(i int) (point, point) {
+			p, q := points[i], points[(i+1)%len(points)]
+			length := distance(p, q)
+			direction := point{(q.x - p.x) / length, (q.y - p.y) / length}
+			return direction, point{-direction.y * halfWidth, direction.x * halfWidth}
+		}
+		for i := 0; i < segmentCount; i++ {
+			p, q := points[i], points[(i+1)%len(points)]
+			_, n := normal(i)
+			add(point{p.x + n.x, p.y + n.y}, point{q.x + n.x, q.y + n.y}, point{q.x - n.x, q.y - n.y}, point{p.x - n.x, p.y - n.y})
+		}
+
+		// joins, at the end of every segment followed by another one
+		for i := 0; i < segmentCount; i++ {
+			if !line.closed && i == segmentCount-1 {
+				break
+			}
+			vertex := points[(i+1)%len(points)]
+			if lineJoin == "round" {
+				add(circlePolygon(vertex, halfWidth)...)
+				continue
+			}
+			d1, n1 := normal(i)
+			d2, n2 := normal((i + 1) % len(points))
+			cross := d1.x*d2.y - d1.y*d2.x
+			if math.Abs(cross) < 1e-9 {
+				continue
+			}
+			// the outer side of the turn
+			if cross > 0 {
+				n1, n2 = point{-n1.x, -n1.y}, point{-n2.x, -n2.y}
+			}
+			p1 := point{vertex.x + n1.x, vertex.y + n1.y}
+			p2 := point{vertex.x + n2.x, vertex.y + n2.y}
+			cosine := d1.x*d2.x + d1.y*d2.y
+			// the ratio of the miter length to the stroke width is
+			// 1/sin(θ/2), θ being the angle between the segments
+			ratio := 1 / math.Sqrt((1+cosine)/2)
+			if lineJoin == "bevel" || ratio > miterLimit {
+				add(vertex, p1, p2)
+				continue
+			}
+			bisector := point{n1.x + n2.x, n1.y + n2.y}
+			bisectorLength := math.Hypot(bisector.x, bisector.y)
+			tip := point{
+				vertex.x + bisector.x/bisectorLength*halfWidth*ratio,
+				vertex.y + bisector.y/bisectorLength*halfWidth*ratio,
+			}
+			add(vertex, p1, tip, p2)
+		}
+
+		if line.closed || lineCap == "butt" || lineCap == "" {
+			continue
+		}
+		for _, end := range []struct {
+			point     point
+			direction point
+		}{
+			{points[0], func() point { d, _ := normal(0); return point{-d.x, -d.y} }()},
+			{points[len(points)-1], func() point { d, _ := normal(len(points) - 2); return d }()},
+		} {
+			if lineCap == "round" {
+				add(circlePolygon(end.point, halfWidth)...)
+				continue
+			}
+			// square caps extend the line by half of its width
+			n := point{-end.direction.y * halfWidth, end.direction.x * halfWidth}
+			outer := point{end.point.x + end.direction.x*halfWidth, end.point.y + end.direction.y*halfWidth}
+			add(
+				point{end.point.x + n.x, end.point.y + n.y},
+				point{outer.x + n.x, outer.y + n.y},
+				point{outer.x - n.x, outer.y - n.y},
+				point{end.point.x - n.x, end.point.y - n.y},
+			)
+		}
+	}
+	return polygons
+}
+
+func circlePolygon(center point, radius float64) []point {
+	steps := int(math.Ceil(2 * math.Pi * radius / 2))
+	if steps < 8 {
+		steps = 8
+	} else if steps > 64 {
+		steps = 64
+	}
+	polygon := make([]point, steps)
+	for i := range polygon {
+		angle := 2 * math.Pi * float64(i) / float64(steps)
+		polygon[i] = point{center.x + radius*math.Cos(angle), center.y + radius*math.Sin(angle)}
+	}
+	return polygon
+}
+
+// canvas is an image with premultiplied colors, the shapes are composited
+// over it.
+type canvas struct {
+	size   int
+	pixels []float64
+}
+
+func newCanvas(size int) *canvas {
+	return &canvas{size: size, pixels: make([]float64, 4*size*size)}
+}
+
+// fill composites a paint over the pixels, weighted by their coverage.
+func (c *canvas) fill(cov []float32, p paint, opacity float64) {
+	for i, value := range cov {
+		if value == 0 {
+			continue
+		}
+		x, y := i%c.size, i/c.size
+		rgba := p.colorAt(point{float64(x) + 0.5, float64(y) + 0.5})
+		alpha := rgba[3] * opacity * float64(value)
+		pixel := c.pixels[4*i : 4*i+4]
+		for j := 0; j < 3; j++ {
+			pixel[j] = rgba[j]*alpha + pixel[j]*(1-alpha)
+		}
+		pixel[3] = alpha + pixel[3]*(1-alpha)
+	}
+}
+
+func (c *canvas) image() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, c.size, c.size))
+	for i := 0; i < c.size*c.size; i++ {
+		pixel := c.pixels[4*i : 4*i+4]
+		alpha := pixel[3]
+		if alpha <= 0 {
+			continue
+		}
+		channel := func(value float64) uint8 {
+			return uint8(math.Min(math.Max(value, 0), 1)*255 + 0.5)
+		}
+		img.SetNRGBA(i%c.size, i/c.size, color.NRGBA{
+			R: channel(pixel[0] / alpha),
+			G: channel(pixel[1] / alpha),
+			B: channel(pixel[2] / alpha),
+			A: channel(alpha),
+		})
+	}
+	return img
+}
@@ -0,0 +1,604 @@
+package icons
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// svgDocument is an SVG image, rendered at the sizes of the icons. The
+// shapes, paths, solid colors, linear and radial gradients, strokes,
+// transformations and the class selectors of style sheets are supported.
+type svgDocument struct {
+	root *svgNode
+	ids  map[string]*svgNode
+	// viewBox is the x, y, width and height of the area of the document
+	// scaled to the icons.
+	viewBox [4]float64
+}
+
+type svgNode struct {
+	name  string
+	attrs map[string]string
+	// properties are the presentation attributes overridden by the rules of
+	// the style sheets and by the style attribute.
+	properties map[string]string
+	children   []*svgNode
+	text       string
+}
+
+// parseSVG parses an SVG document.
+func parseSVG(data []byte) (*svgDocument, error) {
+	doc := &svgDocument{ids: make(map[string]*svgNode)}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var stack []*svgNode
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse the SVG")
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &svgNode{name: t.Name.Local, attrs: make(map[string]string)}
+			for _, attr := range t.Attr {
+				node.attrs[attr.Name.Local] = attr.Value
+			}
+			if id := node.attrs["id"]; id != "" {
+				doc.ids[id] = node
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			} else if doc.root == nil {
+				doc.root = node
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+	if doc.root == nil || doc.root.name != "svg" {
+		return nil, errors.New("the file isn't an SVG image")
+	}
+
+	viewBox, err := parseNumbers(doc.root.attrs["viewBox"])
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid viewBox")
+	}
+	switch {
+	case len(viewBox) == 4:
+		copy(doc.viewBox[:], viewBox)
+	case len(viewBox) == 0 && doc.root.attrs["width"] != "" && doc.root.attrs["height"] != "" &&
+		!strings.HasSuffix(doc.root.attrs["width"], "%") && !strings.HasSuffix(doc.root.attrs["height"], "%"):
+		doc.viewBox[2], err = parseLength(doc.root.attrs["width"], 0)
+		if err != nil {
+			return nil, err
+		}
+		doc.viewBox[3], err = parseLength(doc.root.attrs["height"], 0)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("the SVG must have a viewBox, or a width and a height")
+	}
+	if doc.viewBox[2] <= 0 || doc.viewBox[3] <= 0 {
+		return nil, errors.New("the SVG has an empty viewBox")
+	}
+
+	classRules := make(map[string]map[string]string)
+	collectStyleSheets(doc.root, classRules)
+	setProperties(doc.root, classRules)
+	return doc, nil
+}
+
+// collectStyleSheets collects the declarations of the class selectors of the
+// style elements.
+func collectStyleSheets(node *svgNode, classRules map[string]map[string]string) {
+	if node.name == "style" {
+		css := node.text
+		for strings.Contains(css, "/*") {
+			start := strings.Index(css, "/*")
+			end := strings.Index(css[start:], "*/")
+			if end < 0 {
+				css = css[:start]
+				break
+			}
+			css = css[:start] + css[start+end+2:]
+		}
+		for _, rule := range strings.Split(css, "}") {
+			parts := strings.SplitN(rule, "{", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			for _, selector := range strings.Split(parts[0], ",") {
+				selector = strings.TrimSpace(selector)
+				if !strings.HasPrefix(selector, ".") || strings.ContainsAny(selector[1:], " .#:>[") {
+					continue
+				}
+				if classRules[selector[1:]] == nil {
+					classRules[selector[1:]] = make(map[string]string)
+				}
+				for key, value := range parseDeclarations(parts[1]) {
+					classRules[selector[1:]][key] = value
+				}
+			}
+		}
+	}
+	for _, child := range node.children {
+		collectStyleSheets(child, classRules)
+	}
+}
+
+// parseDeclarations parses CSS declarations, e.g. "fill:#fff;stroke:none".
+func parseDeclarations(s string) map[string]string {
+	declarations := make(map[string]string)
+	for _, declaration := range strings.Split(s, ";") {
+		parts := strings.SplitN(declaration, ":", 2)
+		if len(parts) == 2 {
+			value := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(parts[1]), "!important"))
+			declarations[strings.TrimSpace(parts[0])] = value
+		}
+	}
+	return declarations
+}
+
+func setProperties(node *svgNode, classRules map[string]map[string]string) {
+	node.properties = make(map[string]string)
+	for key, value := range node.attrs {
+		node.properties[key] = value
+	}
+	for _, class := range strings.Fields(node.attrs["class"]) {
+		for key, value := range classRules[class] {
+			node.properties[key] = value
+		}
+	}
+	for key, value := range parseDeclarations(node.attrs["style"]) {
+		node.properties[key] = value
+	}
+	for _, child := range node.children {
+		setProperties(child, classRules)
+	}
+}
+
+// svgStyle is the computed style of an element.
+type svgStyle struct {
+	fill, stroke                        string
+	color                               string
+	fillOpacity, strokeOpacity, opacity float64
+	strokeWidth                         float64
+	fillRule, lineCap, lineJoin         string
+	miterLimit                          float64
+	hidden                              bool
+}
+
+var defaultStyle = svgStyle{
+	fill:          "black",
+	stroke:        "none",
+	color:         "black",
+	fillOpacity:   1,
+	strokeOpacity: 1,
+	opacity:       1,
+	strokeWidth:   1,
+	fillRule:      "nonzero",
+	lineCap:       "butt",
+	lineJoin:      "miter",
+	miterLimit:    4,
+}
+
+// style returns the style of an element, its properties applied to the
+// style inherited from its parent.
+func (doc *svgDocument) style(node *svgNode, parent svgStyle) (svgStyle, error) {
+	s := parent
+	number := func(key, value string) (float64, error) {
+		if strings.HasSuffix(value, "%") {
+			v, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+			return v / 100, errors.Wrapf(err, "invalid %s", key)
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		return v, errors.Wrapf(err, "invalid %s", key)
+	}
+	for key, value := range node.properties {
+		value = strings.TrimSpace(value)
+		if value == "inherit" {
+			continue
+		}
+		var err error
+		switch key {
+		case "fill":
+			s.fill = value
+		case "stroke":
+			s.stroke = value
+		case "color":
+			s.color = value
+		case "fill-opacity":
+			s.fillOpacity, err = number(key, value)
+		case "stroke-opacity":
+			s.strokeOpacity, err = number(key, value)
+		case "opacity":
+			var opacity float64
+			opacity, err = number(key, value)
+			s.opacity = parent.opacity * opacity
+		case "stroke-width":
+			s.strokeWidth, err = parseLength(value, math.Hypot(doc.viewBox[2], doc.viewBox[3])/math.Sqrt2)
+		case "fill-rule":
+			s.fillRule = value
+		case "stroke-linecap":
+			s.lineCap = value
+		case "stroke-linejoin":
+			s.lineJoin = value
+		case "stroke-miterlimit":
+			s.miterLimit, err = number(key, value)
+		case "visibility":
+			s.hidden = value == "hidden" || value == "collapse"
+		case "clip-path", "mask", "filter":
+			if value != "none" {
+				err = errors.Errorf("the %s property isn't supported", key)
+			}
+		case "stroke-dasharray":
+			if value != "none" {
+				err = errors.New("dashed strokes aren't supported")
+			}
+		}
+		if err != nil {
+			return s, err
+		}
+	}
+	return s, nil
+}
+
+// render renders the document as a size x size image, the document is
+// centered when it isn't square.
+func (doc *svgDocument) render(size int) (image.Image, error) {
+	scale := math.Min(float64(size)/doc.viewBox[2], float64(size)/doc.viewBox[3])
+	ctm := matrix{
+		a: scale,
+		d: scale,
+		e: (float64(size)-doc.viewBox[2]*scale)/2 - doc.viewBox[0]*scale,
+		f: (float64(size)-doc.viewBox[3]*scale)/2 - doc.viewBox[1]*scale,
+	}
+	c := newCanvas(size)
+	err := doc.draw(c, doc.root, defaultStyle, ctm)
+	if err != nil {
+		return nil, err
+	}
+	return c.image(), nil
+}
+
+// draw draws an element and its children.
+func (doc *svgDocument) draw(c *canvas, node *svgNode, parent svgStyle, ctm matrix) error {
+	if node.properties["display"] == "none" {
+		return nil
+	}
+	switch node.name {
+	case "svg", "g", "a", "switch", "path", "rect", "circle", "ellipse", "line", "polyline", "polygon":
+	case "text", "image", "use", "foreignObject":
+		return errors.Errorf("<%s> elements aren't supported", node.name)
+	default:
+		// definitions, metadata and the elements of editors aren't drawn
+		return nil
+	}
+	style, err := doc.style(node, parent)
+	if err != nil {
+		return errors.Wrapf(err, "invalid style of <%s>", node.name)
+	}
+	transform, err := parseTransform(node.attrs["transform"])
+	if err != nil {
+		return err
+	}
+	ctm = ctm.mul(transform)
+	switch node.name {
+	case "svg", "g", "a", "switch":
+		for _, child := range node.children {
+			err = doc.draw(c, child, style, ctm)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	shape, err := doc.shapePath(node)
+	if err != nil {
+		return err
+	}
+	if len(shape) == 0 || style.hidden {
+		return nil
+	}
+	transformed := shape.transform(ctm)
+	if !transformed.finite() {
+		return errors.Errorf("the coordinates of <%s> are out of range", node.name)
+	}
+	polylines := transformed.flatten()
+	if style.fill != "none" {
+		p, err := doc.paint(style.fill, style.color, shape, ctm)
+		if err != nil {
+			return err
+		}
+		polygons := make([][]point, len(polylines))
+		for i, line := range polylines {
+			polygons[i] = line.points
+		}
+		c.fill(coverage(polygons, style.fillRule == "evenodd", c.size), p, style.fillOpacity*style.opacity)
+	}
+	if style.stroke != "none" && style.strokeWidth > 0 {
+		p, err := doc.paint(style.stroke, style.color, shape, ctm)
+		if err != nil {
+			return err
+		}
+		polygons := strokePolygons(polylines, style.strokeWidth*ctm.scale(), style.lineCap, style.lineJoin, style.miterLimit)
+		c.fill(coverage(polygons, false, c.size), p, style.strokeOpacity*style.opacity)
+	}
+	return nil
+}
+
+// paint is the color of the pixels of a shape.
+type paint interface {
+	// colorAt returns the color, not premultiplied, at a point of the
+	// canvas.
+	colorAt(p point) [4]float64
+}
+
+type solidPaint [4]float64
+
+func (s solidPaint) colorAt(point) [4]float64 {
+	return s
+}
+
+type gradientStop struct {
+	offset float64
+	color  [4]float64
+}
+
+type gradientPaint struct {
+	radial bool
+	// toGradient maps the points of the canvas to the coordinates of the
+	// gradient.
+	toGradient     matrix
+	x1, y1, x2, y2 float64
+	cx, cy, r      float64
+	stops          []gradientStop
+}
+
+func (g *gradientPaint) colorAt(p point) [4]float64 {
+	q := g.toGradient.apply(p)
+	var t float64
+	if g.radial {
+		t = math.Hypot(q.x-g.cx, q.y-g.cy) / g.r
+	} else {
+		dx, dy := g.x2-g.x1, g.y2-g.y1
+		t = ((q.x-g.x1)*dx + (q.y-g.y1)*dy) / (dx*dx + dy*dy)
+	}
+	if t <= g.stops[0].offset {
+		return g.stops[0].color
+	}
+	for i := 1; i < len(g.stops); i++ {
+		previous, next := g.stops[i-1], g.stops[i]
+		if t > next.offset {
+			continue
+		}
+		if next.offset == previous.offset {
+			return next.color
+		}
+		weight := (t - previous.offset) / (next.offset - previous.offset)
+		var color [4]float64
+		for j := range color {
+			color[j] = previous.color[j] + weight*(next.color[j]-previous.color[j])
+		}
+		return color
+	}
+	return g.stops[len(g.stops)-1].color
+}
+
+// paint returns the paint of a fill or stroke property.
+func (doc *svgDocument) paint(value, currentColor string, shape path, ctm matrix) (paint, error) {
+	if value == "currentColor" {
+		value = currentColor
+	}
+	if !strings.HasPrefix(value, "url(") {
+		color, err := parseColor(value)
+		return solidPaint(color), err
+	}
+	end := strings.Index(value, ")")
+	if end < 0 {
+		return nil, errors.Errorf("invalid paint '%s'", value)
+	}
+	id := strings.TrimPrefix(strings.Trim(strings.TrimSpace(value[4:end]), `'"`), "#")
+	node := doc.ids[id]
+	if node == nil || (node.name != "linearGradient" && node.name != "radialGradient") {
+		return nil, errors.Errorf("unknown gradient '%s'", id)
+	}
+
+	// gradients inherit the attributes and the stops of the gradients they
+	// reference
+	chain := []*svgNode{node}
+	for len(chain) < 8 {
+		href := chain[len(chain)-1].attrs["href"]
+		referenced := doc.ids[strings.TrimPrefix(href, "#")]
+		if href == "" || referenced == nil {
+			break
+		}
+		chain = append(chain, referenced)
+	}
+	attr := func(name, fallback string) string {
+		for _, n := range chain {
+			if value, ok := n.attrs[name]; ok {
+				return value
+			}
+		}
+		return fallback
+	}
+	var stops []gradientStop
+	for _, n := range chain {
+		for _, child := range n.children {
+			if child.name != "stop" {
+				continue
+			}
+			offset, err := parseLength(child.properties["offset"], 1)
+			if err != nil {
+				return nil, errors.Wrap(err, "invalid offset of gradient stop")
+			}
+			offset = math.Min(math.Max(offset, 0), 1)
+			if len(stops) > 0 {
+				offset = math.Max(offset, stops[len(stops)-1].offset)
+			}
+			stopColor := child.properties["stop-color"]
+			if stopColor == "" {
+				stopColor = "black"
+			}
+			color, err := parseColor(stopColor)
+			if err != nil {
+				return nil, err
+			}
+			if stopOpacity := child.properties["stop-opacity"]; stopOpacity != "" {
+				opacity, err := strconv.ParseFloat(stopOpacity, 64)
+				if err != nil {
+					return nil, errors.Wrap(err, "invalid stop-opacity")
+				}
+				color[3] *= opacity
+			}
+			stops = append(stops, gradientStop{offset: offset, color: color})
+		}
+		if len(stops) > 0 {
+			break
+		}
+	}
+	if len(stops) == 0 {
+		return solidPaint{}, nil
+	}
+	if len(stops) == 1 {
+		return solidPaint(stops[0].color), nil
+	}
+
+	toUser := identity
+	width, height := doc.viewBox[2], doc.viewBox[3]
+	if attr("gradientUnits", "objectBoundingBox") != "userSpaceOnUse" {
+		min, max := shape.bounds()
+		toUser = matrix{a: max.x - min.x, d: max.y - min.y, e: min.x, f: min.y}
+		width, height = 1, 1
+	}
+	gradientTransform, err := parseTransform(attr("gradientTransform", ""))
+	if err != nil {
+		return nil, err
+	}
+	g := &gradientPaint{
+		radial:     node.name == "radialGradient",
+		toGradient: ctm.mul(toUser).mul(gradientTransform).invert(),
+		stops:      stops,
+	}
+	coordinates := []struct {
+		value     *float64
+		name      string
+		fallback  string
+		reference float64
+	}{
+		{&g.x1, "x1", "0%", width},
+		{&g.y1, "y1", "0%", height},
+		{&g.x2, "x2", "100%", width},
+		{&g.y2, "y2", "0%", height},
+		{&g.cx, "cx", "50%", width},
+		{&g.cy, "cy", "50%", height},
+		{&g.r, "r", "50%", math.Hypot(width, height) / math.Sqrt2},
+	}
+	for _, coordinate := range coordinates {
+		*coordinate.value, err = parseLength(attr(coordinate.name, coordinate.fallback), coordinate.reference)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s of gradient", coordinate.name)
+		}
+	}
+	if (g.radial && g.r <= 0) || (!g.radial && g.x1 == g.x2 && g.y1 == g.y2) {
+		return solidPaint(stops[len(stops)-1].color), nil
+	}
+	return g, nil
+}
+
+// namedColors are the most common color keywords.
+var namedColors = map[string]string{
+	"black":   "#000000",
+	"white":   "#ffffff",
+	"red":     "#ff0000",
+	"green":   "#008000",
+	"lime":    "#00ff00",
+	"blue":    "#0000ff",
+	"yellow":  "#ffff00",
+	"cyan":    "#00ffff",
+	"aqua":    "#00ffff",
+	"magenta": "#ff00ff",
+	"fuchsia": "#ff00ff",
+	"gray":    "#808080",
+	"grey":    "#808080",
+	"silver":  "#c0c0c0",
+	"maroon":  "#800000",
+	"olive":   "#808000",
+	"navy":    "#000080",
+	"purple":  "#800080",
+	"teal":    "#008080",
+	"orange":  "#ffa500",
+}
+
+// parseColor parses a color, returning its red, green, blue and alpha
+// components between 0 and 1.
+func parseColor(value string) ([4]float64, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if named, ok := namedColors[value]; ok {
+		value = named
+	}
+	switch {
+	case value == "transparent":
+		return [4]float64{}, nil
+	case strings.HasPrefix(value, "#"):
+		hex := value[1:]
+		if len(hex) == 3 || len(hex) == 4 {
+			expanded := ""
+			for _, c := range hex {
+				expanded += string(c) + string(c)
+			}
+			hex = expanded
+		}
+		if len(hex) == 6 {
+			hex += "ff"
+		}
+		rgba, err := strconv.ParseUint(hex, 16, 32)
+		if len(hex) != 8 || err != nil {
+			return [4]float64{}, errors.Errorf("invalid color '%s'", value)
+		}
+		return [4]float64{
+			float64(rgba>>24) / 255,
+			float64(rgba>>16&0xff) / 255,
+			float64(rgba>>8&0xff) / 255,
+			float64(rgba&0xff) / 255,
+		}, nil
+	case strings.HasPrefix(value, "rgb(") || strings.HasPrefix(value, "rgba("):
+		args := strings.Split(strings.TrimSuffix(value[strings.Index(value, "(")+1:], ")"), ",")
+		if len(args) != 3 && len(args) != 4 {
+			return [4]float64{}, errors.Errorf("invalid color '%s'", value)
+		}
+		color := [4]float64{0, 0, 0, 1}
+		for i, arg := range args {
+			arg = strings.TrimSpace(arg)
+			reference := 255.0
+			if i == 3 {
+				reference = 1
+			}
+			component, err := parseLength(arg, reference)
+			if err != nil {
+				return [4]float64{}, errors.Errorf("invalid color '%s'", value)
+			}
+			color[i] = math.Min(math.Max(component/reference, 0), 1)
+		}
+		return color, nil
+	}
+	return [4]float64{}, errors.Errorf("unsupported color '%s'", value)
+}
@@ -0,0 +1,136 @@
+package icons
+
+import (
+	"image"
+	"image/png"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSVG = `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 100 100">
+  <defs>
+    <style>.blue { fill: #00f }</style>
+    <linearGradient id="stops">
+      <stop offset="0" stop-color="black"/>
+      <stop offset="100%" stop-color="#fff"/>
+    </linearGradient>
+    <linearGradient id="gradient" xlink:href="#stops" x1="0" x2="100" gradientUnits="userSpaceOnUse"/>
+  </defs>
+  <rect width="50" height="25" fill="red"/>
+  <rect x="50" width="50" height="25" class="blue" style="opacity:.5"/>
+  <g transform="translate(0 25)">
+    <rect width="100" height="25" fill="url(#gradient)"/>
+  </g>
+  <path d="M0 50h100v25H0z M25 55h50v15h-50z" fill-rule="evenodd" fill="#0f0"/>
+  <path d="M0 87.5H100" stroke="rgb(255, 0, 0)" stroke-width="5"/>
+  <circle cx="50" cy="95" r="4" fill="none" stroke="black" stroke-width="2"/>
+</svg>
+`
+
+// rgba returns the color of a pixel, not premultiplied.
+func rgba(img image.Image, x, y int) [4]uint8 {
+	c := img.(*image.NRGBA).NRGBAAt(x, y)
+	return [4]uint8{c.R, c.G, c.B, c.A}
+}
+
+func TestRenderSVG(t *testing.T) {
+	doc, err := parseSVG([]byte(testSVG))
+	require.Equal(t, err, nil, "failed to parse the SVG: %v", err)
+	img, err := doc.render(100)
+	require.Equal(t, err, nil, "failed to render the SVG: %v", err)
+	require.Equal(t, image.Rect(0, 0, 100, 100), img.Bounds())
+
+	require.Equal(t, [4]uint8{255, 0, 0, 255}, rgba(img, 10, 10))
+	require.Equal(t, [4]uint8{0, 0, 255, 128}, rgba(img, 90, 10))
+	gray := rgba(img, 50, 40)
+	require.InDelta(t, 128, int(gray[0]), 2)
+	require.Equal(t, gray[0], gray[2])
+	require.Equal(t, [4]uint8{0, 255, 0, 255}, rgba(img, 10, 60))
+	require.Equal(t, uint8(0), rgba(img, 50, 60)[3], "the inner rectangle must be a hole")
+	require.Equal(t, [4]uint8{255, 0, 0, 255}, rgba(img, 50, 87))
+	require.Equal(t, uint8(0), rgba(img, 50, 80)[3])
+	require.Equal(t, uint8(0), rgba(img, 50, 94)[3], "the circle must not be filled")
+	require.Equal(t, uint8(255), rgba(img, 53, 94)[3])
+
+	// the antialiased edges are partially covered
+	img, err = doc.render(64)
+	require.Equal(t, err, nil, "failed to render the SVG: %v", err)
+	alpha := rgba(img, 30, 35)[3]
+	require.True(t, alpha > 0 && alpha < 255, "the edge must be antialiased, alpha is %d", alpha)
+}
+
+func TestRenderSVGErrors(t *testing.T) {
+	for _, svg := range []string{
+		`<svg viewBox="0 0 10 10"><text>A</text></svg>`,
+		`<svg viewBox="0 0 10 10"><path d="M0 0 X"/></svg>`,
+		`<svg viewBox="0 0 10 10"><rect width="5" height="5" fill="url(#missing)"/></svg>`,
+		`<svg viewBox="0 0 10 10"><rect width="5" height="5" clip-path="url(#clip)"/></svg>`,
+	} {
+		doc, err := parseSVG([]byte(svg))
+		require.Equal(t, err, nil, "failed to parse the SVG: %v", err)
+		_, err = doc.render(16)
+		require.NotEqual(t, err, nil, "rendering %s must fail", svg)
+	}
+	_, err := parseSVG([]byte(`<svg><rect width="5" height="5"/></svg>`))
+	require.NotEqual(t, err, nil, "SVGs without a size must fail")
+}
+
+func TestRenderSVGOverflow(t *testing.T) {
+	doc, err := parseSVG([]byte(`<svg viewBox="0 0 10 10"><path d="M0 0 L 1e308 1e308 L 0 10" fill="red"/></svg>`))
+	require.Equal(t, err, nil, "failed to parse the SVG: %v", err)
+	// the coordinates are finite once scaled to 16 pixels, not to 1024
+	_, err = doc.render(16)
+	require.Equal(t, err, nil, "failed to render the SVG: %v", err)
+	_, err = doc.render(1024)
+	require.NotEqual(t, err, nil, "rendering overflowing coordinates must fail")
+}
+
+func TestAddSpan(t *testing.T) {
+	row := make([]float32, 4)
+	addSpan(row, math.NaN(), 2, 1)
+	addSpan(row, math.Inf(-1), math.NaN(), 1)
+	require.Equal(t, []float32{0, 0, 0, 0}, row, "NaN spans must be ignored")
+	addSpan(row, math.Inf(-1), 1.5, 1)
+	addSpan(row, 3.5, math.Inf(1), 1)
+	require.Equal(t, []float32{1, 0.5, 0, 0.5}, row, "the spans must be clamped to the row")
+}
+
+func TestLoadOverrides(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hover-icons")
+	require.Equal(t, err, nil, "failed to create temporary directory: %v", err)
+	defer os.RemoveAll(dir)
+	err = ioutil.WriteFile(filepath.Join(dir, "icon.svg"), []byte(testSVG), 0644)
+	require.Equal(t, err, nil, "failed to write the icon: %v", err)
+	override := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	file, err := os.Create(filepath.Join(dir, "icon-16.png"))
+	require.Equal(t, err, nil, "failed to create the icon: %v", err)
+	err = png.Encode(file, override)
+	require.Equal(t, err, nil, "failed to encode the icon: %v", err)
+	err = file.Close()
+	require.Equal(t, err, nil, "failed to close the icon: %v", err)
+
+	icon, err := Load(dir)
+	require.Equal(t, err, nil, "failed to load the icon: %v", err)
+	img, err := icon.Image(16)
+	require.Equal(t, err, nil, "failed to get the icon: %v", err)
+	require.Equal(t, uint8(0), rgba(img, 1, 1)[3], "the override must be used")
+	img, err = icon.Image(32)
+	require.Equal(t, err, nil, "failed to get the icon: %v", err)
+	require.Equal(t, [4]uint8{255, 0, 0, 255}, rgba(img, 1, 1), "the SVG must be rendered")
+
+	err = icon.WriteWindowIcons(dir)
+	require.Equal(t, err, nil, "failed to write the window icons: %v", err)
+	for _, name := range []string{"icon.png", "icon-24.png", "icon-256.png"} {
+		_, err = os.Stat(filepath.Join(dir, name))
+		require.Equal(t, err, nil, "%s must be written: %v", name, err)
+	}
+	img, err = decodePNG(filepath.Join(dir, "icon-16.png"))
+	require.Equal(t, err, nil, "failed to read the override: %v", err)
+	require.Equal(t, uint8(0), rgba(img, 1, 1)[3], "the override must be kept")
+}
@@ -0,0 +1,496 @@
+package icons
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// numberScanner reads the numbers of path data, lists of points and
+// transformations, separated by spaces and commas, e.g. "M10-5.5.5z".
+type numberScanner struct {
+	s string
+	i int
+}
+
+func (sc *numberScanner) skipSeparators() {
+	for sc.i < len(sc.s) && strings.IndexByte(" \t\r\n,", sc.s[sc.i]) >= 0 {
+		sc.i++
+	}
+}
+
+// more returns whether a number follows.
+func (sc *numberScanner) more() bool {
+	sc.skipSeparators()
+	return sc.i < len(sc.s) && strings.IndexByte("+-.0123456789", sc.s[sc.i]) >= 0
+}
+
+func (sc *numberScanner) number() (float64, error) {
+	sc.skipSeparators()
+	start := sc.i
+	if sc.i < len(sc.s) && (sc.s[sc.i] == '+' || sc.s[sc.i] == '-') {
+		sc.i++
+	}
+	digits, dot := 0, false
+	for ; sc.i < len(sc.s); sc.i++ {
+		c := sc.s[sc.i]
+		if c >= '0' && c <= '9' {
+			digits++
+		} else if c == '.' && !dot {
+			dot = true
+		} else {
+			break
+		}
+	}
+	if digits == 0 {
+		return 0, errors.Errorf("expected a number at '%s'", sc.s[start:])
+	}
+	if sc.i < len(sc.s) && (sc.s[sc.i] == 'e' || sc.s[sc.i] == 'E') {
+		j := sc.i + 1
+		if j < len(sc.s) && (sc.s[j] == '+' || sc.s[j] == '-') {
+			j++
+		}
+		if j < len(sc.s) && sc.s[j] >= '0' && sc.s[j] <= '9' {
+			for sc.i = j; sc.i < len(sc.s) && sc.s[sc.i] >= '0' && sc.s[sc.i] <= '9'; sc.i++ {
+			}
+		}
+	}
+	return strconv.ParseFloat(sc.s[start:sc.i], 64)
+}
+
+// flag reads the flags of arcs, which may be written without separators.
+func (sc *numberScanner) flag() (bool, error) {
+	sc.skipSeparators()
+	if sc.i < len(sc.s) && (sc.s[sc.i] == '0' || sc.s[sc.i] == '1') {
+		sc.i++
+		return sc.s[sc.i-1] == '1', nil
+	}
+	return false, errors.Errorf("expected a flag at '%s'", sc.s[sc.i:])
+}
+
+func (sc *numberScanner) numbers(count int) ([]float64, error) {
+	values := make([]float64, count)
+	for i := range values {
+		var err error
+		values[i], err = sc.number()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// parseNumbers parses a list of numbers.
+func parseNumbers(s string) ([]float64, error) {
+	sc := &numberScanner{s: s}
+	var values []float64
+	for sc.more() {
+		value, err := sc.number()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	sc.skipSeparators()
+	if sc.i < len(sc.s) {
+		return nil, errors.Errorf("expected a number at '%s'", sc.s[sc.i:])
+	}
+	return values, nil
+}
+
+// pathBuilder builds paths, keeping track of the current point.
+type pathBuilder struct {
+	path    path
+	current point
+	start   point
+}
+
+func (b *pathBuilder) moveTo(p point) {
+	b.path = append(b.path, segment{kind: segmentMove, points: [3]point{p}})
+	b.current, b.start = p, p
+}
+
+func (b *pathBuilder) lineTo(p point) {
+	b.path = append(b.path, segment{kind: segmentLine, points: [3]point{p}})
+	b.current = p
+}
+
+func (b *pathBuilder) cubicTo(c1, c2, p point) {
+	b.path = append(b.path, segment{kind: segmentCubic, points: [3]point{c1, c2, p}})
+	b.current = p
+}
+
+func (b *pathBuilder) quadTo(c, p point) {
+	p0 := b.current
+	b.cubicTo(
+		point{p0.x + 2.0/3*(c.x-p0.x), p0.y + 2.0/3*(c.y-p0.y)},
+		point{p.x + 2.0/3*(c.x-p.x), p.y + 2.0/3*(c.y-p.y)},
+		p,
+	)
+}
+
+func (b *pathBuilder) close() {
+	b.path = append(b.path, segment{kind: segmentClose})
+	b.current = b.start
+}
+
+// arcTo adds an elliptical arc, as cubic curves of at most 90°, following the
+// implementation notes of the SVG specification.
+func (b *pathBuilder) arcTo(rx, ry, rotation float64, largeArc, sweep bool, p point) {
+	p0 := b.current
+	if p0 == p {
+		return
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		b.lineTo(p)
+		return
+	}
+	phi := rotation * math.Pi / 180
+	cos, sin := math.Cos(phi), math.Sin(phi)
+	dx, dy := (p0.x-p.x)/2, (p0.y-p.y)/2
+	x1, y1 := cos*dx+sin*dy, -sin*dx+cos*dy
+	// radii too small to join the points are scaled up
+	if lambda := x1*x1/(rx*rx) + y1*y1/(ry*ry); lambda > 1 {
+		rx, ry = rx*math.Sqrt(lambda), ry*math.Sqrt(lambda)
+	}
+	numerator := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	denominator := rx*rx*y1*y1 + ry*ry*x1*x1
+	coefficient := math.Sqrt(math.Max(0, numerator/denominator))
+	if largeArc == sweep {
+		coefficient = -coefficient
+	}
+	cx1, cy1 := coefficient*rx*y1/ry, -coefficient*ry*x1/rx
+	cx := cos*cx1 - sin*cy1 + (p0.x+p.x)/2
+	cy := sin*cx1 + cos*cy1 + (p0.y+p.y)/2
+	angle := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	theta := angle(1, 0, (x1-cx1)/rx, (y1-cy1)/ry)
+	delta := angle((x1-cx1)/rx, (y1-cy1)/ry, (-x1-cx1)/rx, (-y1-cy1)/ry)
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	ellipse := func(t float64) (point, point) {
+		return point{cx + rx*math.Cos(t)*cos - ry*math.Sin(t)*sin, cy + rx*math.Cos(t)*sin + ry*math.Sin(t)*cos},
+			point{-rx*math.Sin(t)*cos - ry*math.Cos(t)*sin, -rx*math.Sin(t)*sin + ry*math.Cos(t)*cos}
+	}
+	steps := int(math.Ceil(math.Abs(delta) / (math.Pi / 2)))
+	step := delta / float64(steps)
+	k := 4.0 / 3 * math.Tan(step/4)
+	for i := 0; i < steps; i++ {
+		start, startTangent := ellipse(theta + float64(i)*step)
+		end, endTangent := ellipse(theta + float64(i+1)*step)
+		if i == steps-1 {
+			end = p
+		}
+		b.cubicTo(
+			point{start.x + k*startTangent.x, start.y + k*startTangent.y},
+			point{end.x - k*endTangent.x, end.y - k*endTangent.y},
+			end,
+		)
+	}
+}
+
+// parsePathData parses the d attribute of path elements.
+func parsePathData(d string) (path, error) {
+	sc := &numberScanner{s: d}
+	b := &pathBuilder{}
+	// lastControl is the last control point of the previous curve, for the
+	// smooth curves
+	var lastControl point
+	var lastCommand byte
+	for {
+		sc.skipSeparators()
+		if sc.i == len(sc.s) {
+			break
+		}
+		command := sc.s[sc.i]
+		if !strings.ContainsRune("MmLlHhVvCcSsQqTtAaZz", rune(command)) {
+			return nil, errors.Errorf("unknown path command at '%s'", sc.s[sc.i:])
+		}
+		sc.i++
+		if len(b.path) == 0 && command != 'M' && command != 'm' {
+			return nil, errors.New("path data must start with a moveto command")
+		}
+		for first := true; first || (command != 'Z' && command != 'z' && sc.more()); first = false {
+			relative := command >= 'a'
+			offset := func(x, y float64) point {
+				if relative {
+					return point{b.current.x + x, b.current.y + y}
+				}
+				return point{x, y}
+			}
+			var args []float64
+			var err error
+			switch command {
+			case 'Z', 'z':
+				b.close()
+			case 'M', 'm':
+				if args, err = sc.numbers(2); err == nil {
+					b.moveTo(offset(args[0], args[1]))
+				}
+			case 'L', 'l':
+				if args, err = sc.numbers(2); err == nil {
+					b.lineTo(offset(args[0], args[1]))
+				}
+			case 'H', 'h':
+				if args, err = sc.numbers(1); err == nil {
+					p := offset(args[0], 0)
+					b.lineTo(point{p.x, b.current.y})
+				}
+			case 'V', 'v':
+				if args, err = sc.numbers(1); err == nil {
+					p := offset(0, args[0])
+					b.lineTo(point{b.current.x, p.y})
+				}
+			case 'C', 'c':
+				if args, err = sc.numbers(6); err == nil {
+					c1, c2, p := offset(args[0], args[1]), offset(args[2], args[3]), offset(args[4], args[5])
+					b.cubicTo(c1, c2, p)
+					lastControl = c2
+				}
+			case 'S', 's':
+				if args, err = sc.numbers(4); err == nil {
+					c1 := b.current
+					if strings.IndexByte("CcSs", lastCommand) >= 0 {
+						c1 = point{2*b.current.x - lastControl.x, 2*b.current.y - lastControl.y}
+					}
+					c2, p := offset(args[0], args[1]), offset(args[2], args[3])
+					b.cubicTo(c1, c2, p)
+					lastControl = c2
+				}
+			case 'Q', 'q':
+				if args, err = sc.numbers(4); err == nil {
+					c, p := offset(args[0], args[1]), offset(args[2], args[3])
+					b.quadTo(c, p)
+					lastControl = c
+				}
+			case 'T', 't':
+				if args, err = sc.numbers(2); err == nil {
+					c := b.current
+					if strings.IndexByte("QqTt", lastCommand) >= 0 {
+						c = point{2*b.current.x - lastControl.x, 2*b.current.y - lastControl.y}
+					}
+					b.quadTo(c, offset(args[0], args[1]))
+					lastControl = c
+				}
+			case 'A', 'a':
+				if args, err = sc.numbers(3); err != nil {
+					break
+				}
+				var largeArc, sweep bool
+				if largeArc, err = sc.flag(); err != nil {
+					break
+				}
+				if sweep, err = sc.flag(); err != nil {
+					break
+				}
+				var end []float64
+				if end, err = sc.numbers(2); err == nil {
+					b.arcTo(args[0], args[1], args[2], largeArc, sweep, offset(end[0], end[1]))
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+			lastCommand = command
+			// the coordinates following a moveto are linetos
+			if command == 'M' {
+				command = 'L'
+			} else if command == 'm' {
+				command = 'l'
+			}
+		}
+	}
+	return b.path, nil
+}
+
+// shapePath returns the path of a shape element.
+func (doc *svgDocument) shapePath(node *svgNode) (path, error) {
+	lengths := func(names ...string) ([]float64, error) {
+		values := make([]float64, len(names))
+		for i, name := range names {
+			reference := doc.viewBox[2]
+			if strings.Contains(name, "y") || name == "height" {
+				reference = doc.viewBox[3]
+			}
+			var err error
+			values[i], err = parseLength(node.attrs[name], reference)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid %s of <%s>", name, node.name)
+			}
+		}
+		return values, nil
+	}
+	b := &pathBuilder{}
+	switch node.name {
+	case "path":
+		return parsePathData(node.attrs["d"])
+	case "rect":
+		v, err := lengths("x", "y", "width", "height", "rx", "ry")
+		if err != nil {
+			return nil, err
+		}
+		x, y, width, height, rx, ry := v[0], v[1], v[2], v[3], v[4], v[5]
+		if width <= 0 || height <= 0 {
+			return nil, nil
+		}
+		// a missing radius is the same as the other one
+		if node.attrs["rx"] == "" {
+			rx = ry
+		} else if node.attrs["ry"] == "" {
+			ry = rx
+		}
+		rx, ry = math.Min(rx, width/2), math.Min(ry, height/2)
+		b.moveTo(point{x + rx, y})
+		b.lineTo(point{x + width - rx, y})
+		b.arcTo(rx, ry, 0, false, true, point{x + width, y + ry})
+		b.lineTo(point{x + width, y + height - ry})
+		b.arcTo(rx, ry, 0, false, true, point{x + width - rx, y + height})
+		b.lineTo(point{x + rx, y + height})
+		b.arcTo(rx, ry, 0, false, true, point{x, y + height - ry})
+		b.lineTo(point{x, y + ry})
+		b.arcTo(rx, ry, 0, false, true, point{x + rx, y})
+		b.close()
+	case "circle", "ellipse":
+		names := []string{"cx", "cy", "rx", "ry"}
+		if node.name == "circle" {
+			names = []string{"cx", "cy", "r", "r"}
+		}
+		v, err := lengths(names...)
+		if err != nil {
+			return nil, err
+		}
+		cx, cy, rx, ry := v[0], v[1], v[2], v[3]
+		if rx <= 0 || ry <= 0 {
+			return nil, nil
+		}
+		b.moveTo(point{cx + rx, cy})
+		b.arcTo(rx, ry, 0, false, true, point{cx - rx, cy})
+		b.arcTo(rx, ry, 0, false, true, point{cx + rx, cy})
+		b.close()
+	case "line":
+		v, err := lengths("x1", "y1", "x2", "y2")
+		if err != nil {
+			return nil, err
+		}
+		b.moveTo(point{v[0], v[1]})
+		b.lineTo(point{v[2], v[3]})
+	case "polyline", "polygon":
+		v, err := parseNumbers(node.attrs["points"])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid points of <%s>", node.name)
+		}
+		for i := 0; i+1 < len(v); i += 2 {
+			if i == 0 {
+				b.moveTo(point{v[i], v[i+1]})
+			} else {
+				b.lineTo(point{v[i], v[i+1]})
+			}
+		}
+		if node.name == "polygon" && len(v) >= 2 {
+			b.close()
+		}
+	}
+	return b.path, nil
+}
+
+// lengthUnits are the sizes of the units of lengths, in pixels.
+var lengthUnits = map[string]float64{
+	"":   1,
+	"px": 1,
+	"pt": 4.0 / 3,
+	"pc": 16,
+	"mm": 96 / 25.4,
+	"cm": 96 / 2.54,
+	"in": 96,
+}
+
+// parseLength parses a length. Percentages are relative to reference, and an
+// empty value is 0.
+func parseLength(value string, reference float64) (float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	if strings.HasSuffix(value, "%") {
+		number, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		return number / 100 * reference, err
+	}
+	unit := strings.TrimLeft(value, "+-.0123456789eE")
+	number, err := strconv.ParseFloat(strings.TrimSuffix(value, unit), 64)
+	if err != nil {
+		return 0, errors.Errorf("invalid length '%s'", value)
+	}
+	size, ok := lengthUnits[unit]
+	if !ok {
+		return 0, errors.Errorf("unsupported unit in '%s'", value)
+	}
+	return number * size, nil
+}
+
+var transformRegexp = regexp.MustCompile(`\s*,?\s*([a-zA-Z]+)\s*\(([^)]*)\)`)
+
+// parseTransform parses the transform attribute, a list of transformations
+// applied from the last one to the first one.
+func parseTransform(value string) (matrix, error) {
+	m := identity
+	if strings.TrimSpace(value) == "" {
+		return m, nil
+	}
+	matches := transformRegexp.FindAllStringSubmatchIndex(value, -1)
+	end := 0
+	for _, match := range matches {
+		if match[0] != end {
+			break
+		}
+		end = match[1]
+		name := value[match[2]:match[3]]
+		args, err := parseNumbers(value[match[4]:match[5]])
+		if err != nil {
+			return m, errors.Wrapf(err, "invalid transform '%s'", value)
+		}
+		arg := func(i int, fallback float64) float64 {
+			if i < len(args) {
+				return args[i]
+			}
+			return fallback
+		}
+		var t matrix
+		switch name {
+		case "matrix":
+			if len(args) != 6 {
+				return m, errors.Errorf("invalid transform '%s'", value)
+			}
+			t = matrix{args[0], args[1], args[2], args[3], args[4], args[5]}
+		case "translate":
+			t = matrix{a: 1, d: 1, e: arg(0, 0), f: arg(1, 0)}
+		case "scale":
+			t = matrix{a: arg(0, 1), d: arg(1, arg(0, 1))}
+		case "rotate":
+			angle := arg(0, 0) * math.Pi / 180
+			cx, cy := arg(1, 0), arg(2, 0)
+			t = matrix{a: 1, d: 1, e: cx, f: cy}.
+				mul(matrix{a: math.Cos(angle), b: math.Sin(angle), c: -math.Sin(angle), d: math.Cos(angle)}).
+				mul(matrix{a: 1, d: 1, e: -cx, f: -cy})
+		case "skewX":
+			t = matrix{a: 1, c: math.Tan(arg(0, 0) * math.Pi / 180), d: 1}
+		case "skewY":
+			t = matrix{a: 1, b: math.Tan(arg(0, 0) * math.Pi / 180), d: 1}
+		default:
+			return m, errors.Errorf("unknown transform '%s'", name)
+		}
+		m = m.mul(t)
+	}
+	if strings.TrimSpace(value[end:]) != "" {
+		return m, errors.Errorf("invalid transform '%s'", value)
+	}
+	return m, nil
+}
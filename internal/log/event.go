@@ -0,0 +1,111 @@
+package log
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Event is a line of the JSON output. Every event is written as a JSON object
+// on its own line (NDJSON).
+type Event struct {
+	Time time.Time `json:"time"`
+	// Type is the kind of event: log, step, artifact or result.
+	Type string `json:"type"`
+	// Level is the level of log events: debug, info, warning or error.
+	Level   string `json:"level,omitempty"`
+	Message string `json:"message,omitempty"`
+	// Step is the name of the build step of step events, e.g. go-build.
+	Step string `json:"step,omitempty"`
+	// Target is the OS or the packaging format of step and artifact events.
+	Target string `json:"target,omitempty"`
+	// Status is the status of step events: started, succeeded or failed.
+	Status string `json:"status,omitempty"`
+	// Duration is the duration in seconds of the finished step events.
+	Duration float64 `json:"duration,omitempty"`
+	Error    string  `json:"error,omitempty"`
+	// Path is the path of the artifact of artifact events.
+	Path string `json:"path,omitempty"`
+	// Result is the result of the command of result events.
+	Result interface{} `json:"result,omitempty"`
+}
+
+var (
+	jsonMu  sync.Mutex
+	jsonOut *json.Encoder
+)
+
+// JSON makes the logger write events as NDJSON to w instead of printing
+// colorized text. A nil writer switches back to text.
+func JSON(w io.Writer) {
+	jsonMu.Lock()
+	defer jsonMu.Unlock()
+	if w == nil {
+		jsonOut = nil
+		return
+	}
+	jsonOut = json.NewEncoder(w)
+	Colorize(false)
+}
+
+// JSONOutput reports whether the logger writes JSON events.
+func JSONOutput() bool {
+	jsonMu.Lock()
+	defer jsonMu.Unlock()
+	return jsonOut != nil
+}
+
+// emit writes an event when the JSON output is enabled, it returns false
+// otherwise.
+func emit(event Event) bool {
+	jsonMu.Lock()
+	defer jsonMu.Unlock()
+	if jsonOut == nil {
+		return false
+	}
+	event.Time = time.Now().UTC()
+	// a failed write of the output cannot be logged anywhere
+	_ = jsonOut.Encode(event)
+	return true
+}
+
+func emitLog(level, message string) bool {
+	return emit(Event{Type: "log", Level: level, Message: message})
+}
+
+// Step logs the start of a build step of a target. The returned function
+// logs the end of the step with its duration, err is the outcome of the step.
+func Step(name, target string) func(err error) {
+	emit(Event{Type: "step", Step: name, Target: target, Status: "started"})
+	start := time.Now()
+	return func(err error) {
+		duration := time.Since(start)
+		event := Event{
+			Type:     "step",
+			Step:     name,
+			Target:   target,
+			Status:   "succeeded",
+			Duration: duration.Seconds(),
+		}
+		if err != nil {
+			event.Status = "failed"
+			event.Error = err.Error()
+		}
+		if !emit(event) {
+			Debugf("Step %s of %s took %s", name, target, duration.Round(time.Millisecond))
+		}
+	}
+}
+
+// Artifact logs the artifact produced for a target. Artifacts are only
+// logged in the JSON output, the commands print their own summary.
+func Artifact(target, path string) {
+	emit(Event{Type: "artifact", Target: target, Path: path})
+}
+
+// Result logs the result of a command in the JSON output. The result must be
+// encodable to JSON, its schema is part of the stable output of the command.
+func Result(result interface{}) {
+	emit(Event{Type: "result", Result: result})
+}
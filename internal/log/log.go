@@ -2,7 +2,9 @@ package log
 
 import (
 	"fmt"
+	"io"
 	"log"
+	"os"
 
 	"github.com/logrusorgru/aurora"
 )
@@ -18,6 +20,9 @@ var au aurora.Aurora = aurora.NewAurora(false)
 // internal verbosity level
 var verbose bool
 
+// commandOutput is the writer of the output of the commands run by hover
+var commandOutput io.Writer = os.Stdout
+
 // Verbosity enable verbose logging
 func Verbosity(b bool) {
 	verbose = b
@@ -28,6 +33,17 @@ func Verbosity(b bool) {
 	}
 }
 
+// SetCommandOutput sets the writer of the output of the commands run by
+// hover, such as flutter and go. It defaults to stdout.
+func SetCommandOutput(w io.Writer) {
+	commandOutput = w
+}
+
+// CommandOutput returns the writer of the output of the commands run by hover
+func CommandOutput() io.Writer {
+	return commandOutput
+}
+
 // Colorize set the logger to support colors printing.
 func Colorize(b bool) {
 	au = aurora.NewAurora(b)
@@ -40,6 +56,9 @@ func Au() aurora.Aurora {
 
 // Printf print a message with formatting
 func Printf(part string, parts ...interface{}) {
+	if emitLog("info", fmt.Sprintf(part, parts...)) {
+		return
+	}
 	log.Output(2, fmt.Sprint(
 		hoverPrint(),
 		fmt.Sprintf(part, parts...),
@@ -48,6 +67,9 @@ func Printf(part string, parts ...interface{}) {
 
 // Errorf print a error with formatting (red)
 func Errorf(part string, parts ...interface{}) {
+	if emitLog("error", fmt.Sprintf(part, parts...)) {
+		return
+	}
 	log.Output(2, fmt.Sprint(
 		hoverPrint(),
 		Au().Colorize(fmt.Sprintf(part, parts...), aurora.RedFg).String(),
@@ -56,6 +78,9 @@ func Errorf(part string, parts ...interface{}) {
 
 // Warnf print a warning with formatting (yellow)
 func Warnf(part string, parts ...interface{}) {
+	if emitLog("warning", fmt.Sprintf(part, parts...)) {
+		return
+	}
 	log.Output(2, fmt.Sprint(
 		hoverPrint(),
 		Au().Colorize(fmt.Sprintf(part, parts...), aurora.YellowFg).String(),
@@ -64,6 +89,9 @@ func Warnf(part string, parts ...interface{}) {
 
 // Infof print a information with formatting (green)
 func Infof(part string, parts ...interface{}) {
+	if emitLog("info", fmt.Sprintf(part, parts...)) {
+		return
+	}
 	log.Output(2, fmt.Sprint(
 		hoverPrint(),
 		Au().Colorize(fmt.Sprintf(part, parts...), aurora.GreenFg).String(),
@@ -75,6 +103,9 @@ func Debugf(part string, parts ...interface{}) {
 	if !verbose {
 		return
 	}
+	if emitLog("debug", fmt.Sprintf(part, parts...)) {
+		return
+	}
 
 	log.Output(2, fmt.Sprint(
 		hoverPrint(),
@@ -0,0 +1,423 @@
+// Package msix writes unsigned MSIX packages without makeappx.
+package msix
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ManifestFileName is the name of the manifest of the package, at its root.
+const ManifestFileName = "AppxManifest.xml"
+
+// blockSize is the size of the blocks of the files listed in the block map,
+// the blocks are compressed independently.
+const blockSize = 64 << 10
+
+// Identity is the identity of a package, from its manifest.
+type Identity struct {
+	Name                  string `xml:"Name,attr"`
+	Publisher             string `xml:"Publisher,attr"`
+	Version               string `xml:"Version,attr"`
+	ProcessorArchitecture string `xml:"ProcessorArchitecture,attr"`
+}
+
+// ReadIdentity reads the identity of a package from its manifest and checks
+// that its version has the four numeric parts windows requires.
+func ReadIdentity(manifest []byte) (Identity, error) {
+	var m struct {
+		Identity *Identity `xml:"Identity"`
+	}
+	err := xml.Unmarshal(manifest, &m)
+	if err != nil {
+		return Identity{}, errors.Wrap(err, "failed to parse the manifest")
+	}
+	if m.Identity == nil || m.Identity.Name == "" || m.Identity.Publisher == "" {
+		return Identity{}, errors.New("the manifest must have an Identity with a Name and a Publisher")
+	}
+	parts := strings.Split(m.Identity.Version, ".")
+	if len(parts) != 4 {
+		return Identity{}, errors.Errorf("the version %q of the manifest must have four parts: major.minor.build.revision", m.Identity.Version)
+	}
+	for _, part := range parts {
+		_, err = strconv.ParseUint(part, 10, 16)
+		if err != nil {
+			return Identity{}, errors.Errorf("the version %q of the manifest must be made of numbers up to 65535", m.Identity.Version)
+		}
+	}
+	return *m.Identity, nil
+}
+
+// blockMap is the AppxBlockMap.xml of a package, windows checks the files of
+// the package against the hashes of their blocks.
+type blockMap struct {
+	XMLName    xml.Name        `xml:"http://schemas.microsoft.com/appx/2010/blockmap BlockMap"`
+	HashMethod string          `xml:"HashMethod,attr"`
+	Files      []*blockMapFile `xml:"File"`
+}
+
+type blockMapFile struct {
+	Name    string  `xml:"Name,attr"` // backslash separated
+	Size    int64   `xml:"Size,attr"`
+	LfhSize int     `xml:"LfhSize,attr"`
+	Blocks  []block `xml:"Block"`
+}
+
+type block struct {
+	Hash string `xml:"Hash,attr"`
+	// Size is the compressed size of the block, it is omitted for files
+	// which aren't compressed.
+	Size int64 `xml:"Size,attr,omitempty"`
+}
+
+// contentTypes are the content types of the extensions of the files, other
+// files are application/octet-stream.
+var contentTypes = map[string]string{
+	"dll":  "application/x-msdownload",
+	"exe":  "application/x-msdownload",
+	"png":  "image/png",
+	"jpg":  "image/jpeg",
+	"jpeg": "image/jpeg",
+	"gif":  "image/gif",
+	"ico":  "image/vnd.microsoft.icon",
+	"svg":  "image/svg+xml",
+	"json": "application/json",
+	"xml":  "application/xml",
+	"txt":  "text/plain",
+	"ttf":  "application/x-font-ttf",
+	"otf":  "application/x-font-otf",
+}
+
+// Build writes the MSIX package of the tree root, which must contain the
+// AppxManifest.xml, to w. The files are compressed by blocks and listed in
+// the AppxBlockMap.xml of the package. The modification times of the files
+// are kept, the generated files are dated like the manifest.
+func Build(root string, w io.Writer) error {
+	manifestInfo, err := os.Stat(filepath.Join(root, ManifestFileName))
+	if err != nil {
+		return errors.Wrap(err, "failed to find the manifest")
+	}
+	files, err := payloadFiles(root)
+	if err != nil {
+		return err
+	}
+	zipWriter := zip.NewWriter(w)
+	// the compressor records the compressed size of the blocks of the file
+	// being written, the last block is written when the zip writer closes
+	// the file, as the next file is created
+	var compressedSizes *[]int64
+	zipWriter.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+		return &blockCompressor{out: out, sizes: compressedSizes}, nil
+	})
+	bm := &blockMap{HashMethod: "http://www.w3.org/2001/04/xmlenc#sha256"}
+	blockHashes := make([][]string, len(files))
+	blockSizes := make([]*[]int64, len(files))
+	extensions := make(map[string]bool)
+	var overrides []string
+	for i, name := range files {
+		compressedSizes = new([]int64)
+		blockSizes[i] = compressedSizes
+		file, hashes, err := writeFile(zipWriter, root, name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to write %s", name)
+		}
+		bm.Files = append(bm.Files, file)
+		blockHashes[i] = hashes
+		if name == ManifestFileName {
+			continue
+		}
+		if ext := strings.ToLower(strings.TrimPrefix(path.Ext(name), ".")); ext != "" {
+			extensions[ext] = true
+		} else {
+			overrides = append(overrides, name)
+		}
+	}
+
+	compressedSizes = new([]int64)
+	blockMapWriter, err := zipWriter.CreateHeader(header("AppxBlockMap.xml", manifestInfo.ModTime(), zip.Deflate))
+	if err != nil {
+		return errors.Wrap(err, "failed to write AppxBlockMap.xml")
+	}
+	for i, file := range bm.Files {
+		for j, hash := range blockHashes[i] {
+			b := block{Hash: hash}
+			// stored files have no compressed size
+			if sizes := *blockSizes[i]; len(sizes) > 0 {
+				b.Size = sizes[j]
+			}
+			file.Blocks = append(file.Blocks, b)
+		}
+	}
+	_, err = io.WriteString(blockMapWriter, xml.Header)
+	if err != nil {
+		return errors.Wrap(err, "failed to write AppxBlockMap.xml")
+	}
+	err = xml.NewEncoder(blockMapWriter).Encode(bm)
+	if err != nil {
+		return errors.Wrap(err, "failed to write AppxBlockMap.xml")
+	}
+	err = writeGeneratedFile(zipWriter, "[Content_Types].xml", contentTypesFile(extensions, overrides), manifestInfo.ModTime())
+	if err != nil {
+		return err
+	}
+	return zipWriter.Close()
+}
+
+// payloadFiles lists the slash separated names of the files of the tree,
+// the manifest is last.
+func payloadFiles(root string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return errors.Errorf("%s is not a regular file", path)
+		}
+		relativePath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(relativePath)
+		switch name {
+		case ManifestFileName:
+		case "AppxBlockMap.xml", "[Content_Types].xml", "AppxSignature.p7x":
+			return errors.Errorf("%s is generated, it must not be in the package", name)
+		default:
+			files = append(files, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the files of the package")
+	}
+	return append(files, ManifestFileName), nil
+}
+
+// partName returns the name of a file in the zip archive: its percent
+// encoded name.
+func partName(name string) string {
+	return strings.TrimPrefix((&url.URL{Path: "/" + name}).EscapedPath(), "/")
+}
+
+// header returns the zip header of a file, name is its name in the archive.
+// The modification time isn't set as an extra field, the size of the local
+// file header only depends on the name of the file.
+func header(name string, modTime time.Time, method uint16) *zip.FileHeader {
+	fh := &zip.FileHeader{Name: name, Method: method}
+	fh.ModifiedDate, fh.ModifiedTime = msDosTime(modTime)
+	return fh
+}
+
+// lfhSize returns the size of the local file header of a file.
+func lfhSize(fh *zip.FileHeader) int {
+	return 30 + len(fh.Name) + len(fh.Extra)
+}
+
+func msDosTime(t time.Time) (uint16, uint16) {
+	t = t.UTC()
+	if t.Year() < 1980 {
+		t = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	date := uint16(t.Day() + int(t.Month())<<5 + (t.Year()-1980)<<9)
+	clock := uint16(t.Second()/2 + t.Minute()<<5 + t.Hour()<<11)
+	return date, clock
+}
+
+// writeFile writes a file of the tree to the archive and returns its entry
+// in the block map and the hashes of its blocks.
+func writeFile(zipWriter *zip.Writer, root, name string) (*blockMapFile, []string, error) {
+	file, err := os.Open(filepath.Join(root, filepath.FromSlash(name)))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	method := zip.Deflate
+	if info.Size() == 0 {
+		method = zip.Store
+	}
+	fh := header(partName(name), info.ModTime(), method)
+	entryWriter, err := zipWriter.CreateHeader(fh)
+	if err != nil {
+		return nil, nil, err
+	}
+	hasher := &blockHasher{}
+	_, err = io.Copy(io.MultiWriter(entryWriter, hasher), file)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &blockMapFile{
+		Name:    strings.Replace(name, "/", `\`, -1),
+		Size:    info.Size(),
+		LfhSize: lfhSize(fh),
+	}, hasher.sum(), nil
+}
+
+func writeGeneratedFile(zipWriter *zip.Writer, name string, data []byte, modTime time.Time) error {
+	entryWriter, err := zipWriter.CreateHeader(header(name, modTime, zip.Deflate))
+	if err != nil {
+		return errors.Wrapf(err, "failed to write %s", name)
+	}
+	_, err = entryWriter.Write(data)
+	return errors.Wrapf(err, "failed to write %s", name)
+}
+
+// contentTypesFile returns the [Content_Types].xml of a package, which maps
+// the extensions of the files and the files without extension to their
+// content type.
+func contentTypesFile(extensions map[string]bool, overrides []string) []byte {
+	type typeDefault struct {
+		Extension   string `xml:",attr"`
+		ContentType string `xml:",attr"`
+	}
+	type typeOverride struct {
+		PartName    string `xml:",attr"`
+		ContentType string `xml:",attr"`
+	}
+	types := struct {
+		XMLName   xml.Name       `xml:"http://schemas.openxmlformats.org/package/2006/content-types Types"`
+		Defaults  []typeDefault  `xml:"Default"`
+		Overrides []typeOverride `xml:"Override"`
+	}{}
+	var sortedExtensions []string
+	for ext := range extensions {
+		sortedExtensions = append(sortedExtensions, ext)
+	}
+	sort.Strings(sortedExtensions)
+	for _, ext := range sortedExtensions {
+		contentType, ok := contentTypes[ext]
+		if !ok {
+			contentType = "application/octet-stream"
+		}
+		types.Defaults = append(types.Defaults, typeDefault{Extension: ext, ContentType: contentType})
+	}
+	for _, name := range overrides {
+		types.Overrides = append(types.Overrides, typeOverride{PartName: "/" + partName(name), ContentType: "application/octet-stream"})
+	}
+	types.Overrides = append(types.Overrides,
+		typeOverride{PartName: "/" + ManifestFileName, ContentType: "application/vnd.ms-appx.manifest+xml"},
+		typeOverride{PartName: "/AppxBlockMap.xml", ContentType: "application/vnd.ms-appx.blockmap+xml"},
+	)
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	// the encoding of these types can't fail
+	xml.NewEncoder(&buf).Encode(types)
+	return buf.Bytes()
+}
+
+// blockHasher computes the base64 encoded sha256 hashes of the blocks of
+// the data written to it.
+type blockHasher struct {
+	block  []byte
+	hashes []string
+}
+
+func (h *blockHasher) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		chunk := blockSize - len(h.block)
+		if chunk > len(p) {
+			chunk = len(p)
+		}
+		h.block = append(h.block, p[:chunk]...)
+		p = p[chunk:]
+		if len(h.block) == blockSize {
+			h.flush()
+		}
+	}
+	return n, nil
+}
+
+func (h *blockHasher) flush() {
+	sum := sha256.Sum256(h.block)
+	h.hashes = append(h.hashes, base64.StdEncoding.EncodeToString(sum[:]))
+	h.block = h.block[:0]
+}
+
+func (h *blockHasher) sum() []string {
+	if len(h.block) > 0 {
+		h.flush()
+	}
+	return h.hashes
+}
+
+// blockCompressor compresses the blocks of a file independently: every
+// block is compressed by a new deflate compressor, flushed at the end of
+// the block, and the last one ends the deflate stream. It records the
+// compressed size of the blocks in sizes.
+type blockCompressor struct {
+	out   io.Writer
+	block []byte
+	sizes *[]int64
+}
+
+func (c *blockCompressor) Write(p []byte) (int, error) {
+	c.block = append(c.block, p...)
+	// the last block is kept for Close, which ends the stream
+	for len(c.block) > blockSize {
+		err := c.compress(c.block[:blockSize], false)
+		if err != nil {
+			return 0, err
+		}
+		c.block = append(c.block[:0], c.block[blockSize:]...)
+	}
+	return len(p), nil
+}
+
+func (c *blockCompressor) Close() error {
+	return c.compress(c.block, true)
+}
+
+func (c *blockCompressor) compress(data []byte, last bool) error {
+	counter := &countingWriter{w: c.out}
+	flateWriter, err := flate.NewWriter(counter, flate.BestCompression)
+	if err != nil {
+		return err
+	}
+	_, err = flateWriter.Write(data)
+	if err != nil {
+		return err
+	}
+	if last {
+		err = flateWriter.Close()
+	} else {
+		err = flateWriter.Flush()
+	}
+	if err != nil {
+		return err
+	}
+	*c.sizes = append(*c.sizes, counter.n)
+	return nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,121 @@
+package msix
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-flutter-desktop/hover/internal/testutil"
+)
+
+const testManifest = `<?xml version="1.0" encoding="utf-8"?>
+<Package xmlns="http://schemas.microsoft.com/appx/manifest/foundation/windows10">
+  <Identity Name="com.example.app" Publisher="CN=hover" Version="1.2.3.0" ProcessorArchitecture="x64"/>
+</Package>
+`
+
+func TestReadIdentity(t *testing.T) {
+	identity, err := ReadIdentity([]byte(testManifest))
+	require.Equal(t, err, nil, "failed to read the identity: %v", err)
+	require.Equal(t, Identity{
+		Name:                  "com.example.app",
+		Publisher:             "CN=hover",
+		Version:               "1.2.3.0",
+		ProcessorArchitecture: "x64",
+	}, identity)
+
+	for _, version := range []string{"1.2.3", "1.2.3-beta.0", "1.2.3.70000"} {
+		_, err = ReadIdentity([]byte(strings.Replace(testManifest, "1.2.3.0", version, 1)))
+		require.NotEqual(t, err, nil, "the version %s must be rejected", version)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	large := make([]byte, 3*blockSize+100)
+	random.Read(large[:blockSize])
+	files := map[string]testutil.File{
+		ManifestFileName:                       {Content: testManifest},
+		"app.exe":                              {Content: string(large)},
+		"flutter_assets/fonts/My Font.ttf":     {Content: strings.Repeat("font", blockSize/4)},
+		"flutter_assets/NOTICES":               {Content: "notices"},
+		"flutter_assets/AssetManifest.json":    {Content: "{}"},
+		"flutter_assets/assets/empty.png":      {},
+		"flutter_assets/assets/upper_case.PNG": {Content: "png"},
+	}
+	dir := testutil.Tree(t, files)
+
+	var buf bytes.Buffer
+	err := Build(dir, &buf)
+	require.Equal(t, err, nil, "failed to build the package: %v", err)
+	zipReader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.Equal(t, err, nil, "failed to read the package: %v", err)
+	members := make(map[string]*zip.File)
+	var names []string
+	for _, f := range zipReader.File {
+		names = append(names, f.Name)
+		members[f.Name] = f
+	}
+	require.Equal(t, []string{ManifestFileName, "AppxBlockMap.xml", "[Content_Types].xml"}, names[len(names)-3:])
+	require.Contains(t, names, "flutter_assets/fonts/My%20Font.ttf")
+
+	read := func(f *zip.File) []byte {
+		reader, err := f.Open()
+		require.Equal(t, err, nil, "failed to open %s: %v", f.Name, err)
+		defer reader.Close()
+		data, err := ioutil.ReadAll(reader)
+		require.Equal(t, err, nil, "failed to read %s: %v", f.Name, err)
+		return data
+	}
+	var bm blockMap
+	err = xml.Unmarshal(read(members["AppxBlockMap.xml"]), &bm)
+	require.Equal(t, err, nil, "failed to parse the block map: %v", err)
+	require.Equal(t, len(files), len(bm.Files))
+	for _, file := range bm.Files {
+		name := partName(strings.Replace(file.Name, `\`, "/", -1))
+		f := members[name]
+		require.NotNil(t, f, "%s must be in the package", file.Name)
+		data := []byte(files[strings.Replace(file.Name, `\`, "/", -1)].Content)
+		require.Equal(t, string(data), string(read(f)), "%s must be decompressed", file.Name)
+		require.Equal(t, int64(len(data)), file.Size)
+		require.Equal(t, 30+len(name), file.LfhSize)
+		require.Equal(t, (len(data)+blockSize-1)/blockSize, len(file.Blocks))
+
+		// every block is decompressed on its own
+		offset, err := f.DataOffset()
+		require.Equal(t, err, nil, "failed to find the data of %s: %v", file.Name, err)
+		for i, b := range file.Blocks {
+			blockData := data[i*blockSize:]
+			if len(blockData) > blockSize {
+				blockData = blockData[:blockSize]
+			}
+			sum := sha256.Sum256(blockData)
+			require.Equal(t, base64.StdEncoding.EncodeToString(sum[:]), b.Hash, "block %d of %s", i, file.Name)
+			require.NotEqual(t, int64(0), b.Size, "the blocks of %s must be compressed", file.Name)
+			decompressed := make([]byte, len(blockData))
+			_, err = io.ReadFull(flate.NewReader(bytes.NewReader(buf.Bytes()[offset:offset+b.Size])), decompressed)
+			require.Equal(t, err, nil, "failed to decompress block %d of %s: %v", i, file.Name, err)
+			require.Equal(t, blockData, decompressed)
+			offset += b.Size
+		}
+		if len(data) > 0 {
+			start, _ := f.DataOffset()
+			require.Equal(t, f.CompressedSize64, uint64(offset-start), "the blocks must make up the compressed data of %s", file.Name)
+		}
+	}
+
+	contentTypes := string(read(members["[Content_Types].xml"]))
+	require.Contains(t, contentTypes, `<Default Extension="png" ContentType="image/png"></Default>`)
+	require.Contains(t, contentTypes, `<Override PartName="/flutter_assets/NOTICES" ContentType="application/octet-stream"></Override>`)
+	require.Contains(t, contentTypes, `<Override PartName="/AppxManifest.xml" ContentType="application/vnd.ms-appx.manifest+xml"></Override>`)
+}
@@ -0,0 +1,47 @@
+// Package nix computes the hashes of files as written in Nix expressions.
+package nix
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+)
+
+// base32Alphabet is the alphabet of the base32 encoding of Nix, which
+// leaves out e, o, t and u.
+const base32Alphabet = "0123456789abcdfghijklmnpqrsvwxyz"
+
+// SHA256 returns the sha256 hash of the content of r.
+func SHA256(r io.Reader) ([]byte, error) {
+	h := sha256.New()
+	_, err := io.Copy(h, r)
+	if err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// Base32 encodes a hash in the base32 encoding of Nix, used by the sha256
+// attributes of fetchers. Unlike RFC 4648, the bits of the hash are read
+// from its end.
+func Base32(hash []byte) string {
+	length := (len(hash)*8-1)/5 + 1
+	out := make([]byte, length)
+	for n := length - 1; n >= 0; n-- {
+		b := uint(n * 5)
+		i := b / 8
+		j := b % 8
+		c := hash[i] >> j
+		if int(i)+1 < len(hash) {
+			c |= hash[i+1] << (8 - j)
+		}
+		out[length-1-n] = base32Alphabet[c&0x1f]
+	}
+	return string(out)
+}
+
+// SRI returns the Subresource Integrity form of a sha256 hash, used by the
+// hash attributes of fetchers: sha256-<base64>.
+func SRI(hash []byte) string {
+	return "sha256-" + base64.StdEncoding.EncodeToString(hash)
+}
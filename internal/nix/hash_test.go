@@ -0,0 +1,15 @@
+package nix
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHash(t *testing.T) {
+	hash, err := SHA256(strings.NewReader(""))
+	require.Equal(t, err, nil, "failed to hash: %v", err)
+	require.Equal(t, "0mdqa9w1p6cmli6976v4wi0sw9r4p5prkj7lzfd1877wk11c9c73", Base32(hash))
+	require.Equal(t, "sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=", SRI(hash))
+}
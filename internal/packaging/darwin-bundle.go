@@ -1,10 +1,12 @@
 package packaging
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+
+	"github.com/go-flutter-desktop/hover/internal/icons"
 )
 
 // DarwinBundleTask packaging for darwin as bundle
@@ -15,23 +17,25 @@ var DarwinBundleTask = &packagingTask{
 	},
 	executableFiles:             []string{},
 	flutterBuildOutputDirectory: "{{.applicationName}} {{.version}}.app/Contents/MacOS",
-	packagingFunction: func(tmpPath, applicationName, packageName, executableName, version, release string) (string, error) {
+	packagingFunction: func(ctx context.Context, tmpPath, applicationName, packageName, executableName, version, release, arch string) (string, error) {
 		outputFileName := fmt.Sprintf("%s %s.app", applicationName, version)
 		err := os.MkdirAll(filepath.Join(tmpPath, outputFileName, "Contents", "Resources"), 0755)
 		if err != nil {
 			return "", err
 		}
-		cmdPng2icns := exec.Command("png2icns", filepath.Join(outputFileName, "Contents", "Resources", "icon.icns"), filepath.Join(outputFileName, "Contents", "MacOS", "assets", "icon.png"))
-		cmdPng2icns.Dir = tmpPath
-		cmdPng2icns.Stdout = os.Stdout
-		cmdPng2icns.Stderr = os.Stderr
-		err = cmdPng2icns.Run()
+		icon, err := icons.Load(filepath.Join(tmpPath, outputFileName, "Contents", "MacOS", "assets"))
+		if err != nil {
+			return "", err
+		}
+		err = writeFile(filepath.Join(tmpPath, outputFileName, "Contents", "Resources", "icon.icns"), icon.WriteIcns)
 		if err != nil {
 			return "", err
 		}
 		return outputFileName, nil
 	},
 	requiredTools: map[string][]string{
-		"linux": {"png2icns"},
+		"linux":   {},
+		"darwin":  {},
+		"windows": {},
 	},
 }
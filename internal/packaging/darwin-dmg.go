@@ -1,9 +1,12 @@
 package packaging
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
+
+	"github.com/go-flutter-desktop/hover/internal/log"
 )
 
 // DarwinDmgTask packaging for darwin as dmg
@@ -12,19 +15,19 @@ var DarwinDmgTask = &packagingTask{
 	dependsOn: map[*packagingTask]string{
 		DarwinBundleTask: "dmgdir",
 	},
-	packagingFunction: func(tmpPath, applicationName, packageName, executableName, version, release string) (string, error) {
+	packagingFunction: func(ctx context.Context, tmpPath, applicationName, packageName, executableName, version, release, arch string) (string, error) {
 		outputFileName := fmt.Sprintf("%s %s.dmg", applicationName, version)
-		cmdLn := exec.Command("ln", "-sf", "/Applications", "dmgdir/Applications")
+		cmdLn := exec.CommandContext(ctx, "ln", "-sf", "/Applications", "dmgdir/Applications")
 		cmdLn.Dir = tmpPath
-		cmdLn.Stdout = os.Stdout
+		cmdLn.Stdout = log.CommandOutput()
 		cmdLn.Stderr = os.Stderr
 		err := cmdLn.Run()
 		if err != nil {
 			return "", err
 		}
-		cmdGenisoimage := exec.Command("genisoimage", "-V", packageName, "-D", "-R", "-apple", "-no-pad", "-o", outputFileName, "dmgdir")
+		cmdGenisoimage := exec.CommandContext(ctx, "genisoimage", "-V", packageName, "-D", "-R", "-apple", "-no-pad", "-o", outputFileName, "dmgdir")
 		cmdGenisoimage.Dir = tmpPath
-		cmdGenisoimage.Stdout = os.Stdout
+		cmdGenisoimage.Stdout = log.CommandOutput()
 		cmdGenisoimage.Stderr = os.Stderr
 		err = cmdGenisoimage.Run()
 		if err != nil {
@@ -0,0 +1,73 @@
+package packaging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-flutter-desktop/hover/internal/darwinpkg"
+)
+
+// DarwinPkgTask packaging for darwin as pkg
+var DarwinPkgTask = &packagingTask{
+	packagingFormatName: "darwin-pkg",
+	dependsOn: map[*packagingTask]string{
+		DarwinBundleTask: "flat/root/Applications",
+	},
+	templateFiles: map[string]string{
+		"darwin-pkg/PackageInfo.tmpl":  "flat/base.pkg/PackageInfo.tmpl",
+		"darwin-pkg/Distribution.tmpl": "flat/Distribution.tmpl",
+	},
+	packagingFunction: func(ctx context.Context, tmpPath, applicationName, packageName, executableName, version, release, arch string) (string, error) {
+		outputFileName := fmt.Sprintf("%s %s.pkg", applicationName, version)
+		flatPath := filepath.Join(tmpPath, "flat")
+		rootPath := filepath.Join(flatPath, "root")
+		executables := []string{fmt.Sprintf("Applications/%s %s.app/Contents/MacOS/%s", applicationName, version, executableName)}
+
+		err := writeFile(filepath.Join(flatPath, "base.pkg", "Payload"), func(w io.Writer) error {
+			return darwinpkg.WritePayload(rootPath, w, executables)
+		})
+		if err != nil {
+			return "", err
+		}
+		err = writeFile(filepath.Join(flatPath, "base.pkg", "Bom"), func(w io.Writer) error {
+			return darwinpkg.WriteBom(rootPath, w, executables)
+		})
+		if err != nil {
+			return "", err
+		}
+
+		creationTime := time.Now()
+		if reproducible() {
+			creationTime = sourceDateEpoch
+			// the payload and the bill of materials are created after the
+			// staged tree is normalized
+			for _, name := range []string{"Payload", "Bom"} {
+				path := filepath.Join(flatPath, "base.pkg", name)
+				err = os.Chmod(path, 0644)
+				if err != nil {
+					return "", err
+				}
+				err = os.Chtimes(path, sourceDateEpoch, sourceDateEpoch)
+				if err != nil {
+					return "", err
+				}
+			}
+		}
+		err = writeFile(filepath.Join(tmpPath, outputFileName), func(w io.Writer) error {
+			return darwinpkg.WriteXar(flatPath, w, creationTime)
+		})
+		if err != nil {
+			return "", err
+		}
+		return outputFileName, nil
+	},
+	requiredTools: map[string][]string{
+		"linux":   {},
+		"darwin":  {},
+		"windows": {},
+	},
+}
@@ -0,0 +1,37 @@
+package packaging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/go-flutter-desktop/hover/internal/archive"
+)
+
+// DarwinZipTask packaging for darwin as zip
+var DarwinZipTask = &packagingTask{
+	packagingFormatName: "darwin-zip",
+	dependsOn: map[*packagingTask]string{
+		DarwinBundleTask: "zipdir",
+	},
+	templateFiles: map[string]string{
+		"darwin-zip/README.txt.tmpl": "zipdir/README.txt.tmpl",
+	},
+	packagingFunction: func(ctx context.Context, tmpPath, applicationName, packageName, executableName, version, release, arch string) (string, error) {
+		outputFileName := fmt.Sprintf("%s-%s-darwin-%s.zip", packageName, version, arch)
+		executables := []string{fmt.Sprintf("%s %s.app/Contents/MacOS/%s", applicationName, version, executableName)}
+		err := writeFile(filepath.Join(tmpPath, outputFileName), func(w io.Writer) error {
+			return archive.WriteZip(filepath.Join(tmpPath, "zipdir"), fmt.Sprintf("%s-%s", packageName, version), w, executables)
+		})
+		if err != nil {
+			return "", err
+		}
+		return outputFileName, nil
+	},
+	requiredTools: map[string][]string{
+		"linux":   {},
+		"darwin":  {},
+		"windows": {},
+	},
+}
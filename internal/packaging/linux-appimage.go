@@ -0,0 +1,60 @@
+package packaging
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	copy "github.com/otiai10/copy"
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/hover/internal/log"
+)
+
+// LinuxAppImageTask packaging for linux as AppImage
+var LinuxAppImageTask = &packagingTask{
+	packagingFormatName: "linux-appimage",
+	templateFiles: map[string]string{
+		"linux-appimage/AppRun.tmpl": "AppRun.tmpl",
+		"linux/app.desktop.tmpl":     "{{.packageName}}.desktop.tmpl",
+	},
+	executableFiles: []string{
+		".",
+		"AppRun",
+		"{{.packageName}}.desktop",
+	},
+	linuxDesktopFileIconPath:    "{{.packageName}}",
+	linuxIconsDirectory:         "usr/share/icons/hicolor",
+	flutterBuildOutputDirectory: "build",
+	packagingFunction: func(ctx context.Context, tmpPath, applicationName, packageName, executableName, version, release, arch string) (string, error) {
+		// appimagetool takes the icon of the AppImage from the root of the AppDir
+		err := copy.Copy(filepath.Join(tmpPath, "usr", "share", "icons", "hicolor", "256x256", "apps", packageName+".png"), filepath.Join(tmpPath, packageName+".png"))
+		if err != nil {
+			return "", errors.Wrap(err, "failed to copy icon root dir")
+		}
+		cmdAppImageTool := exec.CommandContext(ctx, "appimagetool", ".")
+		cmdAppImageTool.Dir = tmpPath
+		cmdAppImageTool.Stdout = log.CommandOutput()
+		cmdAppImageTool.Stderr = os.Stderr
+		cmdAppImageTool.Env = append(
+			os.Environ(),
+			"ARCH="+arch,
+			fmt.Sprintf("VERSION=%s", version),
+		)
+		err = cmdAppImageTool.Run()
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s-%s-%s.AppImage", strings.ReplaceAll(applicationName, " ", "_"), version, arch), nil
+	},
+	requiredTools: map[string][]string{
+		"linux": {"appimagetool"},
+	},
+	architectures: map[string]string{
+		"amd64": "x86_64",
+		"arm64": "aarch64",
+	},
+}
@@ -0,0 +1,63 @@
+package packaging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/go-flutter-desktop/hover/internal/deb"
+)
+
+// LinuxDebTask packaging for linux as deb
+var LinuxDebTask = &packagingTask{
+	packagingFormatName: "linux-deb",
+	templateFiles: map[string]string{
+		"linux-deb/control.tmpl": "DEBIAN/control.tmpl",
+		"linux/bin.tmpl":         "usr/bin/{{.executableName}}.tmpl",
+		"linux/app.desktop.tmpl": "usr/share/applications/{{.executableName}}.desktop.tmpl",
+	},
+	executableFiles: []string{
+		"usr/bin/{{.executableName}}",
+		"usr/share/applications/{{.executableName}}.desktop",
+	},
+	linuxDesktopFileExecutablePath: "/usr/lib/{{.packageName}}/{{.executableName}}",
+	linuxDesktopFileIconPath:       "{{.packageName}}",
+	linuxIconsDirectory:            "usr/share/icons/hicolor",
+	flutterBuildOutputDirectory:    "usr/lib/{{.packageName}}",
+	packagingFunction: func(ctx context.Context, tmpPath, applicationName, packageName, executableName, version, release, arch string) (string, error) {
+		outputFileName := fmt.Sprintf("%s_%s_%s.deb", packageName, version, arch)
+		modTime := time.Now()
+		if reproducible() {
+			modTime = sourceDateEpoch
+		}
+		// the package is written once the tree is archived, so that it
+		// doesn't end up in its own data
+		executables := []string{
+			"usr/lib/" + packageName + "/" + executableName,
+			"usr/bin/" + executableName,
+			"usr/share/applications/" + executableName + ".desktop",
+		}
+		var buf bytes.Buffer
+		err := deb.Build(tmpPath, &buf, modTime, executables)
+		if err != nil {
+			return "", err
+		}
+		err = ioutil.WriteFile(filepath.Join(tmpPath, outputFileName), buf.Bytes(), 0644)
+		if err != nil {
+			return "", err
+		}
+		return outputFileName, nil
+	},
+	requiredTools: map[string][]string{
+		"linux":   {},
+		"darwin":  {},
+		"windows": {},
+	},
+	architectures: map[string]string{
+		"amd64": "amd64",
+		"arm64": "arm64",
+	},
+}
@@ -0,0 +1,86 @@
+package packaging
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/go-flutter-desktop/hover/internal/enginecache"
+	"github.com/go-flutter-desktop/hover/internal/log"
+)
+
+// LinuxFlatpakTask packaging for linux as flatpak
+var LinuxFlatpakTask = &packagingTask{
+	packagingFormatName: "linux-flatpak",
+	templateFiles: map[string]string{
+		"linux-flatpak/manifest.yml.tmpl": "{{.packageName}}.yml.tmpl",
+		"linux/app.desktop.tmpl":          "{{.packageName}}.desktop.tmpl",
+	},
+	linuxDesktopFileExecutablePath: "{{.executableName}}",
+	linuxDesktopFileIconPath:       "{{.packageName}}",
+	linuxIconsDirectory:            "icons/hicolor",
+	flutterBuildOutputDirectory:    "build",
+	packagingFunction: func(ctx context.Context, tmpPath, applicationName, packageName, executableName, version, release, arch string) (string, error) {
+		manifestFileName := packageName + ".yml"
+		appID, err := flatpakAppID(filepath.Join(tmpPath, manifestFileName))
+		if err != nil {
+			return "", err
+		}
+		outputFileName := fmt.Sprintf("%s-%s-%s.flatpak", packageName, version, arch)
+		// the runtime and the sdk of the manifest are installed from flathub
+		// when they are missing, in a flatpak installation of hover so that
+		// the remotes of the installation of the user are left untouched
+		flatpakUserDir := filepath.Join(enginecache.DefaultCachePath(), "hover", "flatpak")
+		err = os.MkdirAll(flatpakUserDir, 0755)
+		if err != nil {
+			return "", errors.Wrap(err, "cannot create the flatpak path in the user cache directory")
+		}
+		cmdRemoteAdd := exec.CommandContext(ctx, "flatpak", "remote-add", "--user", "--if-not-exists", "flathub", "https://flathub.org/repo/flathub.flatpakrepo")
+		cmdFlatpakBuilder := exec.CommandContext(ctx, "flatpak-builder", "--user", "--install-deps-from=flathub", "--disable-rofiles-fuse", "--force-clean", "--arch="+arch, "--repo=repo", "build-dir", manifestFileName)
+		cmdBuildBundle := exec.CommandContext(ctx, "flatpak", "build-bundle", "--arch="+arch, "repo", outputFileName, appID)
+		for _, cmd := range []*exec.Cmd{cmdRemoteAdd, cmdFlatpakBuilder, cmdBuildBundle} {
+			cmd.Dir = tmpPath
+			cmd.Env = append(os.Environ(), "FLATPAK_USER_DIR="+flatpakUserDir)
+			cmd.Stdout = log.CommandOutput()
+			cmd.Stderr = os.Stderr
+			err = cmd.Run()
+			if err != nil {
+				return "", err
+			}
+		}
+		return outputFileName, nil
+	},
+	requiredTools: map[string][]string{
+		"linux": {"flatpak", "flatpak-builder"},
+	},
+	architectures: map[string]string{
+		"amd64": "x86_64",
+		"arm64": "aarch64",
+	},
+}
+
+// flatpakAppID returns the app-id of a flatpak manifest, it may be changed
+// after init.
+func flatpakAppID(manifestPath string) (string, error) {
+	data, err := ioutil.ReadFile(manifestPath)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read the flatpak manifest")
+	}
+	var manifest struct {
+		AppID string `yaml:"app-id"`
+	}
+	err = yaml.Unmarshal(data, &manifest)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse the flatpak manifest")
+	}
+	if manifest.AppID == "" {
+		return "", errors.New("the flatpak manifest has no app-id")
+	}
+	return manifest.AppID, nil
+}
@@ -0,0 +1,73 @@
+package packaging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/go-flutter-desktop/hover/internal/archive"
+	"github.com/go-flutter-desktop/hover/internal/nix"
+)
+
+// LinuxNixTask packaging for linux as nix derivation
+var LinuxNixTask = &packagingTask{
+	packagingFormatName: "linux-nix",
+	templateFiles: map[string]string{
+		"linux-nix/default.nix.tmpl": "{{.packageName}}-{{.version}}-nix/default.nix.tmpl",
+		"linux-nix/flake.nix.tmpl":   "{{.packageName}}-{{.version}}-nix/flake.nix.tmpl",
+		"linux/app.desktop.tmpl":     "{{.packageName}}-{{.version}}/share/applications/{{.packageName}}.desktop.tmpl",
+	},
+	linuxDesktopFileExecutablePath: "{{.executableName}}",
+	linuxDesktopFileIconPath:       "{{.packageName}}",
+	linuxIconsDirectory:            "{{.packageName}}-{{.version}}/share/icons/hicolor",
+	flutterBuildOutputDirectory:    "{{.packageName}}-{{.version}}/lib",
+	packagingFunction: func(ctx context.Context, tmpPath, applicationName, packageName, executableName, version, release, arch string) (string, error) {
+		directoryName := fmt.Sprintf("%s-%s", packageName, version)
+		outputDirectoryName := directoryName + "-nix"
+		tarballName := fmt.Sprintf("%s-%s-linux-%s.tar.gz", packageName, version, arch)
+		tarballPath := filepath.Join(tmpPath, outputDirectoryName, tarballName)
+		executables := []string{"lib/" + executableName}
+		err := writeFile(tarballPath, func(w io.Writer) error {
+			return archive.WriteTarGz(filepath.Join(tmpPath, directoryName), directoryName, w, executables)
+		})
+		if err != nil {
+			return "", err
+		}
+		tarball, err := os.Open(tarballPath)
+		if err != nil {
+			return "", err
+		}
+		hash, err := nix.SHA256(tarball)
+		tarball.Close()
+		if err != nil {
+			return "", err
+		}
+		// the tarball is added to the store as a flat file, the hash of
+		// the file is its hash in the store
+		src := fmt.Sprintf(`# Generated by hover: the tarball of the build output and its hash (%s).
+builtins.path {
+  path = ./%s;
+  name = "%s";
+  recursive = false;
+  sha256 = "%s";
+}
+`, nix.SRI(hash), tarballName, tarballName, nix.Base32(hash))
+		err = ioutil.WriteFile(filepath.Join(tmpPath, outputDirectoryName, "src.nix"), []byte(src), 0644)
+		if err != nil {
+			return "", err
+		}
+		return outputDirectoryName, nil
+	},
+	architectures: map[string]string{
+		"amd64": "x86_64",
+		"arm64": "aarch64",
+	},
+	requiredTools: map[string][]string{
+		"linux":   {},
+		"darwin":  {},
+		"windows": {},
+	},
+}
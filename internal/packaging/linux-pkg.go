@@ -0,0 +1,88 @@
+package packaging
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/hover/internal/pacman"
+)
+
+// LinuxPkgTask packaging for linux as pacman pkg
+var LinuxPkgTask = &packagingTask{
+	packagingFormatName: "linux-pkg",
+	templateFiles: map[string]string{
+		"linux-pkg/PKGBUILD.tmpl": "PKGBUILD.tmpl",
+		"linux/bin.tmpl":          "src/usr/bin/{{.executableName}}.tmpl",
+		"linux/app.desktop.tmpl":  "src/usr/share/applications/{{.executableName}}.desktop.tmpl",
+	},
+	executableFiles: []string{
+		"src/usr/bin/{{.executableName}}",
+		"src/usr/share/applications/{{.executableName}}.desktop",
+	},
+	linuxDesktopFileExecutablePath: "/usr/lib/{{.packageName}}/{{.executableName}}",
+	linuxDesktopFileIconPath:       "{{.packageName}}",
+	linuxIconsDirectory:            "src/usr/share/icons/hicolor",
+	flutterBuildOutputDirectory:    "src/usr/lib/{{.packageName}}",
+	packagingFunction: func(ctx context.Context, tmpPath, applicationName, packageName, executableName, version, release, arch string) (string, error) {
+		pkgbuildData, err := ioutil.ReadFile(filepath.Join(tmpPath, "PKGBUILD"))
+		if err != nil {
+			return "", errors.Wrap(err, "failed to read the PKGBUILD")
+		}
+		pkgbuild, err := pacman.ParsePKGBUILD(pkgbuildData, arch)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to parse the PKGBUILD")
+		}
+		opts := pacman.Options{
+			BuildDate: time.Now(),
+			Packager:  "Unknown Packager",
+			Executables: []string{
+				"/usr/lib/" + packageName + "/" + executableName,
+				"/usr/bin/" + executableName,
+				"/usr/share/applications/" + executableName + ".desktop",
+			},
+		}
+		opts.Arch, err = pkgbuild.PackageArch(arch)
+		if err != nil {
+			return "", err
+		}
+		if reproducible() {
+			opts.BuildDate = sourceDateEpoch
+		}
+		// makepkg reads the packager from the environment as well
+		if packager := os.Getenv("PACKAGER"); packager != "" {
+			opts.Packager = packager
+		}
+		if pkgbuild.Install != "" {
+			opts.InstallScript, err = ioutil.ReadFile(filepath.Join(tmpPath, pkgbuild.Install))
+			if err != nil {
+				return "", errors.Wrap(err, "failed to read the install script")
+			}
+		}
+
+		outputFileName := pacman.FileName(pkgbuild, opts.Arch)
+		outputFile, err := os.Create(filepath.Join(tmpPath, outputFileName))
+		if err != nil {
+			return "", err
+		}
+		defer outputFile.Close()
+		err = pacman.Build(filepath.Join(tmpPath, "src"), outputFile, pkgbuild, opts)
+		if err != nil {
+			return "", err
+		}
+		return outputFileName, outputFile.Close()
+	},
+	requiredTools: map[string][]string{
+		"linux":   {},
+		"darwin":  {},
+		"windows": {},
+	},
+	architectures: map[string]string{
+		"amd64": "x86_64",
+		"arm64": "aarch64",
+	},
+}
@@ -0,0 +1,94 @@
+package packaging
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/hover/internal/rpm"
+)
+
+// LinuxRpmTask packaging for linux as rpm
+var LinuxRpmTask = &packagingTask{
+	packagingFormatName: "linux-rpm",
+	templateFiles: map[string]string{
+		"linux-rpm/app.spec.tmpl": "SPECS/{{.packageName}}.spec.tmpl",
+		"linux/bin.tmpl":          "BUILDROOT/{{.packageName}}-{{.version}}-{{.release}}.{{.arch}}/usr/bin/{{.executableName}}.tmpl",
+		"linux/app.desktop.tmpl":  "BUILDROOT/{{.packageName}}-{{.version}}-{{.release}}.{{.arch}}/usr/share/applications/{{.executableName}}.desktop.tmpl",
+	},
+	executableFiles: []string{
+		"BUILDROOT/{{.packageName}}-{{.version}}-{{.release}}.{{.arch}}/usr/bin/{{.executableName}}",
+		"BUILDROOT/{{.packageName}}-{{.version}}-{{.release}}.{{.arch}}/usr/share/applications/{{.executableName}}.desktop",
+	},
+	linuxDesktopFileExecutablePath: "/usr/lib/{{.packageName}}/{{.executableName}}",
+	linuxDesktopFileIconPath:       "{{.packageName}}",
+	linuxIconsDirectory:            "BUILDROOT/{{.packageName}}-{{.version}}-{{.release}}.{{.arch}}/usr/share/icons/hicolor",
+	flutterBuildOutputDirectory:    "BUILDROOT/{{.packageName}}-{{.version}}-{{.release}}.{{.arch}}/usr/lib/{{.packageName}}",
+	packagingFunction: func(ctx context.Context, tmpPath, applicationName, packageName, executableName, version, release, arch string) (string, error) {
+		specPath := filepath.Join(tmpPath, "SPECS", packageName+".spec")
+		specData, err := ioutil.ReadFile(specPath)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to read the spec file")
+		}
+		spec, err := rpm.ParseSpec(specData, arch)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to parse %s", filepath.Base(specPath))
+		}
+		root := filepath.Join(tmpPath, "BUILDROOT", fmt.Sprintf("%s-%s-%s.%s", packageName, version, release, arch))
+		// the specs created before the hicolor icons were generated don't
+		// list them, the desktop entry refers to them by name
+		icons := "/usr/share/icons/hicolor/*/apps/" + packageName + ".png"
+		staged, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(icons)))
+		if err != nil {
+			return "", err
+		}
+		if len(staged) > 0 {
+			spec.Files = append(spec.Files, rpm.File{Path: icons})
+		}
+		opts := rpm.Options{
+			Arch:      arch,
+			BuildTime: time.Now(),
+			Executables: []string{
+				"/usr/lib/" + packageName + "/" + executableName,
+				"/usr/bin/" + executableName,
+				"/usr/share/applications/" + executableName + ".desktop",
+			},
+		}
+		if reproducible() {
+			opts.BuildTime = sourceDateEpoch
+			opts.BuildHost = "reproducible"
+		} else {
+			opts.BuildHost, _ = os.Hostname()
+		}
+
+		outputFilePath := fmt.Sprintf("RPMS/%s/%s-%s-%s.%s.rpm", arch, spec.Name, spec.Version, spec.Release, arch)
+		err = os.MkdirAll(filepath.Join(tmpPath, "RPMS", arch), 0755)
+		if err != nil {
+			return "", err
+		}
+		outputFile, err := os.Create(filepath.Join(tmpPath, filepath.FromSlash(outputFilePath)))
+		if err != nil {
+			return "", err
+		}
+		defer outputFile.Close()
+		err = rpm.Build(root, outputFile, spec, opts)
+		if err != nil {
+			return "", err
+		}
+		return outputFilePath, outputFile.Close()
+	},
+	requiredTools: map[string][]string{
+		"linux":   {},
+		"darwin":  {},
+		"windows": {},
+	},
+	architectures: map[string]string{
+		"amd64": "x86_64",
+		"arm64": "aarch64",
+	},
+}
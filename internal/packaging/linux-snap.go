@@ -1,9 +1,12 @@
 package packaging
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
+
+	"github.com/go-flutter-desktop/hover/internal/log"
 )
 
 // LinuxSnapTask packaging for linux as snap
@@ -16,18 +19,25 @@ var LinuxSnapTask = &packagingTask{
 	linuxDesktopFileExecutablePath: "/{{.executableName}}",
 	linuxDesktopFileIconPath:       "/icon",
 	flutterBuildOutputDirectory:    "build",
-	packagingFunction: func(tmpPath, applicationName, packageName, executableName, version, release string) (string, error) {
-		cmdSnapcraft := exec.Command("snapcraft")
+	packagingFunction: func(ctx context.Context, tmpPath, applicationName, packageName, executableName, version, release, arch string) (string, error) {
+		cmdSnapcraft := exec.CommandContext(ctx, "snapcraft")
+		if arch != "amd64" {
+			cmdSnapcraft.Args = append(cmdSnapcraft.Args, "--enable-experimental-target-arch", "--target-arch", arch)
+		}
 		cmdSnapcraft.Dir = tmpPath
-		cmdSnapcraft.Stdout = os.Stdout
+		cmdSnapcraft.Stdout = log.CommandOutput()
 		cmdSnapcraft.Stderr = os.Stderr
 		err := cmdSnapcraft.Run()
 		if err != nil {
 			return "", err
 		}
-		return fmt.Sprintf("%s_%s_amd64.snap", packageName, version), nil
+		return fmt.Sprintf("%s_%s_%s.snap", packageName, version, arch), nil
 	},
 	requiredTools: map[string][]string{
 		"linux": {"snapcraft"},
 	},
+	architectures: map[string]string{
+		"amd64": "amd64",
+		"arm64": "arm64",
+	},
 }
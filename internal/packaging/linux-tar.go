@@ -0,0 +1,43 @@
+package packaging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/go-flutter-desktop/hover/internal/archive"
+)
+
+// LinuxTarTask packaging for linux as tar.gz
+var LinuxTarTask = &packagingTask{
+	packagingFormatName: "linux-tar",
+	templateFiles: map[string]string{
+		"linux-tar/launcher.tmpl":   "{{.packageName}}-{{.version}}/{{.executableName}}.tmpl",
+		"linux-tar/README.txt.tmpl": "{{.packageName}}-{{.version}}/README.txt.tmpl",
+	},
+	executableFiles: []string{
+		"{{.packageName}}-{{.version}}/{{.executableName}}",
+	},
+	flutterBuildOutputDirectory: "{{.packageName}}-{{.version}}/lib",
+	packagingFunction: func(ctx context.Context, tmpPath, applicationName, packageName, executableName, version, release, arch string) (string, error) {
+		directoryName := fmt.Sprintf("%s-%s", packageName, version)
+		outputFileName := fmt.Sprintf("%s-%s-linux-%s.tar.gz", packageName, version, arch)
+		executables := []string{
+			executableName,
+			"lib/" + executableName,
+		}
+		err := writeFile(filepath.Join(tmpPath, outputFileName), func(w io.Writer) error {
+			return archive.WriteTarGz(filepath.Join(tmpPath, directoryName), directoryName, w, executables)
+		})
+		if err != nil {
+			return "", err
+		}
+		return outputFileName, nil
+	},
+	requiredTools: map[string][]string{
+		"linux":   {},
+		"darwin":  {},
+		"windows": {},
+	},
+}
@@ -0,0 +1,14 @@
+package packaging
+
+import "context"
+
+type noopTask struct{}
+
+var NoopTask Task = &noopTask{}
+
+func (_ *noopTask) Name() string                                         { return "" }
+func (_ *noopTask) Init() error                                          { return nil }
+func (_ *noopTask) IsInitialized() bool                                  { return true }
+func (_ *noopTask) AssertInitialized() error                             { return nil }
+func (_ *noopTask) Pack(context.Context, string, string) (string, error) { return "", nil }
+func (_ *noopTask) CheckSupported() error                                { return nil }
@@ -0,0 +1,523 @@
+package packaging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/go-flutter-desktop/hover/internal/androidmanifest"
+	"github.com/go-flutter-desktop/hover/internal/pubspec"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/otiai10/copy"
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/hover/internal/build"
+	"github.com/go-flutter-desktop/hover/internal/buildinfo"
+	"github.com/go-flutter-desktop/hover/internal/config"
+	"github.com/go-flutter-desktop/hover/internal/fileutils"
+	"github.com/go-flutter-desktop/hover/internal/hooks"
+	"github.com/go-flutter-desktop/hover/internal/icons"
+	"github.com/go-flutter-desktop/hover/internal/log"
+)
+
+var packagingPath = filepath.Join(build.BuildPath, "packaging")
+
+func packagingFormatPath(packagingFormat string) (string, error) {
+	directoryPath, err := filepath.Abs(filepath.Join(packagingPath, build.FlavorDirectoryName(packagingFormat)))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to resolve absolute path for %s directory", packagingFormat)
+	}
+	return directoryPath, nil
+}
+
+func createPackagingFormatDirectory(packagingFormat string) (string, error) {
+	directoryPath, err := packagingFormatPath(packagingFormat)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(directoryPath); !os.IsNotExist(err) {
+		return "", errors.Errorf("a file or directory named `%s` already exists, cannot continue packaging init for %s", packagingFormat, packagingFormat)
+	}
+	err = os.MkdirAll(directoryPath, 0775)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create %s directory %s", packagingFormat, directoryPath)
+	}
+	return directoryPath, nil
+}
+
+func getTemporaryBuildDirectory(projectName string, packagingFormat string) (string, error) {
+	tmpPath, err := ioutil.TempDir("", "hover-build-"+projectName+"-"+packagingFormat)
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't get temporary build directory")
+	}
+	return tmpPath, nil
+}
+
+type packagingTask struct {
+	packagingFormatName            string                                                                                                                          // Name of the packaging format: OS-TYPE
+	dependsOn                      map[*packagingTask]string                                                                                                       // Packaging tasks this task depends on
+	templateFiles                  map[string]string                                                                                                               // Template files to copy over on init
+	executableFiles                []string                                                                                                                        // Files that should be executable
+	linuxDesktopFileExecutablePath string                                                                                                                          // Path of the executable for linux .desktop file (only set on linux)
+	linuxDesktopFileIconPath       string                                                                                                                          // Path of the icon for linux .desktop file (only set on linux)
+	linuxIconsDirectory            string                                                                                                                          // Path of the hicolor icon theme directory to generate the icons of the app in (only set on linux). Operates in the temporary directory
+	generateBuildFiles             func(packageName, path string) error                                                                                            // Generate dynamic build files. Operates in the temporary directory
+	generateInitFiles              func(packageName, path string) error                                                                                            // Generate dynamic init files
+	extraTemplateData              func(packageName, path string) (map[string]string, error)                                                                       // Update the template data on build. This is used for inserting values that are generated on init
+	flutterBuildOutputDirectory    string                                                                                                                          // Path to copy the build output of the app to. Operates in the temporary directory
+	packagingFunction              func(ctx context.Context, tmpPath, applicationName, packageName, executableName, version, release, arch string) (string, error) // Function that actually packages the app. Needs to check for OS specific tools etc. . Returns the path of the packaged file
+	skipAssertInitialized          bool                                                                                                                            // Set to true when a task doesn't need to be initialized.
+	requiredTools                  map[string][]string                                                                                                             // Map of list of tools required to package per OS
+	architectures                  map[string]string                                                                                                               // Map of the architecture names used by the packaging format per GOARCH. When nil, GOARCH names are used
+	mu                             sync.Mutex                                                                                                                      // Prevents concurrent packaging of the same task, which share their output directory
+}
+
+func (t *packagingTask) CheckSupported() error {
+	for task := range t.dependsOn {
+		err := task.CheckSupported()
+		if err != nil {
+			return err
+		}
+	}
+	if _, osIsSupported := t.requiredTools[runtime.GOOS]; !osIsSupported {
+		return errors.Errorf("packaging %s is not supported on %s", t.packagingFormatName, runtime.GOOS)
+	}
+	var unavailableTools []string
+	for _, tool := range t.requiredTools[runtime.GOOS] {
+		_, err := exec.LookPath(tool)
+		if err != nil {
+			unavailableTools = append(unavailableTools, tool)
+		}
+	}
+	if len(unavailableTools) > 0 {
+		return errors.Errorf("to package %s these tools are required: %s", t.packagingFormatName, strings.Join(unavailableTools, ","))
+	}
+	return nil
+}
+
+func (t *packagingTask) Name() string {
+	return strings.SplitN(t.packagingFormatName, "-", 2)[1]
+}
+
+func (t *packagingTask) Init() error {
+	return t.init(false)
+}
+
+func (t *packagingTask) init(ignoreAlreadyExists bool) error {
+	for task := range t.dependsOn {
+		err := task.init(true)
+		if err != nil {
+			return err
+		}
+	}
+	if t.IsInitialized() {
+		if !ignoreAlreadyExists {
+			return errors.Errorf("%s is already initialized for packaging", t.packagingFormatName)
+		}
+		return nil
+	}
+	dir, err := createPackagingFormatDirectory(t.packagingFormatName)
+	if err != nil {
+		return err
+	}
+	for sourceFile, destinationFile := range t.templateFiles {
+		destinationFile = filepath.Join(dir, destinationFile)
+		err := os.MkdirAll(filepath.Dir(destinationFile), 0775)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create directory %s", filepath.Dir(destinationFile))
+		}
+		err = fileutils.CopyAsset(fmt.Sprintf("packaging/%s", sourceFile), destinationFile)
+		if err != nil {
+			return err
+		}
+	}
+	if t.generateInitFiles != nil {
+		log.Infof("Generating dynamic init files")
+		pubSpec, err := pubspec.GetPubSpec()
+		if err != nil {
+			return err
+		}
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return err
+		}
+		err = t.generateInitFiles(cfg.GetPackageName(pubSpec.Name), dir)
+		if err != nil {
+			return err
+		}
+	}
+	buildCommand := "hover build " + t.packagingFormatName
+	if flavor := build.Flavor(); flavor != "" {
+		buildCommand += " --flavor " + flavor
+	}
+	log.Infof("go/packaging/%s has been created. You can modify the configuration files and add it to git.", build.FlavorDirectoryName(t.packagingFormatName))
+	log.Infof(fmt.Sprintf("You now can package the %s using `%s`", strings.Split(t.packagingFormatName, "-")[0], log.Au().Magenta(buildCommand)))
+	return nil
+}
+
+func (t *packagingTask) Pack(ctx context.Context, fullVersion, arch string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pubSpec, err := pubspec.GetPubSpec()
+	if err != nil {
+		return "", err
+	}
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return "", err
+	}
+	projectName := pubSpec.Name
+	version := strings.Split(fullVersion, "+")[0]
+	var release string
+	if strings.Contains(fullVersion, "+") {
+		release = strings.Split(fullVersion, "+")[1]
+	} else {
+		release = strings.ReplaceAll(fullVersion, ".", "")
+	}
+	description := pubSpec.Description
+	organizationName := androidmanifest.AndroidOrganizationName()
+	author, err := pubSpec.GetAuthor()
+	if err != nil {
+		return "", err
+	}
+	applicationName := cfg.GetApplicationName(projectName)
+	executableName := cfg.GetExecutableName(projectName)
+	packageName := cfg.GetPackageName(projectName)
+	license := cfg.GetLicense()
+	packagingArch, err := t.packagingArch(arch)
+	if err != nil {
+		return "", err
+	}
+	templateData := map[string]string{
+		"projectName":      projectName,
+		"version":          version,
+		"release":          release,
+		"description":      description,
+		"organizationName": organizationName,
+		"author":           author,
+		"applicationName":  applicationName,
+		"executableName":   executableName,
+		"packageName":      packageName,
+		"license":          license,
+		"arch":             packagingArch,
+	}
+	templateData["iconPath"], err = executeStringTemplate(t.linuxDesktopFileIconPath, templateData)
+	if err != nil {
+		return "", err
+	}
+	templateData["executablePath"], err = executeStringTemplate(t.linuxDesktopFileExecutablePath, templateData)
+	if err != nil {
+		return "", err
+	}
+	return t.pack(ctx, templateData, packageName, projectName, applicationName, executableName, version, release, arch)
+}
+
+// packagingArch returns the name the packaging format uses for a GOARCH.
+func (t *packagingTask) packagingArch(arch string) (string, error) {
+	if t.architectures == nil {
+		return arch, nil
+	}
+	packagingArch, ok := t.architectures[arch]
+	if !ok {
+		return "", errors.Errorf("packaging %s is not supported for the %s architecture", t.packagingFormatName, arch)
+	}
+	return packagingArch, nil
+}
+
+// pack packages the app and returns the path of the packaged file.
+func (t *packagingTask) pack(ctx context.Context, templateData map[string]string, packageName, projectName, applicationName, executableName, version, release, arch string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	formatPath, err := packagingFormatPath(t.packagingFormatName)
+	if err != nil {
+		return "", err
+	}
+	if t.extraTemplateData != nil {
+		extraTemplateData, err := t.extraTemplateData(packageName, formatPath)
+		if err != nil {
+			return "", err
+		}
+		for key, value := range extraTemplateData {
+			templateData[key] = value
+		}
+	}
+	tmpPath, err := getTemporaryBuildDirectory(projectName, t.packagingFormatName)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		err := os.RemoveAll(tmpPath)
+		if err != nil {
+			log.Warnf("Could not remove temporary build directory: %v", err)
+		}
+	}()
+	log.Infof("Packaging %s in %s", strings.Split(t.packagingFormatName, "-")[1], tmpPath)
+
+	if t.flutterBuildOutputDirectory != "" {
+		buildOutputDirectoryPath, err := build.OutputDirectoryPath(strings.Split(t.packagingFormatName, "-")[0], arch)
+		if err != nil {
+			return "", err
+		}
+		destination, err := executeStringTemplate(filepath.Join(tmpPath, t.flutterBuildOutputDirectory), templateData)
+		if err != nil {
+			return "", err
+		}
+		err = copy.Copy(buildOutputDirectoryPath, destination, skipBuildInfo)
+		if err != nil {
+			return "", errors.Wrap(err, "could not copy build folder")
+		}
+	}
+	for task, destination := range t.dependsOn {
+		// The dependency is locked while it is packaged and its output is
+		// copied, tasks depending on the same task may run concurrently.
+		err := task.packDependency(ctx, templateData, packageName, projectName, applicationName, executableName, version, release, arch, filepath.Join(tmpPath, destination))
+		if err != nil {
+			return "", err
+		}
+	}
+	if t.linuxIconsDirectory != "" {
+		assetsPath, err := executeStringTemplate(filepath.Join(tmpPath, t.flutterBuildOutputDirectory, "assets"), templateData)
+		if err != nil {
+			return "", err
+		}
+		icon, err := icons.Load(assetsPath)
+		if err != nil {
+			return "", err
+		}
+		iconsDirectory, err := executeStringTemplate(filepath.Join(tmpPath, t.linuxIconsDirectory), templateData)
+		if err != nil {
+			return "", err
+		}
+		err = icon.WriteHicolor(iconsDirectory, packageName)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to generate the icons")
+		}
+	}
+	err = fileutils.CopyTemplateDir(formatPath, filepath.Join(tmpPath), templateData)
+	if err != nil {
+		return "", err
+	}
+	if t.generateBuildFiles != nil {
+		log.Infof("Generating dynamic build files")
+		err = t.generateBuildFiles(packageName, tmpPath)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	for _, file := range t.executableFiles {
+		executablePath, err := executeStringTemplate(filepath.Join(tmpPath, file), templateData)
+		if err != nil {
+			return "", err
+		}
+		err = os.Chmod(executablePath, 0777)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to change file permissions for %s file", file)
+		}
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return "", err
+	}
+	targetOS := strings.Split(t.packagingFormatName, "-")[0]
+	err = hooks.Run(ctx, hooks.PrePackage, cfg.Hooks.PrePackage[t.packagingFormatName], hooks.Env{
+		TargetOS:   targetOS,
+		Arch:       arch,
+		Version:    version,
+		StagingDir: tmpPath,
+		Format:     t.packagingFormatName,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// the staged tree is normalized after the pre-package hook, files added
+	// by the hook are normalized as well
+	if reproducible() {
+		err := normalizeStagedTree(tmpPath)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	outputDirectoryPath, err := build.OutputDirectoryPath(t.packagingFormatName, arch)
+	if err != nil {
+		return "", err
+	}
+	err = os.RemoveAll(outputDirectoryPath)
+	log.Printf("Cleaning the build directory")
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to clean output directory %s", outputDirectoryPath)
+	}
+
+	packagingArch, err := t.packagingArch(arch)
+	if err != nil {
+		return "", err
+	}
+	relativeOutputFilePath, err := t.packagingFunction(ctx, tmpPath, applicationName, packageName, executableName, version, release, packagingArch)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to package %s", t.packagingFormatName)
+	}
+	outputFileName := filepath.Base(relativeOutputFilePath)
+	outputFilePath := filepath.Join(outputDirectoryPath, outputFileName)
+	err = copy.Copy(filepath.Join(tmpPath, relativeOutputFilePath), outputFilePath)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not move %s file", outputFileName)
+	}
+	err = os.Chmod(outputFilePath, 0755)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not change file permissions for %s", outputFileName)
+	}
+	err = t.runPostPackageHook(ctx, targetOS, arch, version, outputFilePath)
+	if err != nil {
+		return "", err
+	}
+	err = t.writeBuildInfo(arch, outputFilePath)
+	if err != nil {
+		return "", err
+	}
+	return outputFilePath, nil
+}
+
+// runPostPackageHook runs the post-package hook on a packaged file.
+func (t *packagingTask) runPostPackageHook(ctx context.Context, targetOS, arch, version, outputFilePath string) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return err
+	}
+	commands := cfg.Hooks.PostPackage
+	if len(commands) == 0 {
+		return nil
+	}
+	artifact, err := filepath.Abs(outputFilePath)
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve the packaged file")
+	}
+	return hooks.Run(ctx, hooks.PostPackage, commands, hooks.Env{
+		TargetOS:  targetOS,
+		Arch:      arch,
+		Version:   version,
+		OutputDir: filepath.Dir(artifact),
+		Format:    t.packagingFormatName,
+		Artifact:  artifact,
+	})
+}
+
+// skipBuildInfo doesn't copy the build info of build outputs into packages.
+var skipBuildInfo = copy.Options{
+	Skip: func(src string) (bool, error) {
+		return filepath.Base(src) == buildinfo.Filename, nil
+	},
+}
+
+// writeBuildInfo writes the build info of the package next to it, based on
+// the build info of the build output that was packaged.
+func (t *packagingTask) writeBuildInfo(arch, outputFilePath string) error {
+	buildOutputDirectoryPath, err := build.OutputDirectoryPath(strings.Split(t.packagingFormatName, "-")[0], arch)
+	if err != nil {
+		return err
+	}
+	info, err := buildinfo.Read(buildOutputDirectoryPath)
+	if os.IsNotExist(err) {
+		log.Debugf("The build output has no %s, %s has no build info", buildinfo.Filename, t.packagingFormatName)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	info.Target = t.packagingFormatName
+	info.Files = nil
+	stat, err := os.Stat(outputFilePath)
+	if err != nil {
+		return err
+	}
+	if stat.IsDir() {
+		// the files of packages made of a directory, such as an app bundle,
+		// are listed
+		err = info.AddFiles(filepath.Dir(outputFilePath))
+	} else {
+		err = info.AddFile(outputFilePath, filepath.Base(outputFilePath))
+	}
+	if err != nil {
+		return err
+	}
+	return buildinfo.Write(filepath.Dir(outputFilePath), info)
+}
+
+// packDependency packages a task another task depends on and copies its
+// output to destination.
+func (t *packagingTask) packDependency(ctx context.Context, templateData map[string]string, packageName, projectName, applicationName, executableName, version, release, arch, destination string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, err := t.pack(ctx, templateData, packageName, projectName, applicationName, executableName, version, release, arch)
+	if err != nil {
+		return err
+	}
+	outputDirectoryPath, err := build.OutputDirectoryPath(t.packagingFormatName, arch)
+	if err != nil {
+		return err
+	}
+	err = copy.Copy(outputDirectoryPath, destination, skipBuildInfo)
+	if err != nil {
+		return errors.Wrapf(err, "could not copy build folder of %s", t.packagingFormatName)
+	}
+	return nil
+}
+
+func (t *packagingTask) AssertInitialized() error {
+	if t.skipAssertInitialized || t.IsInitialized() {
+		return nil
+	}
+	command := "hover init-packaging " + t.packagingFormatName
+	if flavor := build.Flavor(); flavor != "" {
+		command += " --flavor " + flavor
+	}
+	return errors.Errorf("%s is not initialized for packaging, please run `%s` first", t.packagingFormatName, command)
+}
+
+func (t *packagingTask) IsInitialized() bool {
+	directoryPath, err := packagingFormatPath(t.packagingFormatName)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(directoryPath)
+	return !os.IsNotExist(err)
+}
+
+// writeFile creates a file with the content written by write.
+func writeFile(path string, write func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = write(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func executeStringTemplate(t string, data map[string]string) (string, error) {
+	tmplFile, err := template.New("").Option("missingkey=error").Parse(t)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse template string")
+	}
+	var tmplBytes bytes.Buffer
+	err = tmplFile.Execute(&tmplBytes, data)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to execute template string %s", t)
+	}
+	return tmplBytes.String(), nil
+}
@@ -0,0 +1,66 @@
+package packaging
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// sourceDateEpoch is the modification time given to the staged files of
+// reproducible packages. Packages aren't reproducible when it is zero.
+var sourceDateEpoch time.Time
+
+// SetSourceDateEpoch makes the packaging tasks reproducible: before a package
+// is created, the modes of the staged files are normalized and their
+// modification time is set to epoch. A zero epoch turns it off.
+func SetSourceDateEpoch(epoch time.Time) {
+	sourceDateEpoch = epoch
+}
+
+// reproducible returns true when the packages must be reproducible.
+func reproducible() bool {
+	return !sourceDateEpoch.IsZero()
+}
+
+// normalizeStagedTree gives the files staged in root a mode that doesn't
+// depend on the umask (0755 for directories and executables, 0644 for other
+// files) and sets their modification time to the source date epoch.
+// Symbolic links are left untouched.
+func normalizeStagedTree(root string) error {
+	var dirs []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+		mode := os.FileMode(0644)
+		if info.IsDir() || info.Mode()&0111 != 0 {
+			mode = 0755
+		}
+		err = os.Chmod(path, mode)
+		if err != nil {
+			return errors.Wrapf(err, "failed to normalize the mode of %s", path)
+		}
+		if info.IsDir() {
+			// the modification time of a directory changes when its
+			// content does, directories are handled last.
+			dirs = append(dirs, path)
+			return nil
+		}
+		return os.Chtimes(path, sourceDateEpoch, sourceDateEpoch)
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to normalize the staged files")
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		err = os.Chtimes(dirs[i], sourceDateEpoch, sourceDateEpoch)
+		if err != nil {
+			return errors.Wrapf(err, "failed to normalize the modification time of %s", dirs[i])
+		}
+	}
+	return nil
+}
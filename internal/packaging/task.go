@@ -1,12 +1,14 @@
 package packaging
 
+import "context"
+
 // Task contains all configuration options for a given packaging method.
 // TODO: Rename to something that suits it more? Mabe Executor?
 type Task interface {
 	Name() string
-	Init()
+	Init() error
 	IsInitialized() bool
-	AssertInitialized()
-	Pack(buildVersion string)
-	AssertSupported()
+	AssertInitialized() error
+	Pack(ctx context.Context, buildVersion, arch string) (string, error)
+	CheckSupported() error
 }
@@ -1,11 +1,12 @@
 package packaging
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/sha1"
 	"encoding/hex"
 	"fmt"
-	"image/png"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
@@ -13,8 +14,9 @@ import (
 	"runtime"
 	"strings"
 
-	ico "github.com/Kodeworks/golang-image-ico"
+	"github.com/pkg/errors"
 
+	"github.com/go-flutter-desktop/hover/internal/icons"
 	"github.com/go-flutter-desktop/hover/internal/log"
 )
 
@@ -29,47 +31,29 @@ var WindowsMsiTask = &packagingTask{
 		"windows-msi/app.wxs.tmpl": "{{.packageName}}.wxs.tmpl",
 	},
 	flutterBuildOutputDirectory: "build",
-	packagingFunction: func(tmpPath, applicationName, packageName, executableName, version, release string) (string, error) {
+	packagingFunction: func(ctx context.Context, tmpPath, applicationName, packageName, executableName, version, release, arch string) (string, error) {
 		outputFileName := fmt.Sprintf("%s %s.msi", applicationName, version)
-		iconPngFile, err := os.Open(filepath.Join(tmpPath, "build", "assets", "icon.png"))
+		icon, err := icons.Load(filepath.Join(tmpPath, "build", "assets"))
 		if err != nil {
 			return "", err
 		}
-		pngImage, err := png.Decode(iconPngFile)
-		if err != nil {
-			return "", err
-		}
-		// We can't defer it, because windows reports that the file is used by another program
-		err = iconPngFile.Close()
-		if err != nil {
-			return "", err
-		}
-		iconIcoFile, err := os.Create(filepath.Join(tmpPath, "build", "assets", "icon.ico"))
-		if err != nil {
-			return "", err
-		}
-		err = ico.Encode(iconIcoFile, pngImage)
-		if err != nil {
-			return "", err
-		}
-		// We can't defer it, because windows reports that the file is used by another program
-		err = iconIcoFile.Close()
+		err = writeFile(filepath.Join(tmpPath, "build", "assets", "icon.ico"), icon.WriteIco)
 		if err != nil {
 			return "", err
 		}
 		switch runtime.GOOS {
 		case "windows":
-			cmdCandle := exec.Command("candle", fmt.Sprintf("%s.wxs", packageName))
+			cmdCandle := exec.CommandContext(ctx, "candle", fmt.Sprintf("%s.wxs", packageName))
 			cmdCandle.Dir = tmpPath
-			cmdCandle.Stdout = os.Stdout
+			cmdCandle.Stdout = log.CommandOutput()
 			cmdCandle.Stderr = os.Stderr
 			err = cmdCandle.Run()
 			if err != nil {
 				return "", err
 			}
-			cmdLight := exec.Command("light", fmt.Sprintf("%s.wixobj", packageName), "-sval")
+			cmdLight := exec.CommandContext(ctx, "light", fmt.Sprintf("%s.wixobj", packageName), "-sval")
 			cmdLight.Dir = tmpPath
-			cmdLight.Stdout = os.Stdout
+			cmdLight.Stdout = log.CommandOutput()
 			cmdLight.Stderr = os.Stderr
 			err = cmdLight.Run()
 			if err != nil {
@@ -80,16 +64,16 @@ var WindowsMsiTask = &packagingTask{
 				return "", err
 			}
 		case "linux":
-			cmdWixl := exec.Command("wixl", "-v", fmt.Sprintf("%s.wxs", packageName), "-o", outputFileName)
+			cmdWixl := exec.CommandContext(ctx, "wixl", "-v", fmt.Sprintf("%s.wxs", packageName), "-o", outputFileName)
 			cmdWixl.Dir = tmpPath
-			cmdWixl.Stdout = os.Stdout
+			cmdWixl.Stdout = log.CommandOutput()
 			cmdWixl.Stderr = os.Stderr
 			err = cmdWixl.Run()
 			if err != nil {
 				return "", err
 			}
 		default:
-			panic("should be unreachable")
+			return "", errors.Errorf("packaging windows-msi is not supported on %s", runtime.GOOS)
 		}
 		return outputFileName, nil
 	},
@@ -97,117 +81,82 @@ var WindowsMsiTask = &packagingTask{
 		"windows": {"candle", "light"},
 		"linux":   {"wixl"},
 	},
-	generateInitFiles: func(packageName, path string) {
+	generateInitFiles: func(packageName, path string) error {
 		b := make([]byte, 16)
 		_, err := rand.Read(b)
 		if err != nil {
-			log.Errorf("Failed to generate GUID: %v", err)
-			os.Exit(1)
+			return errors.Wrap(err, "failed to generate GUID")
 		}
 		upgradeCode := strings.ToUpper(fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]))
 		err = ioutil.WriteFile(filepath.Join(path, "upgrade-code.txt"), []byte(fmt.Sprintf("%s\n# This GUID is your upgrade code and ensures that you can properly update your app.\n# Don't change it.", upgradeCode)), 0755)
 		if err != nil {
-			log.Errorf("Failed to create `upgrade-code.txt` file: %v", err)
-			os.Exit(1)
+			return errors.Wrap(err, "failed to create `upgrade-code.txt` file")
 		}
+		return nil
 	},
-	extraTemplateData: func(packageName, path string) map[string]string {
+	extraTemplateData: func(packageName, path string) (map[string]string, error) {
 		data, err := ioutil.ReadFile(filepath.Join(path, "upgrade-code.txt"))
 		if err != nil {
-			log.Errorf("Failed to read `go/packaging/windows-msi/upgrade-code.txt`: %v", err)
 			if os.IsNotExist(err) {
 				log.Errorf("Please re-init windows-msi to generate the `go/packaging/windows-msi/upgrade-code.txt`")
 				log.Errorf("or put a GUID from https://www.guidgen.com/ into a new `go/packaging/windows-msi/upgrade-code.txt` file.")
 			}
-			os.Exit(1)
+			return nil, errors.Wrap(err, "failed to read `go/packaging/windows-msi/upgrade-code.txt`")
 		}
 		guid := strings.Split(string(data), "\n")[0]
 		return map[string]string{
 			"upgradeCode":   guid,
 			"pathSeparator": string(os.PathSeparator),
-		}
+		}, nil
 	},
-	generateBuildFiles: func(packageName, tmpPath string) {
-		directoriesFilePath, err := filepath.Abs(filepath.Join(tmpPath, "directories.wxi"))
-		if err != nil {
-			log.Errorf("Failed to resolve absolute path for directories.wxi file %s: %v", packageName, err)
-			os.Exit(1)
-		}
-		directoriesFile, err := os.Create(directoriesFilePath)
-		if err != nil {
-			log.Errorf("Failed to create directories.wxi file %s: %v", packageName, err)
-			os.Exit(1)
-		}
-		directoryRefsFilePath, err := filepath.Abs(filepath.Join(tmpPath, "directory_refs.wxi"))
-		if err != nil {
-			log.Errorf("Failed to resolve absolute path for directory_refs.wxi file %s: %v", packageName, err)
-			os.Exit(1)
-		}
-		directoryRefsFile, err := os.Create(directoryRefsFilePath)
-		if err != nil {
-			log.Errorf("Failed to create directory_refs.wxi file %s: %v", packageName, err)
-			os.Exit(1)
-		}
-		componentRefsFilePath, err := filepath.Abs(filepath.Join(tmpPath, "component_refs.wxi"))
-		if err != nil {
-			log.Errorf("Failed to resolve absolute path for component_refs.wxi file %s: %v", packageName, err)
-			os.Exit(1)
-		}
-		componentRefsFile, err := os.Create(componentRefsFilePath)
-		if err != nil {
-			log.Errorf("Failed to create component_refs.wxi file %s: %v", packageName, err)
-			os.Exit(1)
-		}
+	generateBuildFiles: func(packageName, tmpPath string) error {
+		directoriesFileContent = nil
+		directoryRefsFileContent = nil
+		componentRefsFileContent = nil
 		directoriesFileContent = append(directoriesFileContent, "<Include>")
 		directoryRefsFileContent = append(directoryRefsFileContent, "<Include>")
 		componentRefsFileContent = append(componentRefsFileContent, "<Include>")
-		windowsMsiProcessFiles(filepath.Join(tmpPath, "build", "flutter_assets"))
+		err := windowsMsiProcessFiles(filepath.Join(tmpPath, "build", "flutter_assets"))
+		if err != nil {
+			return err
+		}
 		directoriesFileContent = append(directoriesFileContent, "</Include>")
 		directoryRefsFileContent = append(directoryRefsFileContent, "</Include>")
 		componentRefsFileContent = append(componentRefsFileContent, "</Include>")
 
-		for _, line := range directoriesFileContent {
-			if _, err := directoriesFile.WriteString(line + "\n"); err != nil {
-				log.Errorf("Could not write directories.wxi: %v", packageName, err)
-				os.Exit(1)
-			}
-		}
-		err = directoriesFile.Close()
+		err = writeWxiFile(filepath.Join(tmpPath, "directories.wxi"), directoriesFileContent)
 		if err != nil {
-			log.Errorf("Could not close directories.wxi: %v", packageName, err)
-			os.Exit(1)
-		}
-		for _, line := range directoryRefsFileContent {
-			if _, err := directoryRefsFile.WriteString(line + "\n"); err != nil {
-				log.Errorf("Could not write directory_refs.wxi: %v", packageName, err)
-				os.Exit(1)
-			}
+			return err
 		}
-		err = directoryRefsFile.Close()
+		err = writeWxiFile(filepath.Join(tmpPath, "directory_refs.wxi"), directoryRefsFileContent)
 		if err != nil {
-			log.Errorf("Could not close directory_refs.wxi: %v", packageName, err)
-			os.Exit(1)
+			return err
 		}
-		for _, line := range componentRefsFileContent {
-			if _, err := componentRefsFile.WriteString(line + "\n"); err != nil {
-				log.Errorf("Could not write component_refs.wxi: %v", packageName, err)
-				os.Exit(1)
+		return writeWxiFile(filepath.Join(tmpPath, "component_refs.wxi"), componentRefsFileContent)
+	},
+}
+
+// writeWxiFile writes the lines of a wix include file.
+func writeWxiFile(path string, lines []string) error {
+	err := writeFile(path, func(w io.Writer) error {
+		for _, line := range lines {
+			if _, err := io.WriteString(w, line+"\n"); err != nil {
+				return err
 			}
 		}
-		err = componentRefsFile.Close()
-		if err != nil {
-			log.Errorf("Could not close component_refs.wxi: %v", packageName, err)
-			os.Exit(1)
-		}
-	},
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "could not write %s", filepath.Base(path))
+	}
+	return nil
 }
 
-func windowsMsiProcessFiles(path string) {
+func windowsMsiProcessFiles(path string) error {
 	pathSeparator := string(os.PathSeparator)
 	files, err := ioutil.ReadDir(path)
 	if err != nil {
-		log.Errorf("Failed to read directory %s: %v", path, err)
-		os.Exit(1)
+		return errors.Wrapf(err, "failed to read directory %s", path)
 	}
 
 	for _, f := range files {
@@ -218,7 +167,10 @@ func windowsMsiProcessFiles(path string) {
 			directoriesFileContent = append(directoriesFileContent,
 				fmt.Sprintf(`<Directory Id="FLUTTERASSETSDIRECTORY_%s" Name="%s">`, id, f.Name()),
 			)
-			windowsMsiProcessFiles(p)
+			err = windowsMsiProcessFiles(p)
+			if err != nil {
+				return err
+			}
 			directoriesFileContent = append(directoriesFileContent,
 				"</Directory>",
 			)
@@ -244,6 +196,7 @@ func windowsMsiProcessFiles(path string) {
 			)
 		}
 	}
+	return nil
 }
 
 func hashSha1(content string) string {
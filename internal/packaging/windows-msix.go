@@ -0,0 +1,116 @@
+package packaging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/hover/internal/icons"
+	"github.com/go-flutter-desktop/hover/internal/log"
+	"github.com/go-flutter-desktop/hover/internal/msix"
+)
+
+// msixLogos are the logos the manifest refers to, with their size.
+var msixLogos = map[string]int{
+	"Square150x150Logo.png": 150,
+	"Square44x44Logo.png":   44,
+	"StoreLogo.png":         50,
+}
+
+// WindowsMsixTask packaging for windows as msix
+var WindowsMsixTask = &packagingTask{
+	packagingFormatName: "windows-msix",
+	templateFiles: map[string]string{
+		"windows-msix/AppxManifest.xml.tmpl": "package/AppxManifest.xml.tmpl",
+	},
+	flutterBuildOutputDirectory: "package",
+	packagingFunction: func(ctx context.Context, tmpPath, applicationName, packageName, executableName, version, release, arch string) (string, error) {
+		outputFileName := fmt.Sprintf("%s %s.msix", applicationName, version)
+		packagePath := filepath.Join(tmpPath, "package")
+		manifest, err := ioutil.ReadFile(filepath.Join(packagePath, msix.ManifestFileName))
+		if err != nil {
+			return "", err
+		}
+		_, err = msix.ReadIdentity(manifest)
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid %s", msix.ManifestFileName)
+		}
+		icon, err := icons.Load(filepath.Join(packagePath, "assets"))
+		if err != nil {
+			return "", err
+		}
+		err = os.MkdirAll(filepath.Join(packagePath, "images"), 0755)
+		if err != nil {
+			return "", err
+		}
+		for name, size := range msixLogos {
+			size := size
+			logoPath := filepath.Join(packagePath, "images", name)
+			err = writeFile(logoPath, func(w io.Writer) error {
+				return icon.WritePNG(w, size)
+			})
+			if err != nil {
+				return "", errors.Wrapf(err, "failed to write %s", name)
+			}
+			// the staged tree is already normalized
+			if reproducible() {
+				err = os.Chtimes(logoPath, sourceDateEpoch, sourceDateEpoch)
+				if err != nil {
+					return "", err
+				}
+			}
+		}
+		err = writeFile(filepath.Join(tmpPath, outputFileName), func(w io.Writer) error {
+			return msix.Build(packagePath, w)
+		})
+		if err != nil {
+			return "", err
+		}
+		err = signMsix(ctx, filepath.Join(tmpPath, outputFileName))
+		if err != nil {
+			return "", err
+		}
+		return outputFileName, nil
+	},
+	architectures: map[string]string{
+		"amd64": "x64",
+		"arm64": "arm64",
+	},
+	requiredTools: map[string][]string{
+		"linux":   {},
+		"darwin":  {},
+		"windows": {},
+	},
+}
+
+// signMsix signs a package with signtool when the MSIX_CERTIFICATE
+// environment variable is the path of a .pfx certificate, whose password is
+// MSIX_CERTIFICATE_PASSWORD. Packages are left unsigned otherwise.
+func signMsix(ctx context.Context, path string) error {
+	certificate := os.Getenv("MSIX_CERTIFICATE")
+	if certificate == "" {
+		return nil
+	}
+	if runtime.GOOS != "windows" {
+		return errors.New("signing msix packages requires signtool, which is only available on windows")
+	}
+	args := []string{"sign", "/fd", "SHA256", "/f", certificate}
+	if password := os.Getenv("MSIX_CERTIFICATE_PASSWORD"); password != "" {
+		args = append(args, "/p", password)
+	}
+	cmdSigntool := exec.CommandContext(ctx, "signtool", append(args, path)...)
+	cmdSigntool.Stdout = log.CommandOutput()
+	cmdSigntool.Stderr = os.Stderr
+	err := cmdSigntool.Run()
+	if err != nil {
+		return errors.Wrap(err, "failed to sign the package")
+	}
+	return nil
+}
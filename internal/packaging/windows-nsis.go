@@ -0,0 +1,101 @@
+package packaging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/hover/internal/icons"
+	"github.com/go-flutter-desktop/hover/internal/log"
+)
+
+// WindowsNsisTask packaging for windows as nsis installer
+var WindowsNsisTask = &packagingTask{
+	packagingFormatName: "windows-nsis",
+	templateFiles: map[string]string{
+		"windows-nsis/app.nsi.tmpl": "{{.packageName}}.nsi.tmpl",
+	},
+	flutterBuildOutputDirectory: "build",
+	packagingFunction: func(ctx context.Context, tmpPath, applicationName, packageName, executableName, version, release, arch string) (string, error) {
+		outputFileName := fmt.Sprintf("%s %s Setup.exe", applicationName, version)
+		icon, err := icons.Load(filepath.Join(tmpPath, "build", "assets"))
+		if err != nil {
+			return "", err
+		}
+		err = writeFile(filepath.Join(tmpPath, "icon.ico"), icon.WriteIco)
+		if err != nil {
+			return "", err
+		}
+		err = writeFile(filepath.Join(tmpPath, "uninstall-files.nsh"), func(w io.Writer) error {
+			return writeNsisUninstallFiles(filepath.Join(tmpPath, "build"), w)
+		})
+		if err != nil {
+			return "", err
+		}
+		cmdMakensis := exec.CommandContext(ctx, "makensis", "-DOUTFILE="+outputFileName, fmt.Sprintf("%s.nsi", packageName))
+		cmdMakensis.Dir = tmpPath
+		cmdMakensis.Stdout = log.CommandOutput()
+		cmdMakensis.Stderr = os.Stderr
+		err = cmdMakensis.Run()
+		if err != nil {
+			return "", err
+		}
+		return outputFileName, nil
+	},
+	requiredTools: map[string][]string{
+		"windows": {"makensis"},
+		"linux":   {"makensis"},
+		"darwin":  {"makensis"},
+	},
+}
+
+// writeNsisUninstallFiles writes the instructions removing the files of the
+// build directory from the install directory, deepest directories first.
+func writeNsisUninstallFiles(buildPath string, w io.Writer) error {
+	var files, directories []string
+	err := filepath.Walk(buildPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == buildPath {
+			return nil
+		}
+		relativePath, err := filepath.Rel(buildPath, path)
+		if err != nil {
+			return err
+		}
+		// $ starts a variable in nsis strings
+		nsisPath := strings.Replace(filepath.ToSlash(relativePath), "$", "$$", -1)
+		nsisPath = `$INSTDIR\` + strings.Replace(nsisPath, "/", `\`, -1)
+		if info.IsDir() {
+			directories = append(directories, nsisPath)
+		} else {
+			files = append(files, nsisPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to list the installed files")
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(directories)))
+	for _, file := range files {
+		_, err = fmt.Fprintf(w, "Delete \"%s\"\n", file)
+		if err != nil {
+			return err
+		}
+	}
+	for _, directory := range directories {
+		_, err = fmt.Fprintf(w, "RMDir \"%s\"\n", directory)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
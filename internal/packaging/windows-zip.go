@@ -0,0 +1,36 @@
+package packaging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/go-flutter-desktop/hover/internal/archive"
+)
+
+// WindowsZipTask packaging for windows as zip
+var WindowsZipTask = &packagingTask{
+	packagingFormatName: "windows-zip",
+	templateFiles: map[string]string{
+		"windows-zip/launcher.bat.tmpl": "{{.packageName}}-{{.version}}/{{.executableName}}.bat.tmpl",
+		"windows-zip/README.txt.tmpl":   "{{.packageName}}-{{.version}}/README.txt.tmpl",
+	},
+	flutterBuildOutputDirectory: "{{.packageName}}-{{.version}}/app",
+	packagingFunction: func(ctx context.Context, tmpPath, applicationName, packageName, executableName, version, release, arch string) (string, error) {
+		directoryName := fmt.Sprintf("%s-%s", packageName, version)
+		outputFileName := fmt.Sprintf("%s-%s-windows-%s.zip", packageName, version, arch)
+		err := writeFile(filepath.Join(tmpPath, outputFileName), func(w io.Writer) error {
+			return archive.WriteZip(filepath.Join(tmpPath, directoryName), directoryName, w, nil)
+		})
+		if err != nil {
+			return "", err
+		}
+		return outputFileName, nil
+	},
+	requiredTools: map[string][]string{
+		"linux":   {},
+		"darwin":  {},
+		"windows": {},
+	},
+}
@@ -0,0 +1,323 @@
+// Package pacman writes pacman packages (.pkg.tar.zst) without makepkg.
+package pacman
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// Options are the properties of a package that aren't read from the
+// PKGBUILD.
+type Options struct {
+	// Arch is the architecture of the package, see PKGBUILD.PackageArch.
+	Arch      string
+	BuildDate time.Time
+	Packager  string
+	// InstallScript is the content of the install script of the PKGBUILD.
+	InstallScript []byte
+	// Executables are the installed paths of the files packaged with mode
+	// 0755, whatever their mode on the host.
+	Executables []string
+}
+
+// FileName returns the name of the package file.
+func FileName(pkgbuild PKGBUILD, arch string) string {
+	return fmt.Sprintf("%s-%s-%s.pkg.tar.zst", pkgbuild.Name, pkgbuild.FullVersion(), arch)
+}
+
+// entry is a member of the package.
+type entry struct {
+	name       string // relative to the root of the installed system, slash separated
+	info       os.FileInfo
+	link       string
+	data       []byte // content of the generated files
+	path       string // path of the staged files
+	executable bool   // forced to mode 0755
+}
+
+// Build writes the package of the staged tree root to w: the .PKGINFO and
+// .MTREE metadata files, the install script of the PKGBUILD and the files
+// of the tree, owned by root with mode 0755 or 0644. The
+// modification times of the staged files are kept, the metadata files are
+// dated with the build date.
+func Build(root string, w io.Writer, pkgbuild PKGBUILD, opts Options) error {
+	entries, err := members(root, pkgbuild, opts)
+	if err != nil {
+		return err
+	}
+	zstdWriter, err := zstd.NewWriter(w)
+	if err != nil {
+		return errors.Wrap(err, "failed to create the zstd writer")
+	}
+	err = writeMembers(zstdWriter, entries)
+	if err != nil {
+		return err
+	}
+	return zstdWriter.Close()
+}
+
+// members returns the members of the package, sorted by name as makepkg
+// does.
+func members(root string, pkgbuild PKGBUILD, opts Options) ([]*entry, error) {
+	buildDate := opts.BuildDate.Truncate(time.Second)
+	files, size, err := stagedFiles(root, opts.Executables)
+	if err != nil {
+		return nil, err
+	}
+	var entries []*entry
+	if opts.InstallScript != nil {
+		entries = append(entries, metadataEntry(".INSTALL", opts.InstallScript, buildDate))
+	}
+	pkginfo := metadataEntry(".PKGINFO", pkgInfo(pkgbuild, opts, size), buildDate)
+	mtreeData, err := mtree(append(append(entries, pkginfo), files...))
+	if err != nil {
+		return nil, err
+	}
+	entries = append(entries, metadataEntry(".MTREE", mtreeData, buildDate), pkginfo)
+	return append(entries, files...), nil
+}
+
+// writeMembers writes the tar archive of the members of the package to w.
+func writeMembers(w io.Writer, entries []*entry) error {
+	tarWriter := tar.NewWriter(w)
+	for _, e := range entries {
+		err := writeEntry(tarWriter, e)
+		if err != nil {
+			return errors.Wrapf(err, "failed to write %s", e.name)
+		}
+	}
+	return tarWriter.Close()
+}
+
+// metadataInfo is the file info of a generated file.
+type metadataInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (i metadataInfo) Name() string       { return i.name }
+func (i metadataInfo) Size() int64        { return i.size }
+func (i metadataInfo) Mode() os.FileMode  { return 0644 }
+func (i metadataInfo) ModTime() time.Time { return i.modTime }
+func (i metadataInfo) IsDir() bool        { return false }
+func (i metadataInfo) Sys() interface{}   { return nil }
+
+func metadataEntry(name string, data []byte, modTime time.Time) *entry {
+	return &entry{
+		name: name,
+		info: metadataInfo{name: name, size: int64(len(data)), modTime: modTime},
+		data: data,
+	}
+}
+
+// stagedFiles lists the files of the staged tree and returns their installed
+// size.
+func stagedFiles(root string, executables []string) ([]*entry, int64, error) {
+	isExecutable := make(map[string]bool, len(executables))
+	for _, executable := range executables {
+		isExecutable[executable] = true
+	}
+	var entries []*entry
+	var size int64
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+		relativePath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		e := &entry{name: filepath.ToSlash(relativePath), info: info, path: path}
+		e.executable = isExecutable["/"+e.name]
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			e.link, err = os.Readlink(path)
+			if err != nil {
+				return err
+			}
+		case info.Mode().IsRegular():
+			size += info.Size()
+		case !info.IsDir():
+			return errors.Errorf("%s is not a regular file", relativePath)
+		}
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to list the staged files")
+	}
+	return entries, size, nil
+}
+
+// mode returns the permissions of a member of the package: 0755 for
+// directories and executables, 0644 for other files.
+func (e *entry) mode() int64 {
+	switch {
+	case e.link != "":
+		return 0777
+	case e.info.IsDir() || e.executable || e.info.Mode()&0111 != 0:
+		return 0755
+	default:
+		return 0644
+	}
+}
+
+// digests returns the md5 and sha256 digests of a regular file.
+func (e *entry) digests() (string, string, error) {
+	md5Hash := md5.New()
+	sha256Hash := sha256.New()
+	hashes := io.MultiWriter(md5Hash, sha256Hash)
+	if e.path == "" {
+		hashes.Write(e.data)
+	} else {
+		file, err := os.Open(e.path)
+		if err != nil {
+			return "", "", err
+		}
+		defer file.Close()
+		_, err = io.Copy(hashes, file)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	return hex.EncodeToString(md5Hash.Sum(nil)), hex.EncodeToString(sha256Hash.Sum(nil)), nil
+}
+
+func writeEntry(tarWriter *tar.Writer, e *entry) error {
+	header := &tar.Header{
+		Name:    e.name,
+		Mode:    e.mode(),
+		ModTime: e.info.ModTime(),
+		Uname:   "root",
+		Gname:   "root",
+	}
+	switch {
+	case e.info.IsDir():
+		header.Typeflag = tar.TypeDir
+		header.Name += "/"
+	case e.link != "":
+		header.Typeflag = tar.TypeSymlink
+		header.Linkname = e.link
+	default:
+		header.Typeflag = tar.TypeReg
+		header.Size = e.info.Size()
+	}
+	err := tarWriter.WriteHeader(header)
+	if err != nil {
+		return err
+	}
+	if header.Typeflag != tar.TypeReg {
+		return nil
+	}
+	if e.path == "" {
+		_, err = tarWriter.Write(e.data)
+		return err
+	}
+	file, err := os.Open(e.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(tarWriter, file)
+	return err
+}
+
+// pkgInfo returns the .PKGINFO of the package, read by pacman.
+func pkgInfo(pkgbuild PKGBUILD, opts Options, size int64) []byte {
+	var b bytes.Buffer
+	b.WriteString("# Generated by hover\n")
+	add := func(key string, values ...string) {
+		for _, value := range values {
+			fmt.Fprintf(&b, "%s = %s\n", key, value)
+		}
+	}
+	add("pkgname", pkgbuild.Name)
+	add("pkgbase", pkgbuild.Name)
+	add("pkgver", pkgbuild.FullVersion())
+	add("pkgdesc", pkgbuild.Description)
+	if pkgbuild.URL != "" {
+		add("url", pkgbuild.URL)
+	}
+	add("builddate", fmt.Sprint(opts.BuildDate.Unix()))
+	add("packager", opts.Packager)
+	add("size", fmt.Sprint(size))
+	add("arch", opts.Arch)
+	add("license", pkgbuild.License...)
+	add("replaces", pkgbuild.Replaces...)
+	add("group", pkgbuild.Groups...)
+	add("conflict", pkgbuild.Conflicts...)
+	add("provides", pkgbuild.Provides...)
+	add("backup", pkgbuild.Backup...)
+	add("depend", pkgbuild.Depends...)
+	add("optdepend", pkgbuild.OptDepends...)
+	return b.Bytes()
+}
+
+// mtreeEscape escapes the characters of a path that mtree doesn't allow, as
+// octal escape sequences.
+func mtreeEscape(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c <= ' ' || c >= 0x7f || c == '#' || c == '=' || c == '\\' {
+			fmt.Fprintf(&b, "\\%03o", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// mtree returns the gzip compressed .MTREE of the members of the package,
+// used by pacman to check the installed files.
+func mtree(entries []*entry) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("#mtree\n/set type=file uid=0 gid=0 mode=644\n")
+	for _, e := range entries {
+		fmt.Fprintf(&b, "./%s time=%d.0", mtreeEscape(e.name), e.info.ModTime().Unix())
+		if mode := e.mode(); mode != 0644 {
+			fmt.Fprintf(&b, " mode=%o", mode)
+		}
+		switch {
+		case e.info.IsDir():
+			b.WriteString(" type=dir")
+		case e.link != "":
+			fmt.Fprintf(&b, " type=link link=%s", mtreeEscape(e.link))
+		default:
+			md5sum, sha256sum, err := e.digests()
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read %s", e.name)
+			}
+			fmt.Fprintf(&b, " size=%d md5digest=%s sha256digest=%s", e.info.Size(), md5sum, sha256sum)
+		}
+		b.WriteByte('\n')
+	}
+	var compressed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&compressed)
+	_, err := gzipWriter.Write(b.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	err = gzipWriter.Close()
+	if err != nil {
+		return nil, err
+	}
+	return compressed.Bytes(), nil
+}
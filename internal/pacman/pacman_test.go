@@ -0,0 +1,129 @@
+package pacman
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-flutter-desktop/hover/internal/testutil"
+)
+
+const testPKGBUILD = `# Maintainer: hover
+pkgname=app
+pkgver=1.0.0
+pkgrel=100
+epoch=0
+pkgdesc="An app written by \"hover\" users"
+arch=("x86_64" 'aarch64')
+url="https://example.com/$pkgname"
+license=('MIT')
+depends=(glibc
+  'libx11' # the window
+)
+depends_x86_64=(lib32-glibc)
+install=${pkgname}.install
+
+package() {
+    mkdir -p $pkgdir/
+    cp * $pkgdir/ -r # don't copy the { of the comment
+}
+`
+
+func TestParsePKGBUILD(t *testing.T) {
+	pkgbuild, err := ParsePKGBUILD([]byte(testPKGBUILD), "x86_64")
+	require.Equal(t, err, nil, "failed to parse the PKGBUILD: %v", err)
+	require.Equal(t, PKGBUILD{
+		Name:        "app",
+		Epoch:       "0",
+		Version:     "1.0.0",
+		Release:     "100",
+		Description: `An app written by "hover" users`,
+		URL:         "https://example.com/app",
+		Arch:        []string{"x86_64", "aarch64"},
+		License:     []string{"MIT"},
+		Depends:     []string{"glibc", "libx11", "lib32-glibc"},
+		Install:     "app.install",
+	}, pkgbuild)
+	require.Equal(t, "1.0.0-100", pkgbuild.FullVersion())
+	_, err = pkgbuild.PackageArch("armv7h")
+	require.NotEqual(t, err, nil, "armv7h isn't in the arch array")
+
+	for _, data := range []string{
+		"pkgname=(app app-docs)\npkgver=1\npkgrel=1\n",
+		"pkgname=app\npkgver=$(date)\npkgrel=1\n",
+		"pkgname=app\npkgrel=1\n",
+		"if true; then pkgname=app; fi\n",
+	} {
+		_, err = ParsePKGBUILD([]byte(data), "x86_64")
+		require.NotEqual(t, err, nil, "parsing %q must fail", data)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	// the content of the files is their name
+	dir := testutil.Tree(t, map[string]testutil.File{
+		"usr/bin/app":                        {Content: "usr/bin/app", Mode: 0775},
+		"usr/lib/app/app":                    {Content: "usr/lib/app/app"},
+		"usr/lib/app/assets/my icon.png":     {Content: "usr/lib/app/assets/my icon.png", Mode: 0664},
+		"usr/share/applications/app.desktop": {Content: "usr/share/applications/app.desktop"},
+	})
+
+	pkgbuild, err := ParsePKGBUILD([]byte(testPKGBUILD), "x86_64")
+	require.Equal(t, err, nil, "failed to parse the PKGBUILD: %v", err)
+	var buf bytes.Buffer
+	err = Build(dir, &buf, pkgbuild, Options{
+		Arch:          "x86_64",
+		BuildDate:     testutil.Epoch,
+		Packager:      "hover",
+		InstallScript: []byte("post_install() {\n  true\n}\n"),
+		Executables:   []string{"/usr/lib/app/app"},
+	})
+	require.Equal(t, err, nil, "failed to write the package: %v", err)
+
+	zstdReader, err := zstd.NewReader(&buf)
+	require.Equal(t, err, nil, "failed to read the package: %v", err)
+	defer zstdReader.Close()
+	tarReader := tar.NewReader(zstdReader)
+	var names []string
+	headers := make(map[string]*tar.Header)
+	contents := make(map[string][]byte)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.Equal(t, err, nil, "failed to read the package: %v", err)
+		names = append(names, header.Name)
+		headers[header.Name] = header
+		contents[header.Name], err = ioutil.ReadAll(tarReader)
+		require.Equal(t, err, nil, "failed to read %s: %v", header.Name, err)
+	}
+	require.Equal(t, []string{".INSTALL", ".MTREE", ".PKGINFO", "usr/", "usr/bin/", "usr/bin/app"}, names[:6])
+	require.Equal(t, int64(0755), headers["usr/bin/app"].Mode, "the files must not be writable by the group")
+	require.Equal(t, int64(0755), headers["usr/lib/app/app"].Mode)
+	require.Equal(t, int64(0644), headers["usr/lib/app/assets/my icon.png"].Mode)
+	require.Equal(t, "root", headers["usr/bin/app"].Uname)
+
+	pkginfo := string(contents[".PKGINFO"])
+	require.Contains(t, pkginfo, "pkgver = 1.0.0-100\n")
+	require.Contains(t, pkginfo, "builddate = "+strconv.FormatInt(testutil.Epoch.Unix(), 10)+"\n")
+	require.Contains(t, pkginfo, "depend = libx11\n")
+	// the size of the files is the length of their names
+	require.Contains(t, pkginfo, "size = 90\n")
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(contents[".MTREE"]))
+	require.Equal(t, err, nil, "failed to read the .MTREE: %v", err)
+	mtree, err := ioutil.ReadAll(gzipReader)
+	require.Equal(t, err, nil, "failed to read the .MTREE: %v", err)
+	require.Contains(t, string(mtree), "./usr/bin time=")
+	require.Contains(t, string(mtree), "./usr/lib/app/assets/my\\040icon.png time=")
+	require.NotContains(t, string(mtree), ".MTREE")
+	require.Contains(t, string(mtree), "./.PKGINFO time="+strconv.FormatInt(testutil.Epoch.Unix(), 10)+".0 size=")
+}
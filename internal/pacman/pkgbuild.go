@@ -0,0 +1,365 @@
+package pacman
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PKGBUILD is the metadata of a package, read from a PKGBUILD.
+type PKGBUILD struct {
+	Name        string
+	Epoch       string
+	Version     string
+	Release     string
+	Description string
+	URL         string
+	Arch        []string
+	License     []string
+	Groups      []string
+	Depends     []string
+	OptDepends  []string
+	Provides    []string
+	Conflicts   []string
+	Replaces    []string
+	// Backup are the configuration files of the package, relative to the
+	// root of the installed system.
+	Backup []string
+	// Install is the name of the install script, relative to the PKGBUILD.
+	Install string
+}
+
+// archArrays are the arrays which can be suffixed by an architecture, such
+// as depends_x86_64.
+var archArrays = []string{"depends", "optdepends", "provides", "conflicts", "replaces"}
+
+// ParsePKGBUILD reads the metadata of a package built for arch from a
+// PKGBUILD. Only the variables are read, the functions (prepare, build,
+// package, ...) are ignored. Variables are expanded, other shell expansions
+// and statements aren't supported.
+func ParsePKGBUILD(data []byte, arch string) (PKGBUILD, error) {
+	s := &shellScanner{src: string(data), line: 1, vars: make(map[string]string)}
+	arrays := make(map[string][]string)
+	for {
+		s.skipBlanks(true)
+		if s.eof() {
+			break
+		}
+		line := s.line
+		name := s.identifier()
+		if name == "" {
+			return PKGBUILD{}, errors.Errorf("line %d: only variables and functions are supported", line)
+		}
+		switch {
+		case strings.HasPrefix(s.src[s.pos:], "=("):
+			s.pos += 2
+			values, err := s.array()
+			if err != nil {
+				return PKGBUILD{}, errors.Wrapf(err, "line %d", line)
+			}
+			arrays[name] = values
+			s.vars[name] = ""
+			if len(values) > 0 {
+				s.vars[name] = values[0]
+			}
+		case strings.HasPrefix(s.src[s.pos:], "="):
+			s.pos++
+			value, err := s.word()
+			if err != nil {
+				return PKGBUILD{}, errors.Wrapf(err, "line %d", line)
+			}
+			s.vars[name] = value
+			arrays[name] = []string{value}
+		default:
+			err := s.function()
+			if err != nil {
+				return PKGBUILD{}, errors.Wrapf(err, "line %d", line)
+			}
+		}
+	}
+
+	if len(arrays["pkgname"]) > 1 {
+		return PKGBUILD{}, errors.New("split packages aren't supported")
+	}
+	p := PKGBUILD{
+		Name:        s.vars["pkgname"],
+		Epoch:       s.vars["epoch"],
+		Version:     s.vars["pkgver"],
+		Release:     s.vars["pkgrel"],
+		Description: s.vars["pkgdesc"],
+		URL:         s.vars["url"],
+		Install:     s.vars["install"],
+		Arch:        arrays["arch"],
+		License:     arrays["license"],
+		Groups:      arrays["groups"],
+		Backup:      arrays["backup"],
+	}
+	for _, name := range archArrays {
+		arrays[name] = append(arrays[name], arrays[name+"_"+arch]...)
+	}
+	p.Depends = arrays["depends"]
+	p.OptDepends = arrays["optdepends"]
+	p.Provides = arrays["provides"]
+	p.Conflicts = arrays["conflicts"]
+	p.Replaces = arrays["replaces"]
+	for field, value := range map[string]string{"pkgname": p.Name, "pkgver": p.Version, "pkgrel": p.Release} {
+		if value == "" {
+			return PKGBUILD{}, errors.Errorf("%s is missing", field)
+		}
+	}
+	return p, nil
+}
+
+// FullVersion returns the version of the package: [epoch:]pkgver-pkgrel.
+func (p PKGBUILD) FullVersion() string {
+	version := p.Version + "-" + p.Release
+	if p.Epoch != "" && p.Epoch != "0" {
+		version = p.Epoch + ":" + version
+	}
+	return version
+}
+
+// PackageArch returns the architecture of the package built for arch: any
+// when it doesn't depend on the architecture, or arch when it is supported.
+func (p PKGBUILD) PackageArch(arch string) (string, error) {
+	for _, a := range p.Arch {
+		if a == "any" || a == arch {
+			return a, nil
+		}
+	}
+	return "", errors.Errorf("%s isn't available for the %s architecture, arch is (%s)", p.Name, arch, strings.Join(p.Arch, " "))
+}
+
+// shellScanner reads the subset of the shell syntax used by PKGBUILDs.
+type shellScanner struct {
+	src  string
+	pos  int
+	line int
+	vars map[string]string
+}
+
+func (s *shellScanner) eof() bool {
+	return s.pos >= len(s.src)
+}
+
+func (s *shellScanner) peek() byte {
+	return s.src[s.pos]
+}
+
+func (s *shellScanner) next() byte {
+	c := s.src[s.pos]
+	s.pos++
+	if c == '\n' {
+		s.line++
+	}
+	return c
+}
+
+// skipBlanks skips spaces, escaped newlines, comments and, when newlines is
+// set, newlines and semicolons.
+func (s *shellScanner) skipBlanks(newlines bool) {
+	for !s.eof() {
+		switch c := s.peek(); {
+		case c == ' ' || c == '\t':
+			s.next()
+		case c == '\\' && strings.HasPrefix(s.src[s.pos:], "\\\n"):
+			s.next()
+			s.next()
+		case c == '#':
+			for !s.eof() && s.peek() != '\n' {
+				s.next()
+			}
+		case newlines && (c == '\n' || c == ';'):
+			s.next()
+		default:
+			return
+		}
+	}
+}
+
+func isIdentifierChar(c byte, first bool) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || !first && '0' <= c && c <= '9'
+}
+
+func (s *shellScanner) identifier() string {
+	start := s.pos
+	for !s.eof() && isIdentifierChar(s.peek(), s.pos == start) {
+		s.next()
+	}
+	return s.src[start:s.pos]
+}
+
+// array reads the words of an array, up to its closing parenthesis.
+func (s *shellScanner) array() ([]string, error) {
+	var values []string
+	for {
+		s.skipBlanks(true)
+		if s.eof() {
+			return nil, errors.New("unterminated array")
+		}
+		if s.peek() == ')' {
+			s.next()
+			return values, nil
+		}
+		value, err := s.word()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+}
+
+// word reads a word, quotes are removed and variables expanded.
+func (s *shellScanner) word() (string, error) {
+	var b strings.Builder
+	for !s.eof() {
+		switch c := s.peek(); c {
+		case ' ', '\t', '\n', ';', ')':
+			return b.String(), nil
+		case '(', '`', '|', '&', '<', '>':
+			return "", errors.Errorf("unsupported character %q", c)
+		case '\'':
+			s.next()
+			end := strings.IndexByte(s.src[s.pos:], '\'')
+			if end < 0 {
+				return "", errors.New("unterminated quote")
+			}
+			for i := 0; i < end; i++ {
+				b.WriteByte(s.next())
+			}
+			s.next()
+		case '"':
+			s.next()
+			err := s.doubleQuoted(&b)
+			if err != nil {
+				return "", err
+			}
+		case '\\':
+			s.next()
+			if s.eof() {
+				return "", errors.New("unterminated escape")
+			}
+			if c := s.next(); c != '\n' {
+				b.WriteByte(c)
+			}
+		case '$':
+			value, err := s.variable()
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+		default:
+			b.WriteByte(s.next())
+		}
+	}
+	return b.String(), nil
+}
+
+// doubleQuoted reads the end of a double quoted string.
+func (s *shellScanner) doubleQuoted(b *strings.Builder) error {
+	for !s.eof() {
+		switch c := s.peek(); c {
+		case '"':
+			s.next()
+			return nil
+		case '\\':
+			s.next()
+			if s.eof() {
+				return errors.New("unterminated quote")
+			}
+			switch c := s.next(); c {
+			case '"', '\\', '$', '`':
+				b.WriteByte(c)
+			case '\n':
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(c)
+			}
+		case '$':
+			value, err := s.variable()
+			if err != nil {
+				return err
+			}
+			b.WriteString(value)
+		case '`':
+			return errors.New("command substitutions aren't supported")
+		default:
+			b.WriteByte(s.next())
+		}
+	}
+	return errors.New("unterminated quote")
+}
+
+// variable reads a $name or ${name} expansion and returns its value.
+func (s *shellScanner) variable() (string, error) {
+	s.next()
+	braced := !s.eof() && s.peek() == '{'
+	if braced {
+		s.next()
+	}
+	name := s.identifier()
+	if name == "" {
+		if braced || !s.eof() && s.peek() == '(' {
+			return "", errors.New("only variable expansions are supported")
+		}
+		return "$", nil
+	}
+	if braced {
+		if s.eof() || s.peek() != '}' {
+			return "", errors.Errorf("unsupported expansion of %s", name)
+		}
+		s.next()
+	}
+	return s.vars[name], nil
+}
+
+// function skips the definition of a function, name() { ... }.
+func (s *shellScanner) function() error {
+	s.skipBlanks(false)
+	if !strings.HasPrefix(s.src[s.pos:], "()") {
+		return errors.New("only variables and functions are supported")
+	}
+	s.pos += 2
+	s.skipBlanks(true)
+	if s.eof() || s.peek() != '{' {
+		return errors.New("the body of functions must be enclosed in braces")
+	}
+	depth := 0
+	for !s.eof() {
+		switch c := s.peek(); c {
+		case '{':
+			depth++
+			s.next()
+		case '}':
+			depth--
+			s.next()
+			if depth == 0 {
+				return nil
+			}
+		case '#':
+			s.skipBlanks(false)
+		case '\'', '"':
+			// braces in strings don't count
+			s.next()
+			for !s.eof() && s.peek() != c {
+				if c == '"' && s.peek() == '\\' {
+					s.next()
+				}
+				if !s.eof() {
+					s.next()
+				}
+			}
+			if !s.eof() {
+				s.next()
+			}
+		case '\\':
+			s.next()
+			if !s.eof() {
+				s.next()
+			}
+		default:
+			s.next()
+		}
+	}
+	return errors.New("unterminated function")
+}
@@ -8,7 +8,6 @@ import (
 
 	"gopkg.in/yaml.v2"
 
-	"github.com/go-flutter-desktop/hover/internal/log"
 	"github.com/pkg/errors"
 )
 
@@ -18,6 +17,7 @@ type PubSpec struct {
 	Description  string
 	Version      string
 	Author       string
+	Homepage     string
 	Dependencies map[string]interface{}
 	Flutter      map[string]interface{}
 }
@@ -38,33 +38,30 @@ func (p PubSpec) GetVersion() string {
 	return p.Version
 }
 
-func (p PubSpec) GetAuthor() string {
+func (p PubSpec) GetAuthor() (string, error) {
 	if len(p.Author) == 0 {
 		u, err := user.Current()
 		if err != nil {
-			log.Errorf("Couldn't get current user: %v", err)
-			os.Exit(1)
+			return "", errors.Wrap(err, "couldn't get current user")
 		}
 		p.Author = u.Username
 		config.PrintMissingField("author", "pubspec.yaml", p.Author)
 	}
-	return p.Author
+	return p.Author, nil
 }
 
 var pubspec = PubSpec{}
 
 // GetPubSpec returns the working directory pubspec.yaml as a PubSpec
-func GetPubSpec() PubSpec {
+func GetPubSpec() (PubSpec, error) {
 	if pubspec.Name == "" {
 		pub, err := ReadPubSpecFile("pubspec.yaml")
 		if err != nil {
-			log.Errorf("%v", err)
-			log.Errorf("This command should be run from the root of your Flutter project.")
-			os.Exit(1)
+			return PubSpec{}, errors.Wrap(err, "this command should be run from the root of your Flutter project")
 		}
 		pubspec = *pub
 	}
-	return pubspec
+	return pubspec, nil
 }
 
 // ReadPubSpecFile reads a .yaml file at a path and return a correspond
@@ -0,0 +1,60 @@
+package rpm
+
+import (
+	"fmt"
+	"io"
+)
+
+// cpioHeader is the header of a file of a cpio archive in the SVR4 format
+// without checksums, the format of rpm payloads.
+type cpioHeader struct {
+	inode   int
+	mode    int
+	nlink   int
+	modTime int64
+	name    string
+}
+
+// cpioWriter writes a cpio archive, all the files are owned by root.
+type cpioWriter struct {
+	w    io.Writer
+	size int64
+}
+
+func (c *cpioWriter) write(data []byte) error {
+	n, err := c.w.Write(data)
+	c.size += int64(n)
+	return err
+}
+
+// pad aligns the archive to four bytes.
+func (c *cpioWriter) pad() error {
+	if c.size%4 == 0 {
+		return nil
+	}
+	return c.write(make([]byte, 4-c.size%4))
+}
+
+func (c *cpioWriter) writeFile(header cpioHeader, data []byte) error {
+	fields := fmt.Sprintf("070701%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x",
+		header.inode, header.mode, 0, 0, header.nlink, header.modTime, len(data),
+		0, 0, 0, 0, len(header.name)+1, 0)
+	err := c.write([]byte(fields + header.name + "\x00"))
+	if err != nil {
+		return err
+	}
+	err = c.pad()
+	if err != nil {
+		return err
+	}
+	err = c.write(data)
+	if err != nil {
+		return err
+	}
+	return c.pad()
+}
+
+// close writes the trailer of the archive.
+func (c *cpioWriter) close() error {
+	return c.writeFile(cpioHeader{nlink: 1, name: "TRAILER!!!"}, nil)
+}
@@ -0,0 +1,134 @@
+package rpm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+)
+
+// Types of the header entries.
+const (
+	typeInt16       = 3
+	typeInt32       = 4
+	typeString      = 6
+	typeBin         = 7
+	typeStringArray = 8
+	typeI18NString  = 9
+)
+
+// Regions of the signature and of the main header.
+const (
+	tagHeaderSignatures = 62
+	tagHeaderImmutable  = 63
+)
+
+var headerMagic = []byte{0x8e, 0xad, 0xe8, 0x01, 0, 0, 0, 0}
+
+// header is a header structure of a package: the signature or the main
+// header.
+type header struct {
+	region  int32
+	entries map[int32]headerEntry
+}
+
+type headerEntry struct {
+	typ   int32
+	count int32
+	data  []byte
+}
+
+func newHeader(region int32) *header {
+	return &header{region: region, entries: make(map[int32]headerEntry)}
+}
+
+func (h *header) addString(tag int32, value string) {
+	h.entries[tag] = headerEntry{typ: typeString, count: 1, data: append([]byte(value), 0)}
+}
+
+func (h *header) addI18NString(tag int32, value string) {
+	h.entries[tag] = headerEntry{typ: typeI18NString, count: 1, data: append([]byte(value), 0)}
+}
+
+// addStringArray adds an array of strings, empty arrays are left out.
+func (h *header) addStringArray(tag int32, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	var data []byte
+	for _, value := range values {
+		data = append(append(data, value...), 0)
+	}
+	h.entries[tag] = headerEntry{typ: typeStringArray, count: int32(len(values)), data: data}
+}
+
+func (h *header) addBin(tag int32, value []byte) {
+	h.entries[tag] = headerEntry{typ: typeBin, count: int32(len(value)), data: value}
+}
+
+// addInt32 adds an array of int32, empty arrays are left out.
+func (h *header) addInt32(tag int32, values ...int32) {
+	if len(values) == 0 {
+		return
+	}
+	data := make([]byte, 4*len(values))
+	for i, value := range values {
+		binary.BigEndian.PutUint32(data[4*i:], uint32(value))
+	}
+	h.entries[tag] = headerEntry{typ: typeInt32, count: int32(len(values)), data: data}
+}
+
+// addInt16 adds an array of int16, empty arrays are left out.
+func (h *header) addInt16(tag int32, values ...uint16) {
+	if len(values) == 0 {
+		return
+	}
+	data := make([]byte, 2*len(values))
+	for i, value := range values {
+		binary.BigEndian.PutUint16(data[2*i:], value)
+	}
+	h.entries[tag] = headerEntry{typ: typeInt16, count: int32(len(values)), data: data}
+}
+
+// bytes encodes the header. The entries are sorted by tag and preceded by
+// the region entry marking them as immutable.
+func (h *header) bytes() []byte {
+	tags := make([]int, 0, len(h.entries))
+	for tag := range h.entries {
+		tags = append(tags, int(tag))
+	}
+	sort.Ints(tags)
+
+	var store bytes.Buffer
+	offsets := make([]int32, len(tags))
+	for i, tag := range tags {
+		entry := h.entries[int32(tag)]
+		var alignment int
+		switch entry.typ {
+		case typeInt16:
+			alignment = 2
+		case typeInt32:
+			alignment = 4
+		}
+		if alignment > 0 && store.Len()%alignment != 0 {
+			store.Write(make([]byte, alignment-store.Len()%alignment))
+		}
+		offsets[i] = int32(store.Len())
+		store.Write(entry.data)
+	}
+	// the data of the region entry is an index entry pointing back to the
+	// start of the index
+	regionOffset := int32(store.Len())
+	indexCount := int32(len(tags) + 1)
+	binary.Write(&store, binary.BigEndian, []int32{h.region, typeBin, -16 * indexCount, 16})
+
+	var buf bytes.Buffer
+	buf.Write(headerMagic)
+	binary.Write(&buf, binary.BigEndian, []int32{indexCount, int32(store.Len())})
+	binary.Write(&buf, binary.BigEndian, []int32{h.region, typeBin, regionOffset, 16})
+	for i, tag := range tags {
+		entry := h.entries[int32(tag)]
+		binary.Write(&buf, binary.BigEndian, []int32{int32(tag), entry.typ, offsets[i], entry.count})
+	}
+	buf.Write(store.Bytes())
+	return buf.Bytes()
+}
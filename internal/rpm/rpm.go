@@ -0,0 +1,542 @@
+// Package rpm writes RPM packages without rpmbuild.
+package rpm
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ulikunitz/xz"
+)
+
+// Tags of the signature.
+const (
+	sigTagSHA1        = 269
+	sigTagSHA256      = 273
+	sigTagSize        = 1000
+	sigTagMD5         = 1004
+	sigTagPayloadSize = 1007
+)
+
+// Tags of the main header.
+const (
+	tagI18NTable         = 100
+	tagName              = 1000
+	tagVersion           = 1001
+	tagRelease           = 1002
+	tagEpoch             = 1003
+	tagSummary           = 1004
+	tagDescription       = 1005
+	tagBuildTime         = 1006
+	tagBuildHost         = 1007
+	tagSize              = 1009
+	tagVendor            = 1011
+	tagLicense           = 1014
+	tagPackager          = 1015
+	tagGroup             = 1016
+	tagURL               = 1020
+	tagOS                = 1021
+	tagArch              = 1022
+	tagFileSizes         = 1028
+	tagFileModes         = 1030
+	tagFileRDevs         = 1033
+	tagFileMTimes        = 1034
+	tagFileDigests       = 1035
+	tagFileLinkTos       = 1036
+	tagFileFlags         = 1037
+	tagFileUserName      = 1039
+	tagFileGroupName     = 1040
+	tagSourceRPM         = 1044
+	tagFileVerifyFlags   = 1045
+	tagProvideName       = 1047
+	tagRequireFlags      = 1048
+	tagRequireName       = 1049
+	tagRequireVersion    = 1050
+	tagConflictFlags     = 1053
+	tagConflictName      = 1054
+	tagConflictVersion   = 1055
+	tagObsoleteName      = 1090
+	tagFileDevices       = 1095
+	tagFileInodes        = 1096
+	tagFileLangs         = 1097
+	tagProvideFlags      = 1112
+	tagProvideVersion    = 1113
+	tagObsoleteFlags     = 1114
+	tagObsoleteVersion   = 1115
+	tagDirIndexes        = 1116
+	tagBaseNames         = 1117
+	tagDirNames          = 1118
+	tagPayloadFormat     = 1124
+	tagPayloadCompressor = 1125
+	tagPayloadFlags      = 1126
+	tagFileDigestAlgo    = 5011
+	tagPayloadDigest     = 5092
+	tagPayloadDigestAlgo = 5093
+)
+
+// scriptTags are the tags of the scriptlets and of their interpreter.
+var scriptTags = map[string][2]int32{
+	"pre":    {1023, 1085},
+	"post":   {1024, 1086},
+	"preun":  {1025, 1087},
+	"postun": {1026, 1088},
+}
+
+// Flags of the dependencies.
+const (
+	senseLess    = 1 << 1
+	senseGreater = 1 << 2
+	senseEqual   = 1 << 3
+	senseInterp  = 1 << 8
+	senseRPMLib  = 1 << 24
+)
+
+// Flags of the files.
+const (
+	fileConfig    = 1 << 0
+	fileDoc       = 1 << 1
+	fileNoReplace = 1 << 4
+)
+
+// digestAlgoSHA256 is the PGP hash algorithm number of SHA-256.
+const digestAlgoSHA256 = 8
+
+// rpmlibRequires are the features of rpm needed to install the packages.
+var rpmlibRequires = []Dependency{
+	{Name: "rpmlib(CompressedFileNames)", Operator: "<=", Version: "3.0.4-1"},
+	{Name: "rpmlib(FileDigests)", Operator: "<=", Version: "4.6.0-1"},
+	{Name: "rpmlib(PayloadFilesHavePrefix)", Operator: "<=", Version: "4.0-1"},
+	{Name: "rpmlib(PayloadIsXz)", Operator: "<=", Version: "5.2-1"},
+}
+
+// archNumbers are the numbers of the architectures in the lead.
+var archNumbers = map[string]int16{
+	"i386":    1,
+	"x86_64":  1,
+	"aarch64": 19,
+}
+
+// Options are the properties of a package that aren't read from the spec.
+type Options struct {
+	Arch      string
+	BuildTime time.Time
+	BuildHost string
+	// Executables are the installed paths of the files packaged with mode
+	// 0755, whatever their mode on the host.
+	Executables []string
+}
+
+// file is a packaged file.
+type file struct {
+	path       string
+	info       os.FileInfo
+	link       string
+	flags      int32
+	digest     string
+	executable bool
+}
+
+// Build writes the package of the staged tree root to w. The files to
+// package are the entries of the %files section of the spec, root being the
+// root of the installed system. They are owned by root and not writable by
+// group and others.
+func Build(root string, w io.Writer, spec Spec, opts Options) error {
+	files, err := collectFiles(root, spec.Files)
+	if err != nil {
+		return err
+	}
+	isExecutable := make(map[string]bool, len(opts.Executables))
+	for _, executable := range opts.Executables {
+		isExecutable[executable] = true
+	}
+	for _, f := range files {
+		f.executable = isExecutable[f.path]
+	}
+	payload, payloadSize, err := buildPayload(root, files)
+	if err != nil {
+		return err
+	}
+	payloadDigest := sha256.Sum256(payload)
+	h := mainHeader(spec, opts, files)
+	h.addStringArray(tagPayloadDigest, []string{hex.EncodeToString(payloadDigest[:])})
+	h.addInt32(tagPayloadDigestAlgo, digestAlgoSHA256)
+	headerBytes := h.bytes()
+
+	signature := newHeader(tagHeaderSignatures)
+	headerSHA1 := sha1.Sum(headerBytes)
+	headerSHA256 := sha256.Sum256(headerBytes)
+	headerAndPayloadMD5 := md5.New()
+	headerAndPayloadMD5.Write(headerBytes)
+	headerAndPayloadMD5.Write(payload)
+	signature.addString(sigTagSHA1, hex.EncodeToString(headerSHA1[:]))
+	signature.addString(sigTagSHA256, hex.EncodeToString(headerSHA256[:]))
+	signature.addInt32(sigTagSize, int32(len(headerBytes)+len(payload)))
+	signature.addBin(sigTagMD5, headerAndPayloadMD5.Sum(nil))
+	signature.addInt32(sigTagPayloadSize, int32(payloadSize))
+	signatureBytes := signature.bytes()
+	// the main header is aligned to 8 bytes
+	if len(signatureBytes)%8 != 0 {
+		signatureBytes = append(signatureBytes, make([]byte, 8-len(signatureBytes)%8)...)
+	}
+
+	for _, data := range [][]byte{lead(spec, opts.Arch), signatureBytes, headerBytes, payload} {
+		_, err = w.Write(data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lead returns the legacy lead of the package.
+func lead(spec Spec, arch string) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{0xed, 0xab, 0xee, 0xdb, 3, 0})
+	// binary package
+	binary.Write(&buf, binary.BigEndian, int16(0))
+	binary.Write(&buf, binary.BigEndian, archNumbers[arch])
+	name := make([]byte, 66)
+	copy(name[:65], spec.Name+"-"+spec.Version+"-"+spec.Release)
+	buf.Write(name)
+	// linux, header style signature
+	binary.Write(&buf, binary.BigEndian, []int16{1, 5})
+	buf.Write(make([]byte, 16))
+	return buf.Bytes()
+}
+
+// collectFiles returns the staged files matching the entries of the %files
+// section, sorted by path. The parent directories of the entries belong to
+// other packages, such as filesystem, and aren't packaged.
+func collectFiles(root string, entries []File) ([]*file, error) {
+	if len(entries) == 0 {
+		return nil, errors.New("the %files section is missing or empty")
+	}
+	files := make(map[string]*file)
+	for _, entry := range entries {
+		// entries may be glob patterns, like the ones of rpmbuild
+		matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(path.Clean(entry.Path))))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %%files entry %s", entry.Path)
+		}
+		if len(matches) == 0 {
+			return nil, errors.Errorf("the %%files entry %s isn't staged", entry.Path)
+		}
+		var flags int32
+		if entry.Config {
+			flags |= fileConfig
+		}
+		if entry.NoReplace {
+			flags |= fileNoReplace
+		}
+		if entry.Doc {
+			flags |= fileDoc
+		}
+		for _, stagedPath := range matches {
+			err = filepath.Walk(stagedPath, func(p string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				relativePath, err := filepath.Rel(root, p)
+				if err != nil {
+					return err
+				}
+				filePath := path.Join("/", filepath.ToSlash(relativePath))
+				// the root of the installed system belongs to the filesystem
+				if filePath != "/" {
+					files[filePath] = &file{path: filePath, info: info, flags: flags}
+				}
+				if info.IsDir() && entry.Dir {
+					return filepath.SkipDir
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to list the files of %s", entry.Path)
+			}
+		}
+	}
+
+	sorted := make([]*file, 0, len(files))
+	for _, f := range files {
+		stagedPath := filepath.Join(root, filepath.FromSlash(f.path))
+		switch {
+		case f.info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(stagedPath)
+			if err != nil {
+				return nil, err
+			}
+			f.link = link
+		case f.info.Mode().IsRegular():
+			data, err := ioutil.ReadFile(stagedPath)
+			if err != nil {
+				return nil, err
+			}
+			digest := sha256.Sum256(data)
+			f.digest = hex.EncodeToString(digest[:])
+		case !f.info.IsDir():
+			return nil, errors.Errorf("%s isn't a regular file, a directory or a symbolic link", f.path)
+		}
+		sorted = append(sorted, f)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].path < sorted[j].path })
+	return sorted, nil
+}
+
+// mode returns the mode of a file in the package, with the file type bits of
+// stat: 0755 for directories and executables, 0644 for other files.
+func (f *file) mode() uint16 {
+	switch {
+	case f.info.IsDir():
+		return 040755
+	case f.link != "":
+		return 0120777
+	case f.executable || f.info.Mode()&0111 != 0:
+		return 0100755
+	default:
+		return 0100644
+	}
+}
+
+// size returns the size of a file in the package, the size of a symbolic
+// link is the length of its target.
+func (f *file) size() int32 {
+	switch {
+	case f.info.IsDir():
+		return 0
+	case f.link != "":
+		return int32(len(f.link))
+	default:
+		return int32(f.info.Size())
+	}
+}
+
+// mainHeader returns the header describing the package and its files.
+func mainHeader(spec Spec, opts Options, files []*file) *header {
+	h := newHeader(tagHeaderImmutable)
+	h.addStringArray(tagI18NTable, []string{"C"})
+	h.addString(tagName, spec.Name)
+	if spec.Epoch != "" {
+		// the epoch is validated by ParseSpec
+		epoch, _ := strconv.Atoi(spec.Epoch)
+		h.addInt32(tagEpoch, int32(epoch))
+	}
+	h.addString(tagVersion, spec.Version)
+	h.addString(tagRelease, spec.Release)
+	h.addI18NString(tagSummary, spec.Summary)
+	h.addI18NString(tagDescription, spec.Description)
+	h.addInt32(tagBuildTime, int32(opts.BuildTime.Unix()))
+	h.addString(tagBuildHost, opts.BuildHost)
+	h.addString(tagLicense, spec.License)
+	for tag, value := range map[int32]string{
+		tagVendor:   spec.Vendor,
+		tagPackager: spec.Packager,
+		tagURL:      spec.URL,
+	} {
+		if value != "" {
+			h.addString(tag, value)
+		}
+	}
+	group := spec.Group
+	if group == "" {
+		group = "Unspecified"
+	}
+	h.addI18NString(tagGroup, group)
+	h.addString(tagOS, "linux")
+	h.addString(tagArch, opts.Arch)
+	h.addString(tagSourceRPM, spec.Name+"-"+spec.Version+"-"+spec.Release+".src.rpm")
+	h.addString(tagPayloadFormat, "cpio")
+	h.addString(tagPayloadCompressor, "xz")
+	h.addString(tagPayloadFlags, "2")
+
+	var (
+		totalSize   int32
+		sizes       []int32
+		modes       []uint16
+		rdevs       []uint16
+		mtimes      []int32
+		digests     []string
+		linkTos     []string
+		flags       []int32
+		verifyFlags []int32
+		users       []string
+		groups      []string
+		devices     []int32
+		inodes      []int32
+		langs       []string
+		dirIndexes  []int32
+		baseNames   []string
+		dirNames    []string
+	)
+	dirIndex := make(map[string]int32)
+	for i, f := range files {
+		totalSize += f.size()
+		sizes = append(sizes, f.size())
+		modes = append(modes, f.mode())
+		rdevs = append(rdevs, 0)
+		mtimes = append(mtimes, int32(f.info.ModTime().Unix()))
+		digests = append(digests, f.digest)
+		linkTos = append(linkTos, f.link)
+		flags = append(flags, f.flags)
+		verifyFlags = append(verifyFlags, -1)
+		users = append(users, "root")
+		groups = append(groups, "root")
+		devices = append(devices, 1)
+		inodes = append(inodes, int32(i+1))
+		langs = append(langs, "")
+
+		dir, base := path.Split(f.path)
+		index, ok := dirIndex[dir]
+		if !ok {
+			index = int32(len(dirNames))
+			dirIndex[dir] = index
+			dirNames = append(dirNames, dir)
+		}
+		dirIndexes = append(dirIndexes, index)
+		baseNames = append(baseNames, base)
+	}
+	h.addInt32(tagSize, totalSize)
+	h.addInt32(tagFileSizes, sizes...)
+	h.addInt16(tagFileModes, modes...)
+	h.addInt16(tagFileRDevs, rdevs...)
+	h.addInt32(tagFileMTimes, mtimes...)
+	h.addStringArray(tagFileDigests, digests)
+	h.addStringArray(tagFileLinkTos, linkTos)
+	h.addInt32(tagFileFlags, flags...)
+	h.addInt32(tagFileVerifyFlags, verifyFlags...)
+	h.addStringArray(tagFileUserName, users)
+	h.addStringArray(tagFileGroupName, groups)
+	h.addInt32(tagFileDevices, devices...)
+	h.addInt32(tagFileInodes, inodes...)
+	h.addStringArray(tagFileLangs, langs)
+	h.addInt32(tagDirIndexes, dirIndexes...)
+	h.addStringArray(tagBaseNames, baseNames)
+	h.addStringArray(tagDirNames, dirNames)
+	if len(files) > 0 {
+		h.addInt32(tagFileDigestAlgo, digestAlgoSHA256)
+	}
+
+	evr := spec.Version + "-" + spec.Release
+	if spec.Epoch != "" {
+		evr = spec.Epoch + ":" + evr
+	}
+	provides := append([]Dependency{{Name: spec.Name, Operator: "=", Version: evr}}, spec.Provides...)
+	addDependencies(h, tagProvideName, tagProvideFlags, tagProvideVersion, provides, 0)
+
+	requires := append([]Dependency(nil), spec.Requires...)
+	var requireFlags []int32
+	for range requires {
+		requireFlags = append(requireFlags, 0)
+	}
+	for _, dependency := range rpmlibRequires {
+		requires = append(requires, dependency)
+		requireFlags = append(requireFlags, senseRPMLib)
+	}
+	interpreters := make(map[string]bool)
+	for _, section := range []string{"pre", "post", "preun", "postun"} {
+		script, ok := spec.Scripts[section]
+		if !ok {
+			continue
+		}
+		h.addString(scriptTags[section][0], script.Body)
+		h.addString(scriptTags[section][1], script.Interpreter)
+		if !interpreters[script.Interpreter] {
+			interpreters[script.Interpreter] = true
+			requires = append(requires, Dependency{Name: script.Interpreter})
+			requireFlags = append(requireFlags, senseInterp)
+		}
+	}
+	addDependencies(h, tagRequireName, tagRequireFlags, tagRequireVersion, requires, requireFlags...)
+	addDependencies(h, tagConflictName, tagConflictFlags, tagConflictVersion, spec.Conflicts)
+	addDependencies(h, tagObsoleteName, tagObsoleteFlags, tagObsoleteVersion, spec.Obsoletes)
+	return h
+}
+
+// addDependencies adds a list of dependencies to the header. extraFlags are
+// or'ed with the flags of the operators of the dependencies.
+func addDependencies(h *header, nameTag, flagsTag, versionTag int32, dependencies []Dependency, extraFlags ...int32) {
+	var (
+		names    []string
+		flags    []int32
+		versions []string
+	)
+	for i, dependency := range dependencies {
+		var sense int32
+		if strings.Contains(dependency.Operator, "<") {
+			sense |= senseLess
+		}
+		if strings.Contains(dependency.Operator, ">") {
+			sense |= senseGreater
+		}
+		if strings.Contains(dependency.Operator, "=") {
+			sense |= senseEqual
+		}
+		if i < len(extraFlags) {
+			sense |= extraFlags[i]
+		}
+		names = append(names, dependency.Name)
+		flags = append(flags, sense)
+		versions = append(versions, dependency.Version)
+	}
+	h.addStringArray(nameTag, names)
+	h.addInt32(flagsTag, flags...)
+	h.addStringArray(versionTag, versions)
+}
+
+// buildPayload returns the xz compressed cpio archive of the files and its
+// uncompressed size.
+func buildPayload(root string, files []*file) ([]byte, int64, error) {
+	var buf bytes.Buffer
+	xzWriter, err := xz.NewWriter(&buf)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to create the xz writer")
+	}
+	cpioWriter := &cpioWriter{w: xzWriter}
+	for i, f := range files {
+		var data []byte
+		switch {
+		case f.link != "":
+			data = []byte(f.link)
+		case f.info.Mode().IsRegular():
+			data, err = ioutil.ReadFile(filepath.Join(root, filepath.FromSlash(f.path)))
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+		nlink := 1
+		if f.info.IsDir() {
+			nlink = 2
+		}
+		err = cpioWriter.writeFile(cpioHeader{
+			inode:   i + 1,
+			mode:    int(f.mode()),
+			nlink:   nlink,
+			modTime: f.info.ModTime().Unix(),
+			name:    "." + f.path,
+		}, data)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "failed to archive the package files")
+		}
+	}
+	err = cpioWriter.close()
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to archive the package files")
+	}
+	err = xzWriter.Close()
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to compress the package files")
+	}
+	return buf.Bytes(), cpioWriter.size, nil
+}
@@ -0,0 +1,194 @@
+package rpm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/ulikunitz/xz"
+
+	"github.com/go-flutter-desktop/hover/internal/testutil"
+)
+
+const testSpec = `Name: app
+Version: 1.0.0
+Release: 100
+Summary: An app
+License: MIT
+Requires: libGL >= 1.0, xdg-utils
+BuildRequires: gcc
+
+%description
+An app
+made with flutter
+
+%install
+cp -R $RPM_BUILD_DIR/app %{buildroot}
+
+%post -p /bin/bash
+echo installed
+
+%files
+%{_bindir}/app
+/usr/lib/%{name}/
+%config(noreplace) %{_sysconfdir}/app.conf
+%{_datadir}/icons/hicolor/*/apps/app.png
+`
+
+func TestParseSpec(t *testing.T) {
+	spec, err := ParseSpec([]byte(testSpec), "x86_64")
+	require.Equal(t, err, nil, "failed to parse the spec: %v", err)
+	require.Equal(t, "app", spec.Name)
+	require.Equal(t, "1.0.0", spec.Version)
+	require.Equal(t, "100", spec.Release)
+	require.Equal(t, "An app\nmade with flutter", spec.Description)
+	require.Equal(t, []Dependency{{Name: "libGL", Operator: ">=", Version: "1.0"}, {Name: "xdg-utils"}}, spec.Requires)
+	require.Equal(t, map[string]Script{"post": {Interpreter: "/bin/bash", Body: "echo installed\n"}}, spec.Scripts)
+	require.Equal(t, []File{
+		{Path: "/usr/bin/app"},
+		{Path: "/usr/lib/app/"},
+		{Path: "/etc/app.conf", Config: true, NoReplace: true},
+		{Path: "/usr/share/icons/hicolor/*/apps/app.png"},
+	}, spec.Files)
+
+	_, err = ParseSpec([]byte("Name: app\n%files\n%{_unknowndir}/app\n"), "x86_64")
+	require.NotEqual(t, err, nil, "unknown macros must fail")
+	_, err = ParseSpec([]byte("Name: app\nVersion: 1.0.0\n"), "x86_64")
+	require.NotEqual(t, err, nil, "missing tags must fail")
+	_, err = ParseSpec([]byte("Name: app\nVersion: 1.0.0\nRelease: 1\nSummary: An app\nLicense: MIT\n"), "x86_64")
+	require.NotEqual(t, err, nil, "a missing %files section must fail")
+}
+
+// buildTestPackage builds the package of testSpec from a staged tree where
+// no file is executable.
+func buildTestPackage(t *testing.T) []byte {
+	dir := testutil.Tree(t, map[string]testutil.File{
+		"usr/bin/app":        {Content: "#!/bin/sh\nexec /usr/lib/app/app\n"},
+		"usr/lib/app/app":    {Content: "binary"},
+		"usr/lib/app/app.so": {Content: "snapshot"},
+		"etc/app.conf":       {Content: "key=value\n"},
+		"usr/lib/unpackaged": {Content: "not in %files"},
+
+		"usr/share/icons/hicolor/16x16/apps/app.png": {Content: "16"},
+		"usr/share/icons/hicolor/32x32/apps/app.png": {Content: "32"},
+	})
+
+	spec, err := ParseSpec([]byte(testSpec), "x86_64")
+	require.Equal(t, err, nil, "failed to parse the spec: %v", err)
+	var buf bytes.Buffer
+	err = Build(dir, &buf, spec, Options{
+		Arch:        "x86_64",
+		BuildTime:   testutil.Epoch,
+		BuildHost:   "localhost",
+		Executables: []string{"/usr/bin/app", "/usr/lib/app/app"},
+	})
+	require.Equal(t, err, nil, "failed to build the package: %v", err)
+	return buf.Bytes()
+}
+
+func TestBuild(t *testing.T) {
+	data := buildTestPackage(t)
+	require.Equal(t, []byte{0xed, 0xab, 0xee, 0xdb}, data[:4])
+	require.Equal(t, "app-1.0.0-100", string(bytes.TrimRight(data[10:76], "\x00")))
+
+	signature, signatureLength := readHeader(t, data[96:])
+	headerData := data[96+(signatureLength+7)/8*8:]
+	h, headerLength := readHeader(t, headerData)
+	require.Equal(t, "app", h[tagName])
+	require.Equal(t, "x86_64", h[tagArch])
+	require.Equal(t, "An app\nmade with flutter", h[tagDescription])
+	require.Equal(t, "echo installed\n", h[scriptTags["post"][0]])
+	require.Equal(t, "/bin/bash", h[scriptTags["post"][1]])
+	require.Equal(t, []string{"/etc/", "/usr/bin/", "/usr/lib/", "/usr/lib/app/", "/usr/share/icons/hicolor/16x16/apps/", "/usr/share/icons/hicolor/32x32/apps/"}, h[tagDirNames])
+	require.Equal(t, []string{"app.conf", "app", "app", "app", "app.so", "app.png", "app.png"}, h[tagBaseNames])
+	require.Equal(t, uint32(len(headerData)), signature[sigTagSize])
+
+	xzReader, err := xz.NewReader(bytes.NewReader(headerData[headerLength:]))
+	require.Equal(t, err, nil, "failed to read the payload: %v", err)
+	payload, err := ioutil.ReadAll(xzReader)
+	require.Equal(t, err, nil, "failed to read the payload: %v", err)
+	require.Equal(t, uint32(len(payload)), signature[sigTagPayloadSize])
+	var names []string
+	modes := make(map[string]int)
+	for len(payload) > 0 {
+		require.Equal(t, "070701", string(payload[:6]))
+		field := func(i int) int {
+			value, err := strconv.ParseUint(string(payload[6+8*i:14+8*i]), 16, 32)
+			require.Equal(t, err, nil, "invalid cpio header: %v", err)
+			return int(value)
+		}
+		nameSize, fileSize := field(11), field(6)
+		names = append(names, string(payload[110:110+nameSize-1]))
+		modes[names[len(names)-1]] = field(1)
+		offset := (110 + nameSize + 3) / 4 * 4
+		payload = payload[(offset+fileSize+3)/4*4:]
+	}
+	require.Equal(t, []string{
+		"./etc/app.conf",
+		"./usr/bin/app",
+		"./usr/lib/app",
+		"./usr/lib/app/app",
+		"./usr/lib/app/app.so",
+		"./usr/share/icons/hicolor/16x16/apps/app.png",
+		"./usr/share/icons/hicolor/32x32/apps/app.png",
+		"TRAILER!!!",
+	}, names)
+	require.Equal(t, 0100755, modes["./usr/bin/app"])
+	require.Equal(t, 0100755, modes["./usr/lib/app/app"])
+	require.Equal(t, 0100644, modes["./usr/lib/app/app.so"])
+	require.Equal(t, 040755, modes["./usr/lib/app"])
+}
+
+func TestBuildQuery(t *testing.T) {
+	rpmPath, err := exec.LookPath("rpm")
+	if err != nil {
+		t.Skip("rpm is not installed")
+	}
+	packagePath := filepath.Join(t.TempDir(), "app-1.0.0-100.x86_64.rpm")
+	err = ioutil.WriteFile(packagePath, buildTestPackage(t), 0644)
+	require.Equal(t, err, nil, "failed to write the package: %v", err)
+
+	out, err := exec.Command(rpmPath, "-qp", "--queryformat", "[%{FILEMODES:perms} %{FILENAMES}\n]", packagePath).Output()
+	require.Equal(t, err, nil, "failed to query the package: %v", err)
+	require.Equal(t, strings.Join([]string{
+		"-rw-r--r-- /etc/app.conf",
+		"-rwxr-xr-x /usr/bin/app",
+		"drwxr-xr-x /usr/lib/app",
+		"-rwxr-xr-x /usr/lib/app/app",
+		"-rw-r--r-- /usr/lib/app/app.so",
+		"-rw-r--r-- /usr/share/icons/hicolor/16x16/apps/app.png",
+		"-rw-r--r-- /usr/share/icons/hicolor/32x32/apps/app.png",
+	}, "\n")+"\n", string(out))
+}
+
+// readHeader returns the values of the entries of a header, by tag, and the
+// length of the header.
+func readHeader(t *testing.T, data []byte) (map[int32]interface{}, int) {
+	require.Equal(t, headerMagic, data[:8])
+	indexCount := int(binary.BigEndian.Uint32(data[8:]))
+	storeLength := int(binary.BigEndian.Uint32(data[12:]))
+	store := data[16+16*indexCount : 16+16*indexCount+storeLength]
+	values := make(map[int32]interface{})
+	for i := 0; i < indexCount; i++ {
+		entry := data[16+16*i:]
+		tag := int32(binary.BigEndian.Uint32(entry))
+		typ := int32(binary.BigEndian.Uint32(entry[4:]))
+		offset := int32(binary.BigEndian.Uint32(entry[8:]))
+		count := int(binary.BigEndian.Uint32(entry[12:]))
+		switch typ {
+		case typeInt32:
+			values[tag] = binary.BigEndian.Uint32(store[offset:])
+		case typeString, typeI18NString:
+			values[tag] = strings.SplitN(string(store[offset:]), "\x00", 2)[0]
+		case typeStringArray:
+			values[tag] = strings.SplitN(string(store[offset:]), "\x00", count+1)[:count]
+		}
+	}
+	return values, 16 + 16*indexCount + storeLength
+}
@@ -0,0 +1,348 @@
+package rpm
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Spec is the metadata of a package, read from a spec file.
+type Spec struct {
+	Name        string
+	Epoch       string
+	Version     string
+	Release     string
+	Summary     string
+	Description string
+	License     string
+	Group       string
+	URL         string
+	Vendor      string
+	Packager    string
+	Requires    []Dependency
+	Provides    []Dependency
+	Conflicts   []Dependency
+	Obsoletes   []Dependency
+	// Scripts are the scriptlets of the package by section name: pre, post,
+	// preun and postun.
+	Scripts map[string]Script
+	// Files are the entries of the %files section, the only packaged files.
+	Files []File
+}
+
+// Dependency is a dependency on a capability, such as `glibc >= 2.17`.
+type Dependency struct {
+	Name string
+	// Operator is one of <, <=, =, >= and >. It is empty when any version
+	// satisfies the dependency.
+	Operator string
+	Version  string
+}
+
+// Script is a scriptlet run by rpm when the package is installed or removed.
+type Script struct {
+	Interpreter string
+	Body        string
+}
+
+// File is an entry of the %files section.
+type File struct {
+	// Path is the absolute path of the file on the installed system. The
+	// content of directories is packaged as well, unless Dir is set.
+	Path      string
+	Dir       bool
+	Config    bool
+	NoReplace bool
+	Doc       bool
+}
+
+// scriptSections are the sections of the spec holding scriptlets.
+var scriptSections = map[string]bool{
+	"pre":    true,
+	"post":   true,
+	"preun":  true,
+	"postun": true,
+}
+
+// buildSections are the sections of the spec only used by rpmbuild, the
+// files are taken from the staged tree instead.
+var buildSections = map[string]bool{
+	"prep":      true,
+	"build":     true,
+	"install":   true,
+	"check":     true,
+	"clean":     true,
+	"changelog": true,
+}
+
+// buildTags are the preamble tags only used by rpmbuild.
+var buildTags = map[string]bool{
+	"buildrequires":      true,
+	"buildconflicts":     true,
+	"buildarch":          true,
+	"buildarchitectures": true,
+	"buildroot":          true,
+	"exclusivearch":      true,
+	"excludearch":        true,
+	"autoreq":            true,
+	"autoprov":           true,
+	"autoreqprov":        true,
+}
+
+var macroRegexp = regexp.MustCompile(`%%|%\{(\??)([A-Za-z0-9_]+)\}`)
+
+// ParseSpec reads the metadata of a package built for arch from a spec file.
+// The sections building the package (%prep, %build, %install, ...) are
+// ignored, sub-packages and conditionals aren't supported.
+func ParseSpec(data []byte, arch string) (Spec, error) {
+	spec := Spec{Scripts: make(map[string]Script)}
+	macros := defaultMacros(arch)
+	expand := func(s string) (string, error) {
+		var err error
+		s = macroRegexp.ReplaceAllStringFunc(s, func(m string) string {
+			if m == "%%" {
+				return "%"
+			}
+			match := macroRegexp.FindStringSubmatch(m)
+			value, ok := macros[match[2]]
+			if !ok && match[1] == "" && err == nil {
+				err = errors.Errorf("unknown macro %s", m)
+			}
+			return value
+		})
+		return s, err
+	}
+
+	section := ""
+	var sectionLines []string
+	var scriptInterpreter string
+	endSection := func() {
+		body := strings.TrimSpace(strings.Join(sectionLines, "\n"))
+		switch {
+		case section == "description":
+			spec.Description = body
+		case scriptSections[section] && body != "":
+			spec.Scripts[section] = Script{Interpreter: scriptInterpreter, Body: body + "\n"}
+		}
+		sectionLines = nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 0 && strings.HasPrefix(fields[0], "%") && isSection(fields[0][1:]) {
+			endSection()
+			section = fields[0][1:]
+			if section == "package" {
+				return Spec{}, errors.Errorf("line %d: sub-packages aren't supported", lineNumber)
+			}
+			scriptInterpreter = "/bin/sh"
+			for i := 1; i < len(fields); i++ {
+				switch {
+				case fields[i] == "-p" && i+1 < len(fields) && scriptSections[section]:
+					i++
+					scriptInterpreter = fields[i]
+				default:
+					return Spec{}, errors.Errorf("line %d: unsupported option %s of %%%s", lineNumber, fields[i], section)
+				}
+			}
+			continue
+		}
+		if buildSections[section] {
+			continue
+		}
+
+		line, err := expand(scanner.Text())
+		if err != nil {
+			return Spec{}, errors.Wrapf(err, "line %d", lineNumber)
+		}
+		trimmed := strings.TrimSpace(line)
+		fields = strings.Fields(trimmed)
+		if len(fields) > 0 && (fields[0] == "%define" || fields[0] == "%global") {
+			if len(fields) < 3 {
+				return Spec{}, errors.Errorf("line %d: %s needs a name and a value", lineNumber, fields[0])
+			}
+			macros[fields[1]] = strings.Join(fields[2:], " ")
+			continue
+		}
+
+		switch {
+		case section == "":
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			err = spec.parseTag(trimmed, macros)
+		case section == "files":
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			err = spec.parseFile(fields)
+		case section == "description" || scriptSections[section]:
+			sectionLines = append(sectionLines, line)
+		}
+		if err != nil {
+			return Spec{}, errors.Wrapf(err, "line %d", lineNumber)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Spec{}, err
+	}
+	endSection()
+
+	for _, tag := range []struct{ name, value string }{
+		{"Name", spec.Name},
+		{"Version", spec.Version},
+		{"Release", spec.Release},
+		{"Summary", spec.Summary},
+		{"License", spec.License},
+	} {
+		if tag.value == "" {
+			return Spec{}, errors.Errorf("the %s tag is missing", tag.name)
+		}
+	}
+	if len(spec.Files) == 0 {
+		return Spec{}, errors.New("the %files section is missing or empty")
+	}
+	return spec, nil
+}
+
+func isSection(name string) bool {
+	return name == "description" || name == "files" || name == "package" || scriptSections[name] || buildSections[name]
+}
+
+// parseTag parses a tag of the preamble, the tags defining the name, epoch,
+// version and release are available as macros to the following lines.
+func (s *Spec) parseTag(line string, macros map[string]string) error {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return errors.Errorf("invalid preamble line '%s'", line)
+	}
+	tag := strings.ToLower(strings.TrimSpace(line[:colon]))
+	value := strings.TrimSpace(line[colon+1:])
+	// qualified dependencies, such as Requires(post), are plain dependencies
+	if paren := strings.Index(tag, "("); paren >= 0 {
+		tag = tag[:paren]
+	}
+	var err error
+	switch tag {
+	case "name":
+		s.Name = value
+		macros["name"] = value
+	case "epoch":
+		if _, err := strconv.ParseUint(value, 10, 31); err != nil {
+			return errors.Errorf("invalid epoch '%s'", value)
+		}
+		s.Epoch = value
+		macros["epoch"] = value
+	case "version":
+		s.Version = value
+		macros["version"] = value
+	case "release":
+		s.Release = value
+		macros["release"] = value
+	case "summary":
+		s.Summary = value
+	case "license":
+		s.License = value
+	case "group":
+		s.Group = value
+	case "url":
+		s.URL = value
+	case "vendor":
+		s.Vendor = value
+	case "packager":
+		s.Packager = value
+	case "requires":
+		s.Requires, err = appendDependencies(s.Requires, value)
+	case "provides":
+		s.Provides, err = appendDependencies(s.Provides, value)
+	case "conflicts":
+		s.Conflicts, err = appendDependencies(s.Conflicts, value)
+	case "obsoletes":
+		s.Obsoletes, err = appendDependencies(s.Obsoletes, value)
+	default:
+		if buildTags[tag] || strings.HasPrefix(tag, "source") || strings.HasPrefix(tag, "patch") {
+			return nil
+		}
+		return errors.Errorf("unsupported tag %s", line[:colon])
+	}
+	return err
+}
+
+// appendDependencies parses a list of dependencies separated by commas or
+// spaces.
+func appendDependencies(dependencies []Dependency, value string) ([]Dependency, error) {
+	fields := strings.Fields(strings.ReplaceAll(value, ",", " "))
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "<", "<=", "=", ">=", ">":
+			if len(dependencies) == 0 || dependencies[len(dependencies)-1].Operator != "" || i+1 == len(fields) {
+				return nil, errors.Errorf("invalid dependency list '%s'", value)
+			}
+			dependencies[len(dependencies)-1].Operator = fields[i]
+			dependencies[len(dependencies)-1].Version = fields[i+1]
+			i++
+		default:
+			dependencies = append(dependencies, Dependency{Name: fields[i]})
+		}
+	}
+	return dependencies, nil
+}
+
+// parseFile parses an entry of the %files section.
+func (s *Spec) parseFile(fields []string) error {
+	var file File
+	for _, field := range fields[:len(fields)-1] {
+		switch field {
+		case "%dir":
+			file.Dir = true
+		case "%config":
+			file.Config = true
+		case "%config(noreplace)":
+			file.Config = true
+			file.NoReplace = true
+		case "%doc":
+			file.Doc = true
+		default:
+			return errors.Errorf("unsupported %%files directive %s", field)
+		}
+	}
+	file.Path = fields[len(fields)-1]
+	if !strings.HasPrefix(file.Path, "/") {
+		return errors.Errorf("the %%files entry %s isn't an absolute path", file.Path)
+	}
+	s.Files = append(s.Files, file)
+	return nil
+}
+
+// defaultMacros returns the directory macros of rpm for an architecture.
+func defaultMacros(arch string) map[string]string {
+	libdir := "/usr/lib"
+	switch arch {
+	case "x86_64", "aarch64", "ppc64le", "s390x":
+		libdir = "/usr/lib64"
+	}
+	return map[string]string{
+		"_arch":          arch,
+		"_target_cpu":    arch,
+		"_prefix":        "/usr",
+		"_exec_prefix":   "/usr",
+		"_bindir":        "/usr/bin",
+		"_sbindir":       "/usr/sbin",
+		"_libexecdir":    "/usr/libexec",
+		"_libdir":        libdir,
+		"_includedir":    "/usr/include",
+		"_datadir":       "/usr/share",
+		"_mandir":        "/usr/share/man",
+		"_infodir":       "/usr/share/info",
+		"_docdir":        "/usr/share/doc",
+		"_sysconfdir":    "/etc",
+		"_localstatedir": "/var",
+	}
+}
@@ -0,0 +1,48 @@
+// Package testutil contains the helpers shared by the tests of the packages
+// writing archives and packages from a staged tree.
+package testutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Epoch dates the files of the test trees and the packages built by the
+// tests, 2020-06-01T00:00:00Z.
+var Epoch = time.Unix(1590969600, 0)
+
+// File is a file of a test tree.
+type File struct {
+	Content string
+	// Mode is the mode of the file whatever the umask, 0644 when zero.
+	Mode os.FileMode
+}
+
+// Tree writes a test tree, its files indexed by slash separated path, in a
+// temporary directory removed at the end of the test and returns the
+// directory. The files are dated with Epoch.
+func Tree(t *testing.T, files map[string]File) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, file := range files {
+		mode := file.Mode
+		if mode == 0 {
+			mode = 0644
+		}
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		require.Equal(t, err, nil, "failed to create directory: %v", err)
+		err = ioutil.WriteFile(path, []byte(file.Content), mode)
+		require.Equal(t, err, nil, "failed to write file: %v", err)
+		err = os.Chmod(path, mode)
+		require.Equal(t, err, nil, "failed to change the mode: %v", err)
+		err = os.Chtimes(path, Epoch, Epoch)
+		require.Equal(t, err, nil, "failed to change the modification time: %v", err)
+	}
+	return dir
+}
@@ -0,0 +1,194 @@
+package hover
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/hover/internal/build"
+	"github.com/go-flutter-desktop/hover/internal/enginecache"
+	"github.com/go-flutter-desktop/hover/internal/flutterversion"
+	"github.com/go-flutter-desktop/hover/internal/log"
+)
+
+const aotSnapshotStep = "aot-snapshot"
+
+// aotSnapshotFilename is the name of the AOT snapshot placed next to the
+// executable.
+const aotSnapshotFilename = "app.so"
+
+// aotVMArgument points the engine to the AOT snapshot. The relative path is
+// resolved against the executable directory by the main.go of the project.
+const aotVMArgument = "--aot-shared-library-name=" + aotSnapshotFilename
+
+// aot returns true when the dart code of a target OS is AOT compiled.
+func (b *builder) aot(targetOS string) bool {
+	if b.opts.AOT != nil {
+		return *b.opts.AOT
+	}
+	return b.profile.ForOS(targetOS).GetAOT()
+}
+
+// engineMode returns the runtime mode of the engine used for a target OS. JIT
+// builds always use the debug engine.
+func (b *builder) engineMode(targetOS string) string {
+	if !b.aot(targetOS) {
+		return enginecache.ModeDebug
+	}
+	return b.profile.ForOS(targetOS).Mode
+}
+
+// checkAOT returns an error when the dart code of a target OS cannot be AOT
+// compiled.
+func (b *builder) checkAOT(targetOS string) error {
+	if !b.aot(targetOS) {
+		return nil
+	}
+	mode := b.engineMode(targetOS)
+	err := enginecache.ValidateMode(mode)
+	if err != nil {
+		return err
+	}
+	if mode == enginecache.ModeDebug {
+		return errors.Errorf("AOT builds need a profile with the profile or release mode, profile '%s' uses the debug mode", b.opts.Profile)
+	}
+	if b.opts.SkipFlutterBuildBundle {
+		return nil
+	}
+	// gen_snapshot only produces snapshots for the platform it runs on
+	if targetOS != runtime.GOOS || b.opts.Arch != runtime.GOARCH {
+		return errors.Errorf("AOT builds for %s/%s cannot be compiled on %s/%s, gen_snapshot can only target the host platform", targetOS, b.opts.Arch, runtime.GOOS, runtime.GOARCH)
+	}
+	mainPath := filepath.Join(build.BuildPath, "cmd", "main.go")
+	mainSource, err := ioutil.ReadFile(mainPath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", mainPath)
+	}
+	if !strings.Contains(string(mainSource), "resolveVMArguments") {
+		return errors.Errorf("%s doesn't load AOT snapshots, copy the resolveVMArguments function of the hover main.go template into it", mainPath)
+	}
+	return nil
+}
+
+// buildAOTSnapshot compiles the dart code of the application to the
+// app.so snapshot: the frontend server of the flutter SDK compiles an AOT
+// kernel that gen_snapshot compiles to native code. The JIT kernel is
+// removed from the flutter bundle.
+func (b *builder) buildAOTSnapshot(ctx context.Context, targetOS string) error {
+	mode := b.engineMode(targetOS)
+	outputDirectoryPath, err := build.OutputDirectoryPath(targetOS, b.opts.Arch)
+	if err != nil {
+		return err
+	}
+	snapshotPath := filepath.Join(outputDirectoryPath, aotSnapshotFilename)
+
+	engineCachePath, err := b.ensureEngine(ctx, targetOS)
+	if err != nil {
+		return err
+	}
+	inputs, err := b.flutterBundleInputs(targetOS)
+	if err != nil {
+		return err
+	}
+	inputs.AddValue("mode", mode)
+	err = inputs.AddFile(filepath.Join(engineCachePath, "version"))
+	if err != nil {
+		return errors.Wrap(err, "failed to hash the AOT snapshot inputs")
+	}
+	if b.stepUpToDate(aotSnapshotStep, targetOS, inputs, snapshotPath) {
+		log.Infof("Skipping the AOT snapshot, its inputs didn't change since the last build")
+		return removeJITKernel(outputDirectoryPath)
+	}
+
+	flutterRoot, err := flutterversion.FlutterRoot()
+	if err != nil {
+		return err
+	}
+	dartBin := filepath.Join(flutterRoot, "bin", "cache", "dart-sdk", "bin", "dart")
+	if runtime.GOOS == "windows" {
+		dartBin += ".exe"
+	}
+	sdkRoot := filepath.Join(flutterRoot, "bin", "cache", "artifacts", "engine", "common", "flutter_patched_sdk")
+	if mode == enginecache.ModeRelease {
+		sdkRoot += "_product"
+	}
+	aotDirectoryPath := filepath.Join(build.BuildPath, "build", "aot", build.TargetDirectoryName(build.FlavorDirectoryName(targetOS), b.opts.Arch))
+	err = os.MkdirAll(aotDirectoryPath, 0775)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create directory %s", aotDirectoryPath)
+	}
+	kernelPath := filepath.Join(aotDirectoryPath, "kernel_snapshot.dill")
+
+	kernelArgs := []string{
+		filepath.Join(flutterRoot, "bin", "cache", "dart-sdk", "bin", "snapshots", "frontend_server.dart.snapshot"),
+		"--sdk-root", sdkRoot + string(filepath.Separator),
+		"--target=flutter",
+		"--aot", "--tfa",
+		"-Ddart.vm.product=" + strconv.FormatBool(mode == enginecache.ModeRelease),
+		"-Ddart.vm.profile=" + strconv.FormatBool(mode == enginecache.ModeProfile),
+	}
+	for _, define := range b.opts.DartDefines {
+		kernelArgs = append(kernelArgs, "-D"+define)
+	}
+	kernelArgs = append(kernelArgs,
+		"--packages", ".packages",
+		"--output-dill", kernelPath,
+		b.opts.FlutterTarget,
+	)
+	cmdKernel := exec.CommandContext(ctx, dartBin, kernelArgs...)
+	cmdKernel.Stderr = os.Stderr
+	cmdKernel.Stdout = log.CommandOutput()
+	log.Infof("Compiling the AOT kernel")
+	err = cmdKernel.Run()
+	if err != nil {
+		return errors.Wrap(err, "AOT kernel compilation failed")
+	}
+
+	cmdGenSnapshot := exec.CommandContext(ctx, enginecache.GenSnapshotPath(targetOS, b.opts.Arch, mode, b.opts.CachePath),
+		"--deterministic",
+		"--snapshot_kind=app-aot-elf",
+		"--elf="+snapshotPath,
+		"--strip",
+		kernelPath,
+	)
+	cmdGenSnapshot.Stderr = os.Stderr
+	cmdGenSnapshot.Stdout = log.CommandOutput()
+	log.Infof("Compiling the %s AOT snapshot", mode)
+	err = cmdGenSnapshot.Run()
+	if err != nil {
+		return errors.Wrap(err, "gen_snapshot failed")
+	}
+	err = removeJITKernel(outputDirectoryPath)
+	if err != nil {
+		return err
+	}
+	b.recordStep(aotSnapshotStep, targetOS, inputs)
+	return nil
+}
+
+// removeJITKernel removes the JIT kernel from the flutter bundle, AOT builds
+// don't ship the dart code.
+func removeJITKernel(outputDirectoryPath string) error {
+	err := os.RemoveAll(filepath.Join(outputDirectoryPath, "flutter_assets", "kernel_blob.bin"))
+	if err != nil {
+		return errors.Wrap(err, "failed to remove the JIT kernel from the flutter bundle")
+	}
+	return nil
+}
+
+// removeAOTSnapshot removes the snapshot of a previous AOT build, so that JIT
+// builds don't pick it up.
+func removeAOTSnapshot(outputDirectoryPath string) error {
+	err := os.RemoveAll(filepath.Join(outputDirectoryPath, aotSnapshotFilename))
+	if err != nil {
+		return errors.Wrap(err, "failed to remove the AOT snapshot")
+	}
+	return nil
+}
@@ -0,0 +1,962 @@
+package hover
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/otiai10/copy"
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/hover/internal/androidmanifest"
+	"github.com/go-flutter-desktop/hover/internal/build"
+	"github.com/go-flutter-desktop/hover/internal/buildmanifest"
+	"github.com/go-flutter-desktop/hover/internal/config"
+	"github.com/go-flutter-desktop/hover/internal/enginecache"
+	"github.com/go-flutter-desktop/hover/internal/fileutils"
+	"github.com/go-flutter-desktop/hover/internal/flutterversion"
+	"github.com/go-flutter-desktop/hover/internal/hooks"
+	"github.com/go-flutter-desktop/hover/internal/icons"
+	"github.com/go-flutter-desktop/hover/internal/log"
+	"github.com/go-flutter-desktop/hover/internal/packaging"
+	"github.com/go-flutter-desktop/hover/internal/pubspec"
+	"github.com/go-flutter-desktop/hover/internal/versioncheck"
+)
+
+var dotSlash = string([]byte{'.', filepath.Separator})
+
+// crossCompilers contains the C compilers used to cross-compile from a linux
+// host, indexed by GOOS/GOARCH. They are the compilers installed in the
+// goflutter/hover docker image, darwin/arm64 and windows/arm64 can only be
+// built on a host of that platform.
+var crossCompilers = map[string]string{
+	"darwin/amd64":  "o32-clang",
+	"linux/amd64":   "x86_64-linux-gnu-gcc",
+	"linux/arm64":   "aarch64-linux-gnu-gcc",
+	"windows/amd64": "x86_64-w64-mingw32-gcc",
+}
+
+// crossStripBinNames contains the strip binaries used to strip the engine of
+// linux builds that target a foreign architecture, indexed by GOARCH.
+var crossStripBinNames = map[string]string{
+	"amd64": "x86_64-linux-gnu-strip",
+	"arm64": "aarch64-linux-gnu-strip",
+}
+
+// BuildOptions configures a build. Empty or nil fields fall back to the value
+// set in hover.yaml, or otherwise to the hover default.
+type BuildOptions struct {
+	// Targets lists the OSs and packaging formats to build, e.g. "linux" or
+	// "linux-deb". Every OS is compiled once, its packaging formats are then
+	// packaged concurrently. See Targets() for the list of valid targets.
+	Targets []string
+	// Arch is the GOARCH to build for, amd64 or arm64.
+	Arch string
+	// Flavor is the name of the flavor of hover.yaml to build. Its outputs
+	// are placed in directories suffixed with its name.
+	Flavor string
+	// FlutterTarget is the main entry-point file of the application.
+	FlutterTarget string
+	// GoFlutterBranch is the 'go-flutter' version to use, e.g. @master or
+	// @v0.20.0. When set, go-flutter is upgraded before the build.
+	GoFlutterBranch string
+	// CachePath is the directory used to cache dependencies such as the
+	// Flutter engine, defaults to the user cache directory.
+	CachePath string
+	// OpenGL is the OpenGL version go-flutter is built for, "none" disables
+	// texture support. It overrides the OpenGL version of the profile.
+	OpenGL string
+	// EngineVersion is the Flutter engine version to use, defaults to the
+	// engine version of the installed Flutter SDK.
+	EngineVersion string
+	// VersionNumber overrides the version number of pubspec.yaml.
+	VersionNumber string
+	// DartDefines are compile-time variables of the dart code, as
+	// KEY=VALUE, read with String.fromEnvironment. They override the
+	// dart-defines of hover.yaml.
+	DartDefines []string
+	// FlutterBuildArgs are extra arguments of `flutter build bundle`, they are
+	// appended to the flutter-build-args of hover.yaml.
+	FlutterBuildArgs []string
+	// VMArguments are passed to the Dart VM of the built application.
+	VMArguments []string
+	// Profile is the name of the build profile, a built-in profile (debug,
+	// profile or release) or one of the profiles of hover.yaml. Defaults to
+	// release.
+	Profile string
+	// AOT compiles the dart code to a native snapshot with gen_snapshot, it
+	// overrides the aot setting of the profile. AOT builds can only target
+	// the host platform. Defaults to the aot setting of the profile when nil.
+	AOT *bool
+	// Docker runs the go build and the packaging in a docker container. The
+	// Flutter bundle is always built locally. Defaults to the docker setting
+	// when nil.
+	Docker *bool
+	// Reproducible makes the build outputs bit-for-bit deterministic: the
+	// go build drops the paths of the build machine, and the packaged files
+	// get normalized modes and are dated with SOURCE_DATE_EPOCH, or else the
+	// date of the last git commit.
+	Reproducible bool
+	// EmbedBuildInfo sets the build info, without the hashes of the produced
+	// files, in the buildInfo variable of the main package. Defaults to the
+	// embed-build-info setting when nil.
+	EmbedBuildInfo *bool
+	// Force cleans the output directories and reruns every build step, even
+	// when its inputs didn't change since the last build.
+	Force bool
+	// SkipEngineDownload skips the download of the Flutter engine.
+	SkipEngineDownload bool
+	// SkipFlutterBuildBundle skips the 'flutter build bundle' step.
+	SkipFlutterBuildBundle bool
+	// SkipGoBuild skips the go build and the packaging steps.
+	SkipGoBuild bool
+}
+
+// withDefaults returns a copy of the options where empty or nil fields are set
+// from the settings resolved by config.Resolve: the HOVER_* environment
+// variables, hover.yaml, the user config or the hover defaults.
+func (opts BuildOptions) withDefaults() (BuildOptions, error) {
+	if opts.Arch == "" {
+		arch, err := config.Resolve(config.SettingArch)
+		if err != nil {
+			return opts, err
+		}
+		opts.Arch = arch.Value
+	}
+	err := build.ValidateArch(opts.Arch)
+	if err != nil {
+		return opts, err
+	}
+	if opts.FlutterTarget == "" {
+		flutterTarget, err := config.Resolve(config.SettingTarget)
+		if err != nil {
+			return opts, err
+		}
+		opts.FlutterTarget = flutterTarget.Value
+	}
+	if opts.CachePath == "" {
+		opts.CachePath = enginecache.DefaultCachePath()
+	}
+	if opts.CachePath == "" {
+		return opts, errors.New("missing cache path, cannot continue")
+	}
+	if opts.EmbedBuildInfo == nil {
+		embedBuildInfo, err := config.Resolve(config.SettingEmbedBuildInfo)
+		if err != nil {
+			return opts, err
+		}
+		opts.EmbedBuildInfo = boolPtr(embedBuildInfo.Bool())
+	}
+	if opts.Docker == nil {
+		docker, err := config.Resolve(config.SettingDocker)
+		if err != nil {
+			return opts, err
+		}
+		opts.Docker = boolPtr(docker.Bool())
+	}
+	if opts.Profile == "" {
+		opts.Profile = config.BuildProfileDefault
+	}
+	if opts.EngineVersion == "" {
+		engineVersion, err := config.Resolve(config.SettingEngineVersion)
+		if err != nil {
+			return opts, err
+		}
+		if engineVersion.Value != "" {
+			log.Warnf("changing the engine version can lead to undesirable behavior")
+		}
+		opts.EngineVersion = engineVersion.Value
+	}
+	if opts.VersionNumber == "" {
+		pubSpec, err := pubspec.GetPubSpec()
+		if err != nil {
+			return opts, err
+		}
+		opts.VersionNumber = pubSpec.GetVersion()
+	}
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return opts, err
+	}
+	opts.DartDefines, err = mergeDartDefines(cfg.DartDefines, opts.DartDefines)
+	if err != nil {
+		return opts, err
+	}
+	opts.FlutterBuildArgs = append(append([]string{}, cfg.FlutterBuildArgs...), opts.FlutterBuildArgs...)
+	return opts, nil
+}
+
+// mergeDartDefines returns the dart defines of hover.yaml overridden by the
+// KEY=VALUE dart defines of the options, sorted by key.
+func mergeDartDefines(configDefines map[string]string, defines []string) ([]string, error) {
+	merged := make(map[string]string)
+	for key, value := range configDefines {
+		merged[key] = value
+	}
+	for _, define := range defines {
+		i := strings.Index(define, "=")
+		if i <= 0 {
+			return nil, errors.Errorf("invalid dart define '%s', use KEY=VALUE", define)
+		}
+		merged[define[:i]] = define[i+1:]
+	}
+	keys := make([]string, 0, len(merged))
+	for key := range merged {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	result := make([]string, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, key+"="+merged[key])
+	}
+	return result, nil
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// BuildResult contains the outcome of a build.
+type BuildResult struct {
+	// Arch is the GOARCH the targets were built for.
+	Arch string
+	// Targets contains the result of every built target, in the order they
+	// were requested.
+	Targets []TargetResult
+}
+
+// Failed returns the number of targets that failed to build.
+func (r *BuildResult) Failed() int {
+	var failed int
+	for _, target := range r.Targets {
+		if target.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
+
+func (r *BuildResult) err() error {
+	if failed := r.Failed(); failed > 0 {
+		return errors.Errorf("%d of %d targets failed", failed, len(r.Targets))
+	}
+	return nil
+}
+
+// Build compiles the targets listed in opts and packages them. A failure to
+// build an OS fails its targets and a packaging failure fails its target, the
+// other targets are still built: the failures are reported in the returned
+// BuildResult and in the returned error.
+func Build(ctx context.Context, opts BuildOptions) (*BuildResult, error) {
+	err := assertProject()
+	if err != nil {
+		return nil, err
+	}
+	err = config.SelectFlavor(opts.Flavor)
+	if err != nil {
+		return nil, err
+	}
+	opts, err = opts.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	profile, err := cfg.GetProfile(opts.Profile)
+	if err != nil {
+		return nil, err
+	}
+	pubSpec, err := pubspec.GetPubSpec()
+	if err != nil {
+		return nil, err
+	}
+	openGL, err := config.Resolve(config.SettingOpenGL)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Reproducible {
+		restore, err := setupReproducible(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer restore()
+	}
+	targets, err := parseTargets(opts.Targets)
+	if err != nil {
+		return nil, err
+	}
+	for _, target := range targets {
+		err = target.packagingTask.AssertInitialized()
+		if err != nil {
+			return nil, err
+		}
+		if !*opts.Docker && !opts.SkipGoBuild {
+			err = target.packagingTask.CheckSupported()
+			if err != nil {
+				return nil, errors.Errorf("%v, use the docker option to package %s in a container", err, target.name)
+			}
+		}
+	}
+	b := &builder{
+		opts:          opts,
+		profile:       profile,
+		config:        cfg,
+		pubSpec:       pubSpec,
+		openGLSetting: openGL,
+		manifest:      buildmanifest.Open(filepath.Join(build.BuildPath, "build", "build-manifest.json")),
+	}
+	for _, targetOS := range targetOSs(targets) {
+		err = b.checkAOT(targetOS)
+		if err != nil {
+			return nil, err
+		}
+		err = b.checkCrossCompiler(targetOS)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !opts.SkipFlutterBuildBundle {
+		_, err = os.Stat(opts.FlutterTarget)
+		if err != nil {
+			return nil, errors.Wrapf(err, "target file \"%s\" not found", opts.FlutterTarget)
+		}
+	}
+
+	results := make(map[string]TargetResult)
+	var builtTargets []*target
+	for _, targetOS := range targetOSs(targets) {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		var osTargets []*target
+		for _, target := range targets {
+			if target.targetOS == targetOS {
+				osTargets = append(osTargets, target)
+			}
+		}
+		err = b.buildOS(ctx, targetOS, osTargets)
+		if err != nil {
+			// the targets of the other OSs are still built
+			for _, target := range osTargets {
+				results[target.name] = TargetResult{Target: target.name, Err: err}
+			}
+			continue
+		}
+		if opts.SkipGoBuild {
+			continue
+		}
+		if *opts.Docker {
+			for _, target := range osTargets {
+				artifact, err := dockerArtifact(target, opts.Arch)
+				if err == nil {
+					log.Artifact(target.name, artifact)
+				}
+				results[target.name] = TargetResult{Target: target.name, Artifact: artifact, Err: err}
+			}
+			continue
+		}
+		builtTargets = append(builtTargets, osTargets...)
+	}
+	if len(builtTargets) > 0 {
+		for _, targetResult := range packTargets(ctx, builtTargets, opts.VersionNumber, opts.Arch) {
+			results[targetResult.Target] = targetResult
+		}
+	}
+
+	result := &BuildResult{Arch: opts.Arch}
+	for _, target := range targets {
+		if targetResult, ok := results[target.name]; ok {
+			result.Targets = append(result.Targets, targetResult)
+		}
+	}
+	return result, result.err()
+}
+
+// buildOS builds the flutter bundle and the go binary of a target OS, in
+// docker the go build also packages the targets of the OS.
+func (b *builder) buildOS(ctx context.Context, targetOS string, osTargets []*target) error {
+	if !b.opts.SkipFlutterBuildBundle {
+		if b.opts.Force {
+			err := cleanBuildOutputsDir(targetOS, b.opts.Arch)
+			if err != nil {
+				return err
+			}
+		}
+		done := log.Step(flutterBundleStep, targetOS)
+		err := b.buildFlutterBundle(ctx, targetOS)
+		done(err)
+		if err != nil {
+			return err
+		}
+		if b.aot(targetOS) {
+			done = log.Step(aotSnapshotStep, targetOS)
+			err = b.buildAOTSnapshot(ctx, targetOS)
+			done(err)
+		} else {
+			var outputDirectoryPath string
+			outputDirectoryPath, err = build.OutputDirectoryPath(targetOS, b.opts.Arch)
+			if err == nil {
+				err = removeAOTSnapshot(outputDirectoryPath)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if b.opts.SkipGoBuild {
+		return nil
+	}
+	done := log.Step(goBuildStep, targetOS)
+	var err error
+	if *b.opts.Docker {
+		var packagingTasks []packaging.Task
+		for _, target := range osTargets {
+			packagingTasks = append(packagingTasks, target.packagingTask)
+		}
+		err = b.dockerBuild(ctx, targetOS, packagingTasks)
+	} else {
+		err = b.buildGoBinary(ctx, targetOS)
+	}
+	done(err)
+	return err
+}
+
+// builder runs the build steps of a Build.
+type builder struct {
+	opts    BuildOptions
+	profile config.Profile
+	config  config.Config
+	pubSpec pubspec.PubSpec
+	// openGLSetting is the resolved opengl setting, see openGL.
+	openGLSetting config.Value
+	manifest      *buildmanifest.Manifest
+	// flutterSDKVersion is the version of the Flutter SDK, see flutterVersion.
+	flutterSDKVersion *flutterversion.Version
+}
+
+// openGL returns the OpenGL version go-flutter is built for: the OpenGL
+// option, or else the one set by an environment variable, or else the one of
+// the profile, or else the one of the config files.
+func (b *builder) openGL(profile config.Profile) string {
+	if b.opts.OpenGL != "" {
+		return b.opts.OpenGL
+	}
+	if profile.OpenGL != "" && b.openGLSetting.Source != config.SourceEnv {
+		return profile.OpenGL
+	}
+	return b.openGLSetting.Value
+}
+
+func cleanBuildOutputsDir(targetOS, targetArch string) error {
+	outputDirectoryPath, err := build.OutputDirectoryPath(targetOS, targetArch)
+	if err != nil {
+		return err
+	}
+	err = os.RemoveAll(outputDirectoryPath)
+	log.Printf("Cleaning the build directory")
+	if err != nil {
+		return errors.Wrapf(err, "failed to remove output directory %s", outputDirectoryPath)
+	}
+	err = os.MkdirAll(outputDirectoryPath, 0775)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create output directory %s", outputDirectoryPath)
+	}
+	return nil
+}
+
+func (b *builder) buildFlutterBundle(ctx context.Context, targetOS string) error {
+	// the hook runs before the inputs are hashed, it may generate dart code
+	err := b.runHook(ctx, hooks.PreFlutterBundle, b.config.Hooks.PreFlutterBundle, targetOS)
+	if err != nil {
+		return err
+	}
+	outputDirectoryPath, err := build.OutputDirectoryPath(targetOS, b.opts.Arch)
+	if err != nil {
+		return err
+	}
+	flutterAssetsPath := filepath.Join(outputDirectoryPath, "flutter_assets")
+	inputs, err := b.flutterBundleInputs(targetOS)
+	if err != nil {
+		return err
+	}
+	if b.stepUpToDate(flutterBundleStep, targetOS, inputs, flutterAssetsPath) {
+		log.Infof("Skipping the flutter bundle, its inputs didn't change since the last build")
+		return nil
+	}
+	err = os.RemoveAll(flutterAssetsPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove the old flutter bundle")
+	}
+
+	var flutterBuildBundleArgs = []string{
+		"build", "bundle",
+		"--asset-dir", flutterAssetsPath,
+		"--target", b.opts.FlutterTarget,
+	}
+	if b.profile.ForOS(targetOS).GetTrackWidgetCreation() {
+		flutterBuildBundleArgs = append(flutterBuildBundleArgs, "--track-widget-creation")
+	}
+	for _, define := range b.opts.DartDefines {
+		flutterBuildBundleArgs = append(flutterBuildBundleArgs, "--dart-define="+define)
+	}
+	flutterBuildBundleArgs = append(flutterBuildBundleArgs, b.opts.FlutterBuildArgs...)
+	flutterBin, err := build.FlutterBin()
+	if err != nil {
+		return err
+	}
+	cmdFlutterBuildBundle := exec.CommandContext(ctx, flutterBin, flutterBuildBundleArgs...)
+	cmdFlutterBuildBundle.Stderr = os.Stderr
+	cmdFlutterBuildBundle.Stdout = log.CommandOutput()
+
+	log.Infof("Building flutter bundle")
+	err = cmdFlutterBuildBundle.Run()
+	if err != nil {
+		return errors.Wrap(err, "flutter build failed")
+	}
+	b.recordStep(flutterBundleStep, targetOS, inputs)
+	return nil
+}
+
+func (b *builder) buildGoBinary(ctx context.Context, targetOS string) error {
+	targetArch := b.opts.Arch
+	vmArguments := b.opts.VMArguments
+	if vmArgsFromEnv := os.Getenv("HOVER_IN_DOCKER_BUILD_VMARGS"); len(vmArgsFromEnv) > 0 {
+		vmArguments = append(vmArguments, strings.Split(vmArgsFromEnv, ",")...)
+	}
+	engineCachePath, err := b.ensureEngine(ctx, targetOS)
+	if err != nil {
+		return err
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return errors.Wrap(err, "failed to get working dir")
+	}
+
+	err = b.runHook(ctx, hooks.PreGoBuild, b.config.Hooks.PreGoBuild, targetOS)
+	if err != nil {
+		return err
+	}
+
+	if b.opts.GoFlutterBranch == "" {
+		currentTag, err := versioncheck.CurrentGoFlutterTag(filepath.Join(wd, build.BuildPath))
+		if err != nil {
+			return err
+		}
+
+		if currentTag == "" {
+			log.Warnf("Empty version found for go-flutter. Skipping upgrade check. (This may be caused by replace statement in the application go.mod)")
+		} else {
+			semver, err := version.NewSemver(currentTag)
+			if err != nil {
+				return errors.Wrap(err, "faild to parse 'go-flutter' semver")
+			}
+
+			if semver.Prerelease() != "" {
+				log.Infof("Upgrading 'go-flutter' to the latest release")
+				// no buildBranch provided and currentTag isn't a release,
+				// force update. (same behaviour as previous version of hover).
+				err = UpgradeGoFlutter(ctx, "")
+				if err != nil {
+					// the upgrade can fail silently
+					log.Warnf("%v", err)
+					log.Warnf("Upgrade ignored, current 'go-flutter' version: %s", currentTag)
+				}
+			} else {
+				// when the buildBranch is empty and the currentTag is a release.
+				// Check if the 'go-flutter' needs updates.
+				versioncheck.CheckForGoFlutterUpdate(filepath.Join(wd, build.BuildPath), currentTag)
+			}
+		}
+
+	} else {
+		log.Printf("Downloading 'go-flutter' %s", b.opts.GoFlutterBranch)
+
+		// when the buildBranch is set, fetch the go-flutter branch version.
+		err = UpgradeGoFlutter(ctx, b.opts.GoFlutterBranch)
+		if err != nil {
+			return err
+		}
+	}
+
+	if hoverVersion := Version(); hoverVersion != "" {
+		versioncheck.CheckForHoverUpdate(hoverVersion)
+	}
+
+	profile := b.profile.ForOS(targetOS)
+	if b.openGL(profile) == "none" {
+		log.Warnf("The '--opengl=none' flag makes go-flutter incompatible with texture plugins!")
+	}
+
+	outputDirectoryPath, err := build.OutputDirectoryPath(targetOS, targetArch)
+	if err != nil {
+		return err
+	}
+	outputBinaryPath, err := build.OutputBinaryPath(b.config.GetExecutableName(b.pubSpec.Name), targetOS, targetArch)
+	if err != nil {
+		return err
+	}
+	engineFilename, err := build.EngineFilename(targetOS)
+	if err != nil {
+		return err
+	}
+	outputEngineFile := filepath.Join(outputDirectoryPath, engineFilename)
+	if b.aot(targetOS) {
+		vmArguments = append(vmArguments, aotVMArgument)
+	}
+	info := b.collectBuildInfo(ctx, targetOS, engineCachePath, vmArguments)
+	var embeddedBuildInfo string
+	if *b.opts.EmbedBuildInfo {
+		embeddedBuildInfo, err = info.Embedded()
+		if err != nil {
+			return err
+		}
+	}
+	buildCommandString, err := b.goBuildCommand(profile, vmArguments, embeddedBuildInfo, outputBinaryPath)
+	if err != nil {
+		return err
+	}
+	goBuildEnv, err := goBuildEnv(targetOS, targetArch, engineCachePath)
+	if err != nil {
+		return err
+	}
+	inputs, err := goBuildInputs(ctx, targetOS, engineCachePath, buildCommandString, goBuildEnv)
+	if err != nil {
+		return err
+	}
+	if icon := b.config.GetIcon(); icon != "" {
+		err = inputs.AddFile(icon)
+		if err != nil {
+			return errors.Wrapf(err, "failed to hash the icon of the %s flavor", b.opts.Flavor)
+		}
+	}
+	if b.stepUpToDate(goBuildStep, targetOS, inputs, outputBinaryPath, outputEngineFile) {
+		log.Infof("Skipping the go build, its inputs didn't change since the last build")
+		return b.writeBuildInfo(targetOS, info)
+	}
+	err = cleanGoBuildOutputs(outputDirectoryPath)
+	if err != nil {
+		return err
+	}
+
+	intermediatesDirectoryPath, err := build.IntermediatesDirectoryPath(targetOS)
+	if err != nil {
+		return err
+	}
+	err = fileutils.CopyDir(intermediatesDirectoryPath, outputDirectoryPath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(outputEngineFile); err == nil || os.IsExist(err) {
+		err = os.RemoveAll(outputEngineFile)
+		if err != nil {
+			return errors.Wrap(err, "failed to remove old engine")
+		}
+	}
+	err = copy.Copy(
+		filepath.Join(engineCachePath, engineFilename),
+		outputEngineFile,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed to copy %s", engineFilename)
+	}
+
+	err = copy.Copy(
+		filepath.Join(engineCachePath, "artifacts", "icudtl.dat"),
+		filepath.Join(outputDirectoryPath, "icudtl.dat"),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to copy icudtl.dat")
+	}
+
+	err = fileutils.CopyDir(
+		filepath.Join(build.BuildPath, "assets"),
+		filepath.Join(outputDirectoryPath, "assets"),
+	)
+	if err != nil {
+		return err
+	}
+	outputAssetsPath := filepath.Join(outputDirectoryPath, "assets")
+	if icon := b.config.GetIcon(); icon != "" {
+		err = replaceIcon(outputAssetsPath, icon)
+		if err != nil {
+			return errors.Wrapf(err, "failed to copy the icon of the %s flavor", b.opts.Flavor)
+		}
+	}
+	icon, err := icons.Load(outputAssetsPath)
+	if err != nil {
+		return err
+	}
+	err = icon.WriteWindowIcons(outputAssetsPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate the window icons")
+	}
+
+	if profile.GetStrip() && targetOS == "linux" {
+		stripBinName := "strip"
+		if targetArch != runtime.GOARCH {
+			stripBinName = crossStripBinNames[targetArch]
+		}
+		err = exec.CommandContext(ctx, stripBinName, "-s", outputEngineFile).Run()
+		if err != nil {
+			return errors.Wrapf(err, "failed to strip %s", outputEngineFile)
+		}
+	}
+
+	cmdGoBuild := exec.CommandContext(ctx, buildCommandString[0], buildCommandString[1:]...)
+	cmdGoBuild.Dir = filepath.Join(wd, build.BuildPath)
+	cmdGoBuild.Env = append(os.Environ(), goBuildEnv...)
+
+	cmdGoBuild.Stderr = os.Stderr
+	cmdGoBuild.Stdout = log.CommandOutput()
+
+	log.Infof("Compiling 'go-flutter' and plugins")
+	err = cmdGoBuild.Run()
+	if err != nil {
+		return errors.Wrap(err, "go build failed")
+	}
+	log.Infof("Successfully compiled executable binary for %s/%s", targetOS, targetArch)
+	err = b.runHook(ctx, hooks.PostGoBuild, b.config.Hooks.PostGoBuild, targetOS)
+	if err != nil {
+		return err
+	}
+	b.recordStep(goBuildStep, targetOS, inputs)
+	return b.writeBuildInfo(targetOS, info)
+}
+
+// replaceIcon replaces the icon of the assets of a build, the icon.svg or
+// icon.png and the icons of the sizes overriding it, by the icon of a flavor.
+func replaceIcon(assetsPath, icon string) error {
+	iconPaths, err := filepath.Glob(filepath.Join(assetsPath, "icon-*.png"))
+	if err != nil {
+		return err
+	}
+	iconPaths = append(iconPaths, filepath.Join(assetsPath, "icon.png"), filepath.Join(assetsPath, "icon.svg"))
+	for _, iconPath := range iconPaths {
+		err = os.RemoveAll(iconPath)
+		if err != nil {
+			return err
+		}
+	}
+	iconName := "icon.png"
+	if strings.EqualFold(filepath.Ext(icon), ".svg") {
+		iconName = "icon.svg"
+	}
+	return copy.Copy(icon, filepath.Join(assetsPath, iconName))
+}
+
+// runHook runs the commands of a build hook of a target OS.
+func (b *builder) runHook(ctx context.Context, name string, commands []string, targetOS string) error {
+	if len(commands) == 0 {
+		return nil
+	}
+	outputDirectoryPath, err := build.OutputDirectoryPath(targetOS, b.opts.Arch)
+	if err != nil {
+		return err
+	}
+	outputDirectoryPath, err = filepath.Abs(outputDirectoryPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve the output directory")
+	}
+	return hooks.Run(ctx, name, commands, hooks.Env{
+		TargetOS:  targetOS,
+		Arch:      b.opts.Arch,
+		Version:   b.opts.VersionNumber,
+		OutputDir: outputDirectoryPath,
+	})
+}
+
+// ensureEngine returns the engine cache path of a target OS, downloading
+// the engine unless SkipEngineDownload is set.
+func (b *builder) ensureEngine(ctx context.Context, targetOS string) (string, error) {
+	if b.opts.SkipEngineDownload {
+		return enginecache.EngineCachePath(targetOS, b.opts.Arch, b.engineMode(targetOS), b.opts.CachePath), nil
+	}
+	return EnsureEngine(ctx, EngineOptions{
+		TargetOS:      targetOS,
+		TargetArch:    b.opts.Arch,
+		Mode:          b.engineMode(targetOS),
+		CachePath:     b.opts.CachePath,
+		EngineVersion: b.opts.EngineVersion,
+	})
+}
+
+// checkCrossCompiler returns an error when the go build of a target OS runs
+// on a linux host, or in the docker container, which has no C compiler for
+// the target platform.
+func (b *builder) checkCrossCompiler(targetOS string) error {
+	if b.opts.SkipGoBuild {
+		return nil
+	}
+	hostOS, hostArch := runtime.GOOS, runtime.GOARCH
+	if *b.opts.Docker {
+		hostOS, hostArch = "linux", "amd64"
+	}
+	if hostOS != "linux" || (targetOS == hostOS && b.opts.Arch == hostArch) {
+		return nil
+	}
+	if _, ok := crossCompilers[targetOS+"/"+b.opts.Arch]; !ok {
+		return errors.Errorf("%s/%s cannot be cross-compiled from linux, build it on a %s/%s host without docker", targetOS, b.opts.Arch, targetOS, b.opts.Arch)
+	}
+	return nil
+}
+
+func goBuildEnv(targetOS, targetArch string, engineCachePath string) ([]string, error) {
+	var cgoLdflags string = os.Getenv("CGO_LDFLAGS")
+	var cgoCflags string = os.Getenv("CGO_CFLAGS")
+
+	outputDirPath, err := build.OutputDirectoryPath(targetOS, targetArch)
+	if err != nil {
+		return nil, err
+	}
+
+	switch targetOS {
+	case "darwin":
+		cgoLdflags += fmt.Sprintf(" -F%s -Wl,-rpath,@executable_path", engineCachePath)
+		cgoLdflags += fmt.Sprintf(" -F%s -L%s", outputDirPath, outputDirPath)
+		cgoLdflags += " -mmacosx-version-min=10.10"
+		cgoCflags += " -mmacosx-version-min=10.10"
+	case "linux":
+		cgoLdflags += fmt.Sprintf(" -L%s -L%s", engineCachePath, outputDirPath)
+	case "windows":
+		cgoLdflags += fmt.Sprintf(" -L%s -L%s", engineCachePath, outputDirPath)
+	default:
+		return nil, errors.Errorf("target platform %s is not supported, cgo_ldflags not implemented", targetOS)
+	}
+	env := []string{
+		"GO111MODULE=on",
+		"CGO_LDFLAGS=" + cgoLdflags,
+		"CGO_CFLAGS=" + cgoCflags,
+		"GOOS=" + targetOS,
+		"GOARCH=" + targetArch,
+		"CGO_ENABLED=1",
+	}
+	if runtime.GOOS == "linux" && (targetOS != runtime.GOOS || targetArch != runtime.GOARCH) {
+		crossCompiler, ok := crossCompilers[targetOS+"/"+targetArch]
+		if !ok {
+			return nil, errors.Errorf("%s/%s cannot be cross-compiled from linux", targetOS, targetArch)
+		}
+		env = append(env,
+			"CC="+crossCompiler,
+		)
+	}
+	return env, nil
+}
+
+func (b *builder) goBuildCommand(profile config.Profile, vmArguments []string, embeddedBuildInfo, outputBinaryPath string) ([]string, error) {
+	abspath, err := filepath.Abs(build.BuildPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to detect absolute path: %s", build.BuildPath)
+	}
+
+	currentTag, err := versioncheck.CurrentGoFlutterTag(abspath)
+	if err != nil {
+		return nil, err
+	}
+
+	vmArguments = append(vmArguments, profile.VMArguments...)
+
+	var ldflags []string
+	ldflags = append(ldflags, profile.Ldflags...)
+	if b.opts.Reproducible {
+		ldflags = append(ldflags, "-buildid=")
+	}
+	if profile.GetStrip() {
+		ldflags = append(ldflags, "-s")
+		ldflags = append(ldflags, "-w")
+	}
+	ldflags = append(ldflags, fmt.Sprintf("-X main.vmArguments=%s", strings.Join(vmArguments, ";")))
+	if embeddedBuildInfo != "" {
+		ldflags = append(ldflags, "-X main.buildInfo="+embeddedBuildInfo)
+	}
+	// overwrite go-flutter build-constants values
+	ldflags = append(ldflags, fmt.Sprintf(
+		"-X 'github.com/go-flutter-desktop/go-flutter.ProjectVersion=%s' "+
+			" -X 'github.com/go-flutter-desktop/go-flutter.PlatformVersion=%s' "+
+			" -X 'github.com/go-flutter-desktop/go-flutter.ProjectName=%s' "+
+			" -X 'github.com/go-flutter-desktop/go-flutter.ProjectOrganizationName=%s'",
+		b.opts.VersionNumber,
+		currentTag,
+		b.config.GetApplicationName(b.pubSpec.Name),
+		androidmanifest.AndroidOrganizationName()))
+
+	tags := append([]string{"opengl" + b.openGL(profile)}, profile.BuildTags...)
+	outputCommand := []string{
+		"go",
+		"build",
+		"-tags=" + strings.Join(tags, ","),
+		"-o", outputBinaryPath,
+		"-v",
+	}
+	if profile.GetTrimpath() || b.opts.Reproducible {
+		outputCommand = append(outputCommand, "-trimpath")
+	}
+	if profile.GetRace() {
+		outputCommand = append(outputCommand, "-race")
+	}
+	outputCommand = append(outputCommand, fmt.Sprintf("-ldflags=%s", strings.Join(ldflags, " ")))
+	outputCommand = append(outputCommand, dotSlash+"cmd")
+	return outputCommand, nil
+}
+
+// UpgradeGoFlutter updates the 'go-flutter' library of the project to the
+// given version, e.g. @master or @v0.20.0. It defaults to the latest release.
+func UpgradeGoFlutter(ctx context.Context, branch string) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return errors.Wrap(err, "failed to get working dir")
+	}
+
+	if branch == "" {
+		branch = "@latest"
+	}
+
+	goBin, err := build.GoBin()
+	if err != nil {
+		return err
+	}
+	cmdGoGetU := exec.CommandContext(ctx, goBin, "get", "-u", "-d", "github.com/go-flutter-desktop/go-flutter"+branch)
+	cmdGoGetU.Dir = filepath.Join(wd, build.BuildPath)
+	cmdGoGetU.Env = append(os.Environ(),
+		"GOPROXY=direct", // github.com/golang/go/issues/32955 (allows '/' in branch name)
+		"GO111MODULE=on",
+	)
+	cmdGoGetU.Stderr = os.Stderr
+	cmdGoGetU.Stdout = log.CommandOutput()
+
+	err = cmdGoGetU.Run()
+	// When cross-compiling the command fails, but that is not an error
+	if err != nil {
+		return errors.Wrapf(err, "updating go-flutter to %s version failed", branch)
+	}
+
+	cmdGoModDownload := exec.CommandContext(ctx, goBin, "mod", "download")
+	cmdGoModDownload.Dir = filepath.Join(wd, build.BuildPath)
+	cmdGoModDownload.Env = append(os.Environ(),
+		"GO111MODULE=on",
+	)
+	cmdGoModDownload.Stderr = os.Stderr
+	cmdGoModDownload.Stdout = log.CommandOutput()
+
+	err = cmdGoModDownload.Run()
+	if err != nil {
+		return errors.Wrap(err, "go mod download failed")
+	}
+
+	currentTag, err := versioncheck.CurrentGoFlutterTag(filepath.Join(wd, build.BuildPath))
+	if err != nil {
+		return err
+	}
+
+	log.Printf("'go-flutter' is on version: %s", currentTag)
+	return nil
+}
@@ -0,0 +1,51 @@
+package hover
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeDartDefines(t *testing.T) {
+	for _, test := range []struct {
+		configDefines map[string]string
+		defines       []string
+		merged        []string
+		err           string
+	}{
+		{
+			merged: []string{},
+		},
+		{
+			configDefines: map[string]string{"FLAVOR": "beta", "API_URL": "https://example.com"},
+			merged:        []string{"API_URL=https://example.com", "FLAVOR=beta"},
+		},
+		{
+			defines: []string{"SENTRY=on", "API_URL=http://localhost:8080/?a=b"},
+			merged:  []string{"API_URL=http://localhost:8080/?a=b", "SENTRY=on"},
+		},
+		{
+			configDefines: map[string]string{"FLAVOR": "beta", "API_URL": "https://example.com"},
+			defines:       []string{"FLAVOR=dev", "EMPTY=", "FLAVOR=prod"},
+			merged:        []string{"API_URL=https://example.com", "EMPTY=", "FLAVOR=prod"},
+		},
+		{
+			configDefines: map[string]string{"FLAVOR": "beta"},
+			defines:       []string{"=prod"},
+			err:           "invalid dart define '=prod', use KEY=VALUE",
+		},
+		{
+			defines: []string{"FLAVOR=prod", "SENTRY"},
+			err:     "invalid dart define 'SENTRY', use KEY=VALUE",
+		},
+	} {
+		merged, err := mergeDartDefines(test.configDefines, test.defines)
+		if test.err != "" {
+			require.NotEqual(t, err, nil, "merging %q must fail", test.defines)
+			require.Equal(t, test.err, err.Error())
+			continue
+		}
+		require.Equal(t, err, nil, "failed to merge %q: %v", test.defines, err)
+		require.Equal(t, test.merged, merged)
+	}
+}
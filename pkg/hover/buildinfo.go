@@ -0,0 +1,104 @@
+package hover
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-flutter-desktop/hover/internal/build"
+	"github.com/go-flutter-desktop/hover/internal/buildinfo"
+	"github.com/go-flutter-desktop/hover/internal/flutterversion"
+	"github.com/go-flutter-desktop/hover/internal/log"
+	"github.com/go-flutter-desktop/hover/internal/modx"
+	"github.com/go-flutter-desktop/hover/internal/versioncheck"
+)
+
+// collectBuildInfo returns the provenance of the build of a target OS. Tool
+// versions that cannot be determined are left empty, the build info must not
+// fail the build.
+func (b *builder) collectBuildInfo(ctx context.Context, targetOS, engineCachePath string, vmArguments []string) buildinfo.Info {
+	profile := b.profile.ForOS(targetOS)
+	info := buildinfo.Info{
+		Target:  targetOS,
+		Arch:    b.opts.Arch,
+		Version: b.opts.VersionNumber,
+		Inputs: buildinfo.Inputs{
+			FlutterTarget:   b.opts.FlutterTarget,
+			Profile:         b.opts.Profile,
+			AOT:             b.aot(targetOS),
+			Reproducible:    b.opts.Reproducible,
+			OpenGL:          b.openGL(profile),
+			VMArguments:     append(append([]string{}, vmArguments...), profile.VMArguments...),
+			GoFlutterBranch: b.opts.GoFlutterBranch,
+		},
+		Tools: buildinfo.Tools{
+			Hover: Version(),
+		},
+		Dependencies: buildinfo.Dependencies{
+			DartPackages: make(map[string]string),
+			GoModules:    make(map[string]string),
+		},
+	}
+	if b.opts.Reproducible {
+		info.Inputs.SourceDateEpoch, _ = strconv.ParseInt(os.Getenv("SOURCE_DATE_EPOCH"), 10, 64)
+	}
+
+	if goBin, err := build.GoBin(); err == nil {
+		if out, err := exec.CommandContext(ctx, goBin, "env", "GOVERSION").Output(); err == nil {
+			info.Tools.Go = strings.TrimSpace(string(out))
+		}
+	}
+	// flutter isn't available in the docker image, the flutter bundle is
+	// built on the host.
+	if _, err := exec.LookPath("flutter"); err == nil {
+		flutterVersion, err := flutterversion.FlutterVersion()
+		if err != nil {
+			log.Debugf("Build info: %v", err)
+		}
+		info.Tools.Flutter = flutterVersion.Framework
+		info.Tools.FlutterChannel = flutterVersion.Channel
+	}
+	if engineVersion, err := ioutil.ReadFile(filepath.Join(engineCachePath, "version")); err == nil {
+		info.Tools.Engine = string(engineVersion)
+	}
+	if goFlutterTag, err := versioncheck.CurrentGoFlutterTag(build.BuildPath); err == nil {
+		info.Tools.GoFlutter = goFlutterTag
+	}
+
+	if out, err := exec.CommandContext(ctx, "git", "rev-parse", "HEAD").Output(); err == nil {
+		info.Git = &buildinfo.Git{Commit: strings.TrimSpace(string(out))}
+		if status, err := exec.CommandContext(ctx, "git", "status", "--porcelain").Output(); err == nil {
+			info.Git.Dirty = len(strings.TrimSpace(string(status))) > 0
+		}
+	}
+
+	if pubLock, err := readPubSpecLock(); err == nil {
+		for name, dep := range pubLock.Packages {
+			info.Dependencies.DartPackages[name] = dep.Version
+		}
+	}
+	if goMod, err := modx.Open(build.BuildPath); err == nil {
+		for _, require := range goMod.Require {
+			info.Dependencies.GoModules[require.Mod.Path] = require.Mod.Version
+		}
+	}
+	return info
+}
+
+// writeBuildInfo writes the build info of a target OS with the hashes of the
+// files of the output directory.
+func (b *builder) writeBuildInfo(targetOS string, info buildinfo.Info) error {
+	outputDirectoryPath, err := build.OutputDirectoryPath(targetOS, b.opts.Arch)
+	if err != nil {
+		return err
+	}
+	err = info.AddFiles(outputDirectoryPath)
+	if err != nil {
+		return err
+	}
+	return buildinfo.Write(outputDirectoryPath, info)
+}
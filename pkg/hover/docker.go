@@ -0,0 +1,186 @@
+package hover
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/hover/internal/build"
+	"github.com/go-flutter-desktop/hover/internal/log"
+	"github.com/go-flutter-desktop/hover/internal/logstreamer"
+	"github.com/go-flutter-desktop/hover/internal/packaging"
+)
+
+// dockerFlags returns the flags passed to the hover build running inside the
+// docker container.
+func (b *builder) dockerFlags(targetOS string) []string {
+	// the settings are resolved on the host, the environment variables and
+	// the user config aren't available in the container
+	f := []string{
+		"--skip-flutter-build-bundle",
+		"--skip-engine-download",
+		"--version-number", b.opts.VersionNumber,
+		"--arch", b.opts.Arch,
+		"--profile", b.opts.Profile,
+		"--target", b.opts.FlutterTarget,
+		"--opengl", b.openGL(b.profile.ForOS(targetOS)),
+		"--docker=false",
+		"--embed-build-info=" + strconv.FormatBool(*b.opts.EmbedBuildInfo),
+	}
+	if b.opts.Flavor != "" {
+		f = append(f, "--flavor", b.opts.Flavor)
+	}
+	for _, define := range b.opts.DartDefines {
+		f = append(f, "--dart-define", define)
+	}
+	if b.opts.GoFlutterBranch != "" {
+		f = append(f, "--branch", b.opts.GoFlutterBranch)
+	}
+	if b.opts.EngineVersion != "" {
+		f = append(f, "--engine-version", b.opts.EngineVersion)
+	}
+	if b.opts.AOT != nil {
+		f = append(f, "--aot="+strconv.FormatBool(*b.opts.AOT))
+	}
+	if b.opts.Reproducible {
+		f = append(f, "--reproducible")
+	}
+	if b.opts.Force {
+		f = append(f, "--force")
+	}
+	return f
+}
+
+// dockerBuild runs the go build and the packaging tasks of a target OS in the
+// goflutter/hover docker image.
+func (b *builder) dockerBuild(ctx context.Context, targetOS string, packagingTasks []packaging.Task) error {
+	_, err := b.ensureEngine(ctx, targetOS)
+	if err != nil {
+		return err
+	}
+	dockerBin, err := build.DockerBin()
+	if err != nil {
+		return err
+	}
+
+	hoverCacheDir := filepath.Join(b.opts.CachePath, "hover")
+
+	engineCacheDir := filepath.Join(hoverCacheDir, "engine")
+	err = os.MkdirAll(engineCacheDir, 0755)
+	if err != nil {
+		return errors.Wrap(err, "cannot create the engine cache path in the user cache directory")
+	}
+
+	dockerGoCacheDir := filepath.Join(hoverCacheDir, "docker-go-cache")
+	err = os.MkdirAll(dockerGoCacheDir, 0755)
+	if err != nil {
+		return errors.Wrap(err, "cannot create the docker-go-cache path in the user cache directory")
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return errors.Wrap(err, "cannot get the path for current directory")
+	}
+	log.Infof("Compiling go binary using docker container")
+
+	dockerArgs := []string{
+		"run",
+		"--rm",
+		"--mount", "type=bind,source=" + wd + ",target=/app",
+		"--mount", "type=bind,source=" + engineCacheDir + ",target=/root/.cache/hover/engine",
+		"--mount", "type=bind,source=" + dockerGoCacheDir + ",target=/go-cache",
+		"--env", "GOCACHE=/go-cache",
+	}
+	for _, packagingTask := range packagingTasks {
+		if targetOS != "linux" || packagingTask.Name() != "flatpak" {
+			continue
+		}
+		// flatpak-builder runs the build commands in a bubblewrap sandbox,
+		// the flatpak runtimes are kept between the builds
+		dockerFlatpakDir := filepath.Join(hoverCacheDir, "docker-flatpak")
+		err = os.MkdirAll(dockerFlatpakDir, 0755)
+		if err != nil {
+			return errors.Wrap(err, "cannot create the docker-flatpak path in the user cache directory")
+		}
+		dockerArgs = append(dockerArgs,
+			"--privileged",
+			"--mount", "type=bind,source="+dockerFlatpakDir+",target=/root/.cache/hover/flatpak",
+		)
+	}
+	if runtime.GOOS != "windows" {
+		currentUser, err := user.Current()
+		if err != nil {
+			return errors.Wrap(err, "couldn't get current user info")
+		}
+		dockerArgs = append(dockerArgs, "--env", "HOVER_SAFE_CHOWN_UID="+currentUser.Uid)
+		dockerArgs = append(dockerArgs, "--env", "HOVER_SAFE_CHOWN_GID="+currentUser.Gid)
+	}
+	goproxy, err := exec.CommandContext(ctx, "go", "env", "GOPROXY").Output()
+	if err != nil {
+		log.Errorf("Failed to get GOPROXY: %v", err)
+	}
+	if string(goproxy) != "" {
+		dockerArgs = append(dockerArgs, "--env", "GOPROXY="+string(goproxy))
+	}
+	goprivate, err := exec.CommandContext(ctx, "go", "env", "GOPRIVATE").Output()
+	if err != nil {
+		log.Errorf("Failed to get GOPRIVATE: %v", err)
+	}
+	if string(goprivate) != "" {
+		dockerArgs = append(dockerArgs, "--env", "GOPRIVATE="+string(goprivate))
+	}
+	if sourceDateEpoch := os.Getenv("SOURCE_DATE_EPOCH"); sourceDateEpoch != "" {
+		dockerArgs = append(dockerArgs, "--env", "SOURCE_DATE_EPOCH="+sourceDateEpoch)
+	}
+	if vmArguments := b.opts.VMArguments; len(vmArguments) > 0 {
+		// I (GeertJohan) am not too happy with this, it make the hover inside
+		// the container aware of it being inside the container. But for now
+		// this is the best way to go about.
+		//
+		// HOVER_BUILD_INDOCKER_VMARGS is explicitly not document, it is not
+		// intended to be abused and may disappear at any time.
+		dockerArgs = append(dockerArgs, "--env", "HOVER_IN_DOCKER_BUILD_VMARGS="+strings.Join(vmArguments, ","))
+	}
+
+	version := Version()
+	if version == "" || version == "(devel)" {
+		version = "latest"
+	}
+	dockerImage := "goflutter/hover:" + version
+	dockerArgs = append(dockerArgs, dockerImage)
+	var targets []string
+	for _, packagingTask := range packagingTasks {
+		targetOSAndPackaging := targetOS
+		if packName := packagingTask.Name(); packName != "" {
+			targetOSAndPackaging += "-" + packName
+		}
+		targets = append(targets, targetOSAndPackaging)
+	}
+	hoverCommand := []string{"hover-safe.sh", "build"}
+	if len(targets) == 1 {
+		hoverCommand = append(hoverCommand, targets[0])
+	} else {
+		hoverCommand = append(hoverCommand, "--targets", strings.Join(targets, ","))
+	}
+	hoverCommand = append(hoverCommand, b.dockerFlags(targetOS)...)
+	dockerArgs = append(dockerArgs, hoverCommand...)
+
+	dockerRunCmd := exec.CommandContext(ctx, dockerBin, dockerArgs...)
+	log.Debugf("Running the docker command: %s", dockerRunCmd.String())
+	dockerRunCmd.Stderr = logstreamer.NewLogstreamerForStderr("docker container: ")
+	dockerRunCmd.Stdout = logstreamer.NewLogstreamerForWriter("docker container: ", log.CommandOutput())
+	dockerRunCmd.Dir = wd
+	err = dockerRunCmd.Run()
+	if err != nil {
+		return errors.Wrap(err, "docker run failed")
+	}
+	log.Infof("Docker run completed")
+	return nil
+}
@@ -0,0 +1,50 @@
+package hover
+
+import (
+	"context"
+
+	"github.com/go-flutter-desktop/hover/internal/build"
+	"github.com/go-flutter-desktop/hover/internal/enginecache"
+)
+
+// EngineOptions selects the Flutter engine to download.
+type EngineOptions struct {
+	// TargetOS is the GOOS the engine is downloaded for.
+	TargetOS string
+	// TargetArch is the GOARCH the engine is downloaded for, defaults to
+	// amd64.
+	TargetArch string
+	// Mode is the runtime mode of the engine: debug, profile or release.
+	// Defaults to debug. The profile and release engines come with
+	// gen_snapshot.
+	Mode string
+	// CachePath is the directory the engine is cached in, defaults to the
+	// user cache directory.
+	CachePath string
+	// EngineVersion is the commit hash of the engine, defaults to the engine
+	// version of the installed Flutter SDK.
+	EngineVersion string
+}
+
+// EnsureEngine makes sure the engine described by opts is in the engine
+// cache, downloading it when needed, and returns the path of the directory
+// containing the engine.
+func EnsureEngine(ctx context.Context, opts EngineOptions) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if opts.TargetArch == "" {
+		opts.TargetArch = build.DefaultArch
+	}
+	if opts.Mode == "" {
+		opts.Mode = enginecache.ModeDebug
+	}
+	if opts.CachePath == "" {
+		opts.CachePath = enginecache.DefaultCachePath()
+	}
+	err := enginecache.ValidateOrUpdateEngine(opts.TargetOS, opts.TargetArch, opts.Mode, opts.CachePath, opts.EngineVersion)
+	if err != nil {
+		return "", err
+	}
+	return enginecache.EngineCachePath(opts.TargetOS, opts.TargetArch, opts.Mode, opts.CachePath), nil
+}
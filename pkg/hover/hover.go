@@ -0,0 +1,65 @@
+// Package hover exposes the hover tooling as a Go API, so that Flutter
+// desktop applications can be built and packaged programmatically instead of
+// by running the hover CLI.
+//
+// The functions operate on the Flutter project in the current working
+// directory. They return errors instead of exiting the process, but still log
+// their progress the same way the CLI does.
+package hover
+
+import (
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/hover/internal/build"
+	"github.com/go-flutter-desktop/hover/internal/config"
+	"github.com/go-flutter-desktop/hover/internal/pubspec"
+)
+
+var (
+	versionValue string
+	versionOnce  sync.Once
+)
+
+// Version returns the version of hover. An empty string is returned when the
+// binary doesn't contain version information (hover built without modules).
+func Version() string {
+	versionOnce.Do(func() {
+		buildInfo, ok := debug.ReadBuildInfo()
+		if ok {
+			versionValue = buildInfo.Main.Version
+		}
+	})
+	return versionValue
+}
+
+// assertProject checks that the working directory contains a Flutter project
+// initialized for hover, with valid pubspec.yaml and hover.yaml files.
+func assertProject() error {
+	_, err := pubspec.ReadPubSpecFile("pubspec.yaml")
+	if err != nil {
+		return errors.Wrap(err, "the working directory isn't a Flutter project")
+	}
+	_, err = os.Stat(build.BuildPath)
+	if os.IsNotExist(err) {
+		return errors.Errorf("directory '%s' is missing, please init go-flutter first: hover init", build.BuildPath)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to detect directory %s", build.BuildPath)
+	}
+	hoverConfig, err := config.ReadConfigFile(filepath.Join(build.BuildPath, "hover.yaml"))
+	if err != nil && !os.IsNotExist(errors.Cause(err)) {
+		return err
+	}
+	if hoverConfig.CachePathREMOVED != "" {
+		return errors.New("the hover.yaml field 'cache-path' is not used anymore, remove it from your hover.yaml and use the cache path option instead")
+	}
+	if hoverConfig.BranchREMOVED != "" {
+		return errors.New("the hover.yaml field 'branch' is not used anymore, remove it from your hover.yaml and use the go-flutter branch option instead")
+	}
+	return nil
+}
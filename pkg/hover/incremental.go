@@ -0,0 +1,195 @@
+package hover
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/hover/internal/build"
+	"github.com/go-flutter-desktop/hover/internal/buildmanifest"
+	"github.com/go-flutter-desktop/hover/internal/flutterversion"
+	"github.com/go-flutter-desktop/hover/internal/log"
+	"github.com/go-flutter-desktop/hover/internal/pubspec"
+)
+
+const (
+	flutterBundleStep = "flutter-bundle"
+	goBuildStep       = "go-build"
+)
+
+// stepUpToDate returns true when a build step can be skipped: the step inputs
+// are the same as the ones of its last successful run, and all of its outputs
+// still exist.
+func (b *builder) stepUpToDate(step, targetOS string, inputs buildmanifest.Inputs, outputs ...string) bool {
+	stepName := build.TargetDirectoryName(build.FlavorDirectoryName(targetOS), b.opts.Arch) + "/" + step
+	if b.opts.Force {
+		log.Debugf("Running %s: --force is set", stepName)
+		return false
+	}
+	for _, output := range outputs {
+		if _, err := os.Stat(output); err != nil {
+			log.Debugf("Running %s: output %s is missing", stepName, output)
+			return false
+		}
+	}
+	reason := b.manifest.Changed(stepName, inputs)
+	if reason != "" {
+		log.Debugf("Running %s: %s", stepName, reason)
+		return false
+	}
+	return true
+}
+
+// recordStep records the inputs of a build step that ran successfully.
+func (b *builder) recordStep(step, targetOS string, inputs buildmanifest.Inputs) {
+	err := b.manifest.Record(build.TargetDirectoryName(build.FlavorDirectoryName(targetOS), b.opts.Arch)+"/"+step, inputs)
+	if err != nil {
+		log.Warnf("The next build won't be able to skip unchanged steps: %v", err)
+	}
+}
+
+// flutterBundleInputs returns the inputs of the flutter bundle step: the dart
+// sources, pubspec.yaml, the resolved dependencies, the assets declared in
+// pubspec.yaml and the version of the Flutter SDK.
+func (b *builder) flutterBundleInputs(targetOS string) (buildmanifest.Inputs, error) {
+	flutterVersion, err := b.flutterVersion()
+	if err != nil {
+		return nil, err
+	}
+	inputs := buildmanifest.Inputs{}
+	inputs.AddValue("flutter-version", flutterVersion.Framework)
+	inputs.AddValue("flutter-engine", flutterVersion.Engine)
+	inputs.AddValue("target", b.opts.FlutterTarget)
+	inputs.AddValue("track-widget-creation", strconv.FormatBool(b.profile.ForOS(targetOS).GetTrackWidgetCreation()))
+	inputs.AddValue("aot", strconv.FormatBool(b.aot(targetOS)))
+	inputs.AddValue("dart-defines", strings.Join(b.opts.DartDefines, " "))
+	inputs.AddValue("flutter-build-args", strings.Join(b.opts.FlutterBuildArgs, " "))
+	paths := []string{"lib", "pubspec.yaml", "pubspec.lock", ".packages"}
+	paths = append(paths, pubspecAssetPaths(b.pubSpec)...)
+	for _, path := range paths {
+		err := addBuildInput(inputs, path)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to hash the flutter bundle inputs")
+		}
+	}
+	return inputs, nil
+}
+
+// goBuildInputs returns the inputs of the go build step: the go sources and
+// assets, the plugin intermediates, the engine version, the go version and the
+// go build command and environment.
+func goBuildInputs(ctx context.Context, targetOS, engineCachePath string, buildCommandString, goBuildEnv []string) (buildmanifest.Inputs, error) {
+	goBin, err := build.GoBin()
+	if err != nil {
+		return nil, err
+	}
+	goVersion, err := exec.CommandContext(ctx, goBin, "version").Output()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the go version")
+	}
+	inputs := buildmanifest.Inputs{}
+	inputs.AddValue("go-version", strings.TrimSpace(string(goVersion)))
+	inputs.AddValue("command", strings.Join(buildCommandString, " "))
+	inputs.AddValue("env", strings.Join(goBuildEnv, " "))
+	err = inputs.AddFile(filepath.Join(engineCachePath, "version"))
+	if err == nil {
+		err = inputs.AddDir(build.BuildPath, func(path string) bool {
+			return path == filepath.Join(build.BuildPath, "build") || path == filepath.Join(build.BuildPath, "packaging")
+		})
+	}
+	if err == nil {
+		var intermediatesDirectoryPath string
+		intermediatesDirectoryPath, err = build.IntermediatesDirectoryPath(targetOS)
+		if err != nil {
+			return nil, err
+		}
+		err = inputs.AddDir(intermediatesDirectoryPath, nil)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to hash the go build inputs")
+	}
+	return inputs, nil
+}
+
+// flutterVersion returns the version of the Flutter SDK, it is read once per
+// build.
+func (b *builder) flutterVersion() (flutterversion.Version, error) {
+	if b.flutterSDKVersion == nil {
+		version, err := flutterversion.FlutterVersion()
+		if err != nil {
+			return version, err
+		}
+		b.flutterSDKVersion = &version
+	}
+	return *b.flutterSDKVersion, nil
+}
+
+// cleanGoBuildOutputs removes the files of the output directory of a target OS
+// before the go build runs, such as the libraries of plugins that were
+// removed. The flutter bundle and the AOT snapshot, built by the steps that
+// ran before, are kept.
+func cleanGoBuildOutputs(outputDirectoryPath string) error {
+	files, err := ioutil.ReadDir(outputDirectoryPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to list the output directory")
+	}
+	for _, file := range files {
+		if file.Name() == "flutter_assets" || file.Name() == aotSnapshotFilename {
+			continue
+		}
+		err = os.RemoveAll(filepath.Join(outputDirectoryPath, file.Name()))
+		if err != nil {
+			return errors.Wrap(err, "failed to clean the output directory")
+		}
+	}
+	return nil
+}
+
+func addBuildInput(inputs buildmanifest.Inputs, path string) error {
+	info, err := os.Stat(path)
+	if err == nil && info.IsDir() {
+		return inputs.AddDir(path, nil)
+	}
+	return inputs.AddFile(path)
+}
+
+// pubspecAssetPaths returns the assets and fonts declared in pubspec.yaml.
+func pubspecAssetPaths(pubSpec pubspec.PubSpec) []string {
+	var paths []string
+	flutter := pubSpec.Flutter
+	if assets, ok := flutter["assets"].([]interface{}); ok {
+		for _, asset := range assets {
+			if path, ok := asset.(string); ok {
+				paths = append(paths, filepath.FromSlash(path))
+			}
+		}
+	}
+	if fonts, ok := flutter["fonts"].([]interface{}); ok {
+		for _, family := range fonts {
+			familyMap, ok := family.(map[interface{}]interface{})
+			if !ok {
+				continue
+			}
+			familyFonts, ok := familyMap["fonts"].([]interface{})
+			if !ok {
+				continue
+			}
+			for _, font := range familyFonts {
+				fontMap, ok := font.(map[interface{}]interface{})
+				if !ok {
+					continue
+				}
+				if path, ok := fontMap["asset"].(string); ok {
+					paths = append(paths, filepath.FromSlash(path))
+				}
+			}
+		}
+	}
+	return paths
+}
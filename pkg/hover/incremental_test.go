@@ -0,0 +1,113 @@
+package hover
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-flutter-desktop/hover/internal/buildmanifest"
+)
+
+func TestStepUpToDate(t *testing.T) {
+	dir := t.TempDir()
+	outputPath := filepath.Join(dir, "app")
+	err := ioutil.WriteFile(outputPath, []byte("binary"), 0755)
+	require.Equal(t, err, nil, "failed to write the output: %v", err)
+	inputs := func(command string) buildmanifest.Inputs {
+		inputs := buildmanifest.Inputs{}
+		inputs.AddValue("command", command)
+		return inputs
+	}
+	manifest := buildmanifest.Open(filepath.Join(dir, "manifest.json"))
+
+	b := &builder{opts: BuildOptions{Arch: "amd64"}, manifest: manifest}
+	require.False(t, b.stepUpToDate(goBuildStep, "linux", inputs("go build"), outputPath), "the step never ran")
+	b.recordStep(goBuildStep, "linux", inputs("go build"))
+
+	for _, test := range []struct {
+		name     string
+		opts     BuildOptions
+		targetOS string
+		inputs   buildmanifest.Inputs
+		output   string
+		upToDate bool
+	}{
+		{
+			name:     "unchanged",
+			opts:     BuildOptions{Arch: "amd64"},
+			targetOS: "linux",
+			inputs:   inputs("go build"),
+			output:   outputPath,
+			upToDate: true,
+		},
+		{
+			name:     "forced",
+			opts:     BuildOptions{Arch: "amd64", Force: true},
+			targetOS: "linux",
+			inputs:   inputs("go build"),
+			output:   outputPath,
+		},
+		{
+			name:     "changed inputs",
+			opts:     BuildOptions{Arch: "amd64"},
+			targetOS: "linux",
+			inputs:   inputs("go build -v"),
+			output:   outputPath,
+		},
+		{
+			name:     "missing output",
+			opts:     BuildOptions{Arch: "amd64"},
+			targetOS: "linux",
+			inputs:   inputs("go build"),
+			output:   filepath.Join(dir, "missing"),
+		},
+		{
+			name:     "other target OS",
+			opts:     BuildOptions{Arch: "amd64"},
+			targetOS: "windows",
+			inputs:   inputs("go build"),
+			output:   outputPath,
+		},
+		{
+			name:     "other arch",
+			opts:     BuildOptions{Arch: "arm64"},
+			targetOS: "linux",
+			inputs:   inputs("go build"),
+			output:   outputPath,
+		},
+	} {
+		b := &builder{opts: test.opts, manifest: manifest}
+		require.Equal(t, test.upToDate, b.stepUpToDate(goBuildStep, test.targetOS, test.inputs, test.output), test.name)
+	}
+}
+
+func TestCleanGoBuildOutputs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"app", aotSnapshotFilename, "libremoved_plugin.so", "flutter_assets/kernel_blob.bin", "assets/icon.png"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		require.Equal(t, err, nil, "failed to create directory: %v", err)
+		err = ioutil.WriteFile(path, []byte(name), 0644)
+		require.Equal(t, err, nil, "failed to write file: %v", err)
+	}
+
+	err := cleanGoBuildOutputs(dir)
+	require.Equal(t, err, nil, "failed to clean the outputs: %v", err)
+	files, err := ioutil.ReadDir(dir)
+	require.Equal(t, err, nil, "failed to read the outputs: %v", err)
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	sort.Strings(names)
+	require.Equal(t, []string{aotSnapshotFilename, "flutter_assets"}, names)
+	_, err = os.Stat(filepath.Join(dir, "flutter_assets", "kernel_blob.bin"))
+	require.Equal(t, err, nil, "the flutter bundle must be kept: %v", err)
+
+	err = cleanGoBuildOutputs(filepath.Join(dir, "missing"))
+	require.NotEqual(t, err, nil, "cleaning a missing directory must fail")
+}
@@ -0,0 +1,304 @@
+package hover
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/hover/internal/androidmanifest"
+	"github.com/go-flutter-desktop/hover/internal/build"
+	"github.com/go-flutter-desktop/hover/internal/buildinfo"
+	"github.com/go-flutter-desktop/hover/internal/config"
+	"github.com/go-flutter-desktop/hover/internal/fileutils"
+	"github.com/go-flutter-desktop/hover/internal/log"
+	"github.com/go-flutter-desktop/hover/internal/pubspec"
+)
+
+// manifestsDirectoryName is the name of the directory of the manifest
+// templates in go/packaging and of the rendered manifests in
+// go/build/outputs.
+const manifestsDirectoryName = "publish-manifests"
+
+// manifestTemplateFiles are the default manifest templates, created in
+// go/packaging/publish-manifests when it doesn't exist.
+var manifestTemplateFiles = []string{
+	"homebrew/{{.packageName}}.rb.tmpl",
+	"scoop/{{.packageName}}.json.tmpl",
+	"winget/{{.organizationName}}.{{.packageName}}.yaml.tmpl",
+	"winget/{{.organizationName}}.{{.packageName}}.installer.yaml.tmpl",
+	"winget/{{.organizationName}}.{{.packageName}}.locale.en-US.yaml.tmpl",
+}
+
+// PublishManifestsOptions configures the generation of the manifests of the
+// package managers.
+type PublishManifestsOptions struct {
+	// BaseURL is the URL the artifacts are published at, the URL of an
+	// artifact is BaseURL/<file name>.
+	BaseURL string
+	// Flavor is the name of the flavor of hover.yaml the artifacts were built
+	// for.
+	Flavor string
+	// VersionNumber overrides the version number of pubspec.yaml.
+	VersionNumber string
+}
+
+// manifestArtifact is a packaged file the manifests refer to.
+type manifestArtifact struct {
+	Target   string
+	Arch     string
+	FileName string
+	URL      string
+	SHA256   string
+	Size     int64
+}
+
+// PublishManifests renders the templates of go/packaging/publish-manifests
+// with the artifacts packaged in go/build/outputs, their URL and SHA-256, and
+// returns the paths of the manifests, written to
+// go/build/outputs/publish-manifests. Manifests referring to artifacts that
+// weren't packaged are skipped.
+func PublishManifests(opts PublishManifestsOptions) ([]string, error) {
+	err := assertProject()
+	if err != nil {
+		return nil, err
+	}
+	if opts.BaseURL == "" {
+		return nil, errors.New("the base URL of the artifacts is missing")
+	}
+	err = config.SelectFlavor(opts.Flavor)
+	if err != nil {
+		return nil, err
+	}
+	pubSpec, err := pubspec.GetPubSpec()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	if opts.VersionNumber == "" {
+		opts.VersionNumber = pubSpec.GetVersion()
+	}
+	version := strings.Split(opts.VersionNumber, "+")[0]
+
+	artifacts, err := findArtifacts(strings.TrimSuffix(opts.BaseURL, "/"), version)
+	if err != nil {
+		return nil, err
+	}
+	projectName := pubSpec.Name
+	author, err := pubSpec.GetAuthor()
+	if err != nil {
+		return nil, err
+	}
+	templateData := map[string]interface{}{
+		"projectName":      projectName,
+		"version":          version,
+		"description":      pubSpec.GetDescription(),
+		"homepage":         pubSpec.Homepage,
+		"organizationName": androidmanifest.AndroidOrganizationName(),
+		"author":           author,
+		"applicationName":  cfg.GetApplicationName(projectName),
+		"executableName":   cfg.GetExecutableName(projectName),
+		"packageName":      cfg.GetPackageName(projectName),
+		"license":          cfg.GetLicense(),
+	}
+
+	templatesPath := filepath.Join(build.BuildPath, "packaging", build.FlavorDirectoryName(manifestsDirectoryName))
+	if _, err := os.Stat(templatesPath); os.IsNotExist(err) {
+		err = initManifestTemplates(templatesPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	outputPath, err := build.OutputDirectoryPath(manifestsDirectoryName, build.DefaultArch)
+	if err != nil {
+		return nil, err
+	}
+	err = os.RemoveAll(outputPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to clean output directory %s", outputPath)
+	}
+
+	var manifests []string
+	err = filepath.Walk(templatesPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relativePath, err := filepath.Rel(templatesPath, path)
+		if err != nil {
+			return err
+		}
+		manifest, err := renderManifest(path, outputPath, strings.TrimSuffix(relativePath, ".tmpl"), templateData, artifacts)
+		if err != nil {
+			return errors.Wrapf(err, "failed to render %s", relativePath)
+		}
+		if manifest != "" {
+			log.Artifact(manifestsDirectoryName, manifest)
+			manifests = append(manifests, manifest)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifests, nil
+}
+
+// initManifestTemplates creates the default manifest templates.
+func initManifestTemplates(templatesPath string) error {
+	for _, file := range manifestTemplateFiles {
+		destination := filepath.Join(templatesPath, filepath.FromSlash(file))
+		err := os.MkdirAll(filepath.Dir(destination), 0775)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create directory %s", filepath.Dir(destination))
+		}
+		err = fileutils.CopyAsset("packaging/"+manifestsDirectoryName+"/"+file, destination)
+		if err != nil {
+			return err
+		}
+	}
+	log.Infof("go/packaging/%s has been created. You can modify the manifest templates and add them to git.", build.FlavorDirectoryName(manifestsDirectoryName))
+	return nil
+}
+
+// findArtifacts returns the artifacts of the packaging formats, indexed by
+// packaging format and architecture. Artifacts whose build info has another
+// version are left out.
+func findArtifacts(baseURL, version string) (map[string]map[string]*manifestArtifact, error) {
+	artifacts := make(map[string]map[string]*manifestArtifact)
+	for _, target := range Targets() {
+		if !strings.Contains(target, "-") {
+			continue
+		}
+		for _, arch := range []string{"amd64", "arm64"} {
+			dir := filepath.Join(build.BuildPath, "build", "outputs", build.TargetDirectoryName(build.FlavorDirectoryName(target), arch))
+			artifact, err := findArtifact(dir, version)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read the %s artifact", target)
+			}
+			if artifact == nil {
+				continue
+			}
+			artifact.Target = target
+			artifact.Arch = arch
+			artifact.URL = baseURL + "/" + url.PathEscape(artifact.FileName)
+			if artifacts[target] == nil {
+				artifacts[target] = make(map[string]*manifestArtifact)
+			}
+			artifacts[target][arch] = artifact
+		}
+	}
+	return artifacts, nil
+}
+
+// findArtifact returns the packaged file of an output directory, nil when
+// the directory doesn't hold a single file of the given version.
+func findArtifact(dir, version string) (*manifestArtifact, error) {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var packagedFiles []os.FileInfo
+	for _, file := range files {
+		if file.Name() != buildinfo.Filename {
+			packagedFiles = append(packagedFiles, file)
+		}
+	}
+	if len(packagedFiles) != 1 || !packagedFiles[0].Mode().IsRegular() {
+		return nil, nil
+	}
+	info, err := buildinfo.Read(dir)
+	if err == nil && info.Version != "" && strings.Split(info.Version, "+")[0] != version {
+		log.Warnf("%s was packaged for version %s, it is left out of the manifests", filepath.Join(dir, packagedFiles[0].Name()), info.Version)
+		return nil, nil
+	}
+	file, err := os.Open(filepath.Join(dir, packagedFiles[0].Name()))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, file)
+	if err != nil {
+		return nil, err
+	}
+	return &manifestArtifact{
+		FileName: packagedFiles[0].Name(),
+		SHA256:   hex.EncodeToString(h.Sum(nil)),
+		Size:     size,
+	}, nil
+}
+
+// renderManifest renders a manifest template to its name, relative to
+// outputPath, and returns the path of the manifest, or an empty path when
+// the manifest refers to an artifact that wasn't packaged. Besides the
+// template data, the templates can call `artifacts "<format>"`, which returns
+// the artifacts of a packaging format indexed by architecture, and `quote`,
+// which returns a string as a double quoted JSON string, also valid in YAML
+// and Ruby.
+func renderManifest(templatePath, outputPath, manifestName string, templateData map[string]interface{}, artifacts map[string]map[string]*manifestArtifact) (string, error) {
+	var missingTarget string
+	funcs := template.FuncMap{
+		"artifacts": func(target string) (map[string]*manifestArtifact, error) {
+			if len(artifacts[target]) == 0 {
+				missingTarget = target
+				return nil, errors.Errorf("%s wasn't packaged", target)
+			}
+			return artifacts[target], nil
+		},
+		"quote": func(s string) (string, error) {
+			data, err := json.Marshal(s)
+			return string(data), err
+		},
+	}
+	executeTemplate := func(text string) (string, error) {
+		tmpl, err := template.New(filepath.Base(templatePath)).Funcs(funcs).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return "", err
+		}
+		var buf bytes.Buffer
+		err = tmpl.Execute(&buf, templateData)
+		return buf.String(), err
+	}
+
+	manifestName, err := executeTemplate(manifestName)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to render the name of the manifest")
+	}
+	manifestPath := filepath.Join(outputPath, manifestName)
+	text, err := ioutil.ReadFile(templatePath)
+	if err != nil {
+		return "", err
+	}
+	manifest, err := executeTemplate(string(text))
+	if missingTarget != "" {
+		log.Warnf("%s wasn't packaged, %s is skipped. Package it with `hover build %s`.", missingTarget, filepath.Base(manifestPath), missingTarget)
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll(filepath.Dir(manifestPath), 0775)
+	if err != nil {
+		return "", err
+	}
+	err = ioutil.WriteFile(manifestPath, []byte(manifest), 0644)
+	if err != nil {
+		return "", err
+	}
+	return manifestPath, nil
+}
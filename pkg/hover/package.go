@@ -0,0 +1,260 @@
+package hover
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/hover/internal/build"
+	"github.com/go-flutter-desktop/hover/internal/buildinfo"
+	"github.com/go-flutter-desktop/hover/internal/config"
+	"github.com/go-flutter-desktop/hover/internal/log"
+	"github.com/go-flutter-desktop/hover/internal/packaging"
+	"github.com/go-flutter-desktop/hover/internal/pubspec"
+)
+
+// targetTasks contains the packaging task of every build target, indexed by
+// target name. Plain OS targets don't package the build output.
+var targetTasks = map[string]packaging.Task{
+	"linux":          packaging.NoopTask,
+	"linux-snap":     packaging.LinuxSnapTask,
+	"linux-deb":      packaging.LinuxDebTask,
+	"linux-appimage": packaging.LinuxAppImageTask,
+	"linux-rpm":      packaging.LinuxRpmTask,
+	"linux-pkg":      packaging.LinuxPkgTask,
+	"linux-tar":      packaging.LinuxTarTask,
+	"linux-flatpak":  packaging.LinuxFlatpakTask,
+	"linux-nix":      packaging.LinuxNixTask,
+	"darwin":         packaging.NoopTask,
+	"darwin-bundle":  packaging.DarwinBundleTask,
+	"darwin-pkg":     packaging.DarwinPkgTask,
+	"darwin-dmg":     packaging.DarwinDmgTask,
+	"darwin-zip":     packaging.DarwinZipTask,
+	"windows":        packaging.NoopTask,
+	"windows-msi":    packaging.WindowsMsiTask,
+	"windows-zip":    packaging.WindowsZipTask,
+	"windows-nsis":   packaging.WindowsNsisTask,
+	"windows-msix":   packaging.WindowsMsixTask,
+}
+
+// Targets returns the names of all the targets hover can build.
+func Targets() []string {
+	var names []string
+	for name := range targetTasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TargetResult contains the outcome of a single build target.
+type TargetResult struct {
+	// Target is the name of the target, e.g. linux-deb.
+	Target string
+	// Artifact is the path of the packaged file, or of the output directory
+	// for targets without packaging.
+	Artifact string
+	// Err is set when the target failed to build or to package.
+	Err error
+	// Duration is the time spent packaging the target, it is zero for builds
+	// run in docker, which package in the container.
+	Duration time.Duration
+}
+
+// target is a build target resolved from its name.
+type target struct {
+	name          string
+	targetOS      string
+	packagingTask packaging.Task
+}
+
+// parseTargets resolves target names, duplicates are removed.
+func parseTargets(names []string) ([]*target, error) {
+	var targets []*target
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		packagingTask, ok := targetTasks[name]
+		if !ok {
+			return nil, errors.Errorf("unknown build target '%s'", name)
+		}
+		targets = append(targets, &target{
+			name:          name,
+			targetOS:      strings.Split(name, "-")[0],
+			packagingTask: packagingTask,
+		})
+	}
+	if len(targets) == 0 {
+		return nil, errors.New("no build target given")
+	}
+	return targets, nil
+}
+
+// targetOSs returns the OSs of the targets, in the order of the targets.
+func targetOSs(targets []*target) []string {
+	var targetOSs []string
+	seen := make(map[string]bool)
+	for _, target := range targets {
+		if !seen[target.targetOS] {
+			seen[target.targetOS] = true
+			targetOSs = append(targetOSs, target.targetOS)
+		}
+	}
+	return targetOSs
+}
+
+// PackageOptions configures the packaging of already built targets.
+type PackageOptions struct {
+	// Targets lists the packaging formats to package, e.g. linux-deb.
+	Targets []string
+	// Arch is the GOARCH the targets were built for, amd64 or arm64.
+	Arch string
+	// Flavor is the name of the flavor of hover.yaml the targets were built
+	// for.
+	Flavor string
+	// VersionNumber overrides the version number of pubspec.yaml.
+	VersionNumber string
+}
+
+// Package packages the output of a previous build for the targets listed in
+// opts. The targets are packaged concurrently, failures are reported in the
+// returned BuildResult and in the returned error.
+func Package(ctx context.Context, opts PackageOptions) (*BuildResult, error) {
+	err := assertProject()
+	if err != nil {
+		return nil, err
+	}
+	err = config.SelectFlavor(opts.Flavor)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Arch == "" {
+		arch, err := config.Resolve(config.SettingArch)
+		if err != nil {
+			return nil, err
+		}
+		opts.Arch = arch.Value
+	}
+	err = build.ValidateArch(opts.Arch)
+	if err != nil {
+		return nil, err
+	}
+	if opts.VersionNumber == "" {
+		pubSpec, err := pubspec.GetPubSpec()
+		if err != nil {
+			return nil, err
+		}
+		opts.VersionNumber = pubSpec.GetVersion()
+	}
+	targets, err := parseTargets(opts.Targets)
+	if err != nil {
+		return nil, err
+	}
+	for _, target := range targets {
+		err = target.packagingTask.AssertInitialized()
+		if err != nil {
+			return nil, err
+		}
+		err = target.packagingTask.CheckSupported()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	result := &BuildResult{
+		Arch:    opts.Arch,
+		Targets: packTargets(ctx, targets, opts.VersionNumber, opts.Arch),
+	}
+	return result, result.err()
+}
+
+// InitPackaging creates the configuration files of a packaging format, e.g.
+// linux-deb, in go/packaging for the given flavor of hover.yaml.
+func InitPackaging(target, flavor string) error {
+	err := assertProject()
+	if err != nil {
+		return err
+	}
+	err = config.SelectFlavor(flavor)
+	if err != nil {
+		return err
+	}
+	packagingTask, ok := targetTasks[target]
+	if !ok || packagingTask == packaging.NoopTask {
+		return errors.Errorf("unknown packaging format '%s'", target)
+	}
+	return packagingTask.Init()
+}
+
+// packageStep is the name of the packaging step in the JSON output.
+const packageStep = "package"
+
+// packTargets packages the targets concurrently from the build output.
+func packTargets(ctx context.Context, targets []*target, versionNumber, targetArch string) []TargetResult {
+	log.Infof("Packaging %d targets", len(targets))
+	results := make([]TargetResult, len(targets))
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func(i int, t *target) {
+			defer wg.Done()
+			start := time.Now()
+			done := log.Step(packageStep, t.name)
+			artifact, err := t.packagingTask.Pack(ctx, versionNumber, targetArch)
+			if err == nil && artifact == "" {
+				artifact, err = build.OutputDirectoryPath(t.targetOS, targetArch)
+			}
+			done(err)
+			if err == nil {
+				log.Artifact(t.name, artifact)
+			}
+			results[i] = TargetResult{
+				Target:   t.name,
+				Artifact: artifact,
+				Err:      err,
+				Duration: time.Since(start),
+			}
+		}(i, t)
+	}
+	wg.Wait()
+	return results
+}
+
+// dockerArtifact returns the path of the artifact of a target packaged in the
+// docker container: the single file or directory of its output directory,
+// besides the build info.
+func dockerArtifact(t *target, targetArch string) (string, error) {
+	outputDirectoryPath, err := build.OutputDirectoryPath(t.name, targetArch)
+	if err != nil {
+		return "", err
+	}
+	if t.packagingTask == packaging.NoopTask {
+		return outputDirectoryPath, nil
+	}
+	files, err := ioutil.ReadDir(outputDirectoryPath)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read the output directory of %s", t.name)
+	}
+	var artifacts []string
+	for _, file := range files {
+		if file.Name() != buildinfo.Filename {
+			artifacts = append(artifacts, filepath.Join(outputDirectoryPath, file.Name()))
+		}
+	}
+	if len(artifacts) != 1 {
+		return "", errors.Errorf("the docker build didn't package %s, found %d files in %s", t.name, len(artifacts), outputDirectoryPath)
+	}
+	return artifacts[0], nil
+}
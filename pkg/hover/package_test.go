@@ -0,0 +1,111 @@
+package hover
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-flutter-desktop/hover/internal/build"
+	"github.com/go-flutter-desktop/hover/internal/buildinfo"
+)
+
+func TestParseTargets(t *testing.T) {
+	for _, test := range []struct {
+		names   []string
+		targets []string
+		err     string
+	}{
+		{
+			names:   []string{"linux", "linux-deb", "windows"},
+			targets: []string{"linux", "linux-deb", "windows"},
+		},
+		{
+			names:   []string{"linux-deb", " linux-deb", "", "darwin", "linux-deb "},
+			targets: []string{"linux-deb", "darwin"},
+		},
+		{
+			names: []string{"linux", "linux-exe"},
+			err:   "unknown build target 'linux-exe'",
+		},
+		{
+			names: []string{" ", ""},
+			err:   "no build target given",
+		},
+		{
+			err: "no build target given",
+		},
+	} {
+		targets, err := parseTargets(test.names)
+		if test.err != "" {
+			require.NotEqual(t, err, nil, "parsing %q must fail", test.names)
+			require.Equal(t, test.err, err.Error())
+			continue
+		}
+		require.Equal(t, err, nil, "failed to parse %q: %v", test.names, err)
+		var names []string
+		for _, target := range targets {
+			names = append(names, target.name)
+			require.Equal(t, targetTasks[target.name], target.packagingTask)
+		}
+		require.Equal(t, test.targets, names)
+	}
+
+	targets, err := parseTargets([]string{"linux-deb", "windows-msix", "linux"})
+	require.Equal(t, err, nil, "failed to parse the targets: %v", err)
+	require.Equal(t, []string{"linux", "windows"}, targetOSs(targets))
+}
+
+// chdir changes the working directory for the duration of a test, the build
+// directories are relative to it.
+func chdir(t *testing.T, dir string) {
+	wd, err := os.Getwd()
+	require.Equal(t, err, nil, "failed to get the working directory: %v", err)
+	err = os.Chdir(dir)
+	require.Equal(t, err, nil, "failed to change the working directory: %v", err)
+	t.Cleanup(func() {
+		err := os.Chdir(wd)
+		require.Equal(t, err, nil, "failed to restore the working directory: %v", err)
+	})
+}
+
+func TestDockerArtifact(t *testing.T) {
+	chdir(t, t.TempDir())
+	targets, err := parseTargets([]string{"linux", "linux-deb", "linux-rpm", "windows-zip"})
+	require.Equal(t, err, nil, "failed to parse the targets: %v", err)
+	outputs := make(map[string]string)
+	for _, target := range targets {
+		outputs[target.name], err = build.OutputDirectoryPath(target.name, "arm64")
+		require.Equal(t, err, nil, "failed to create the output directory: %v", err)
+		err = ioutil.WriteFile(filepath.Join(outputs[target.name], buildinfo.Filename), []byte("{}"), 0644)
+		require.Equal(t, err, nil, "failed to write the build info: %v", err)
+	}
+	err = ioutil.WriteFile(filepath.Join(outputs["linux-deb"], "app_1.0.0_arm64.deb"), nil, 0644)
+	require.Equal(t, err, nil, "failed to write the package: %v", err)
+	for _, name := range []string{"app-1.0.0.aarch64.rpm", "app-0.9.0.aarch64.rpm"} {
+		err = ioutil.WriteFile(filepath.Join(outputs["linux-rpm"], name), nil, 0644)
+		require.Equal(t, err, nil, "failed to write the package: %v", err)
+	}
+
+	for _, test := range []struct {
+		target   *target
+		artifact string
+		err      string
+	}{
+		{target: targets[0], artifact: outputs["linux"]},
+		{target: targets[1], artifact: filepath.Join(outputs["linux-deb"], "app_1.0.0_arm64.deb")},
+		{target: targets[2], err: "the docker build didn't package linux-rpm, found 2 files in " + outputs["linux-rpm"]},
+		{target: targets[3], err: "the docker build didn't package windows-zip, found 0 files in " + outputs["windows-zip"]},
+	} {
+		artifact, err := dockerArtifact(test.target, "arm64")
+		if test.err != "" {
+			require.NotEqual(t, err, nil, "the artifact of %s must not be found", test.target.name)
+			require.Equal(t, test.err, err.Error())
+			continue
+		}
+		require.Equal(t, err, nil, "failed to find the artifact of %s: %v", test.target.name, err)
+		require.Equal(t, test.artifact, artifact)
+	}
+}
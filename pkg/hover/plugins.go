@@ -0,0 +1,284 @@
+package hover
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/go-flutter-desktop/hover/internal/build"
+	"github.com/go-flutter-desktop/hover/internal/log"
+	"github.com/go-flutter-desktop/hover/internal/pubspec"
+)
+
+const standaloneImplementationListAPI = "https://raw.githubusercontent.com/go-flutter-desktop/plugins/master/list.json"
+
+// Plugin is a Flutter platform plugin the application depends on.
+type Plugin struct {
+	// Name is the name of the plugin package.
+	Name string
+	// Version is the version of the plugin in pubspec.lock.
+	Version string
+	// Android, IOS and Desktop are set when the plugin has an implementation
+	// for the platform. Desktop means a go-flutter implementation.
+	Android bool
+	IOS     bool
+	Desktop bool
+	// Path is set when the plugin is a path dependency.
+	Path string
+	// Host is the pub server hosting the plugin.
+	Host string
+	// AutoImport is set when hover is able to import the plugin.
+	AutoImport bool
+	// GoSource is the path or the URL of the go code of the plugin.
+	GoSource string
+	// StandaloneImpl is set when the go code of the plugin isn't maintained
+	// by the plugin authors but in github.com/go-flutter-desktop/plugins.
+	StandaloneImpl bool
+}
+
+// Imported reports whether the plugin is imported in the go-flutter project.
+func (p Plugin) Imported() bool {
+	pluginImportOutPath := filepath.Join(build.BuildPath, "cmd", fmt.Sprintf("import-%s-plugin.go", p.Name))
+	if _, err := os.Stat(pluginImportOutPath); err == nil {
+		return true
+	}
+	return false
+}
+
+// Platforms returns the platforms the plugin has an implementation for.
+func (p Plugin) Platforms() []string {
+	var platforms []string
+	if p.Android {
+		platforms = append(platforms, "android")
+	}
+	if p.IOS {
+		platforms = append(platforms, "ios")
+	}
+	if p.Desktop {
+		platforms = append(platforms, build.BuildPath)
+	}
+	return platforms
+}
+
+// pubSpecLock contains the parsed contents of pubspec.lock
+type pubSpecLock struct {
+	Packages map[string]pubDep
+}
+
+// pubDep contains one entry of the pubspec.lock yaml list
+type pubDep struct {
+	Dependency  string
+	Description interface{}
+	Source      string
+	Version     string
+}
+
+// PluginsList returns the platform plugins listed in the pubspec.lock file of
+// the project.
+func PluginsList(ctx context.Context) ([]Plugin, error) {
+	onlineList, err := fetchStandaloneImplementationList(ctx)
+	if err != nil {
+		log.Warnf("Warning, couldn't read the online plugin list: %v", err)
+	}
+
+	pubcachePath, err := findPubcachePath()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find path for pub-cache")
+	}
+
+	var list []Plugin
+	pubLock, err := readPubSpecLock()
+	if err != nil {
+		log.Infof("Run `%s` (or equivalent) first", log.Au().Magenta("flutter build bundle"))
+		return nil, err
+	}
+
+	for name, dep := range pubLock.Packages {
+		entry := Plugin{
+			Name:    name,
+			Version: dep.Version,
+		}
+
+		switch i := dep.Description.(type) {
+		case string:
+			if i == "flutter" {
+				continue
+			}
+		case map[interface{}]interface{}:
+			if value, ok := i["path"]; ok {
+				entry.Path = value.(string)
+			}
+			if value, ok := i["url"]; ok {
+				url, err := url.Parse(value.(string))
+				if err != nil {
+					return nil, errors.Wrap(err, "failed to parse URL from string %s"+value.(string))
+				}
+				entry.Host = url.Host
+			}
+		}
+
+		pluginPath := filepath.Join(pubcachePath, "hosted", entry.Host, entry.Name+"-"+entry.Version)
+		if entry.Path != "" {
+			pluginPath = entry.Path
+		}
+
+		pluginPubspecPath := filepath.Join(pluginPath, "pubspec.yaml")
+		pluginPubspec, err := pubspec.ReadPubSpecFile(pluginPubspecPath)
+		if err != nil {
+			continue
+		}
+
+		// Non plugin package are likely to contain android/ios folders (even
+		// through they aren't used).
+		// To check if the package is really a platform plugin, we need to read
+		// the pubspec.yaml file. If he contains a Flutter/plugin entry, then
+		// it's a platform plugin.
+		if _, ok := pluginPubspec.Flutter["plugin"]; !ok {
+			continue
+		}
+
+		detectPlatformPlugin := func(platform string) (bool, error) {
+			platformPath := filepath.Join(pluginPath, platform)
+			stat, err := os.Stat(platformPath)
+			if err != nil {
+				if os.IsNotExist(err) {
+					return false, nil
+				}
+				return false, errors.Wrapf(err, "failed to stat %s", platformPath)
+			}
+			return stat.IsDir(), nil
+		}
+
+		entry.Android, err = detectPlatformPlugin("android")
+		if err != nil {
+			return nil, err
+		}
+		entry.IOS, err = detectPlatformPlugin("ios")
+		if err != nil {
+			return nil, err
+		}
+		entry.Desktop, err = detectPlatformPlugin(build.BuildPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if entry.Desktop {
+			entry.GoSource = filepath.Join(pluginPath, build.BuildPath)
+			autoImportTemplate := filepath.Join(entry.GoSource, "import.go.tmpl")
+			_, err := os.Stat(autoImportTemplate)
+			entry.AutoImport = true
+			if err != nil {
+				entry.AutoImport = false
+				if !os.IsNotExist(err) {
+					return nil, errors.Wrapf(err, "failed to stat %s", autoImportTemplate)
+				}
+			}
+		} else {
+			// check if the plugin is available in github.com/go-flutter-desktop/plugins
+			for _, plugin := range onlineList {
+				if entry.Name == plugin.Name {
+					entry.Desktop = true
+					entry.StandaloneImpl = true
+					entry.AutoImport = true
+					entry.GoSource = plugin.ImportFile
+					break
+				}
+			}
+		}
+
+		list = append(list, entry)
+
+	}
+	return list, nil
+}
+
+// readPubSpecLock reads pubspec.lock in the current working directory.
+func readPubSpecLock() (*pubSpecLock, error) {
+	p := &pubSpecLock{}
+	file, err := os.Open("pubspec.lock")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.New("no pubspec.lock file found")
+
+		}
+		return nil, errors.Wrap(err, "failed to open pubspec.lock")
+	}
+	defer file.Close()
+
+	err = yaml.NewDecoder(file).Decode(p)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode pubspec.lock")
+	}
+	return p, nil
+}
+
+// findPubcachePath returns the absolute path for the pub-cache or an error.
+func findPubcachePath() (string, error) {
+	var path string
+	switch runtime.GOOS {
+	case "darwin", "linux":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", errors.Wrap(err, "failed to resolve user home dir")
+		}
+		path = filepath.Join(home, ".pub-cache")
+	case "windows":
+		path = filepath.Join(os.Getenv("APPDATA"), "Pub", "Cache")
+	}
+	return path, nil
+}
+
+type onlineList struct {
+	List []standaloneImplementation `json:"standaloneImplementation"`
+}
+
+// standaloneImplementation contains the go-flutter compatible plugins that
+// aren't merged into original VSC repo.
+type standaloneImplementation struct {
+	Name       string `json:"name"`
+	ImportFile string `json:"importFile"`
+}
+
+func fetchStandaloneImplementationList(ctx context.Context) ([]standaloneImplementation, error) {
+	remoteList := &onlineList{}
+
+	client := http.Client{
+		Timeout: time.Second * 20, // Maximum of 10 secs
+	}
+
+	req, err := http.NewRequest(http.MethodGet, standaloneImplementationListAPI, nil)
+	if err != nil {
+		return remoteList.List, err
+	}
+
+	res, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return remoteList.List, err
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return remoteList.List, err
+	}
+
+	if res.StatusCode != 200 {
+		return remoteList.List, errors.New(strings.TrimRight(string(body), "\r\n"))
+	}
+
+	err = json.Unmarshal(body, remoteList)
+	if err != nil {
+		return remoteList.List, err
+	}
+	return remoteList.List, nil
+}
@@ -0,0 +1,135 @@
+package hover
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-flutter-desktop/hover/internal/buildmanifest"
+	"github.com/go-flutter-desktop/hover/internal/log"
+	"github.com/go-flutter-desktop/hover/internal/packaging"
+)
+
+// sourceDateEpoch returns the date reproducible builds are stamped with: the
+// SOURCE_DATE_EPOCH environment variable, or else the commit date of the git
+// HEAD.
+func sourceDateEpoch(ctx context.Context) (time.Time, error) {
+	if value := os.Getenv("SOURCE_DATE_EPOCH"); value != "" {
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, errors.Wrapf(err, "invalid SOURCE_DATE_EPOCH '%s'", value)
+		}
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	out, err := exec.CommandContext(ctx, "git", "log", "-1", "--format=%ct").Output()
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "reproducible builds need the SOURCE_DATE_EPOCH environment variable or a git repository, failed to read the date of the last commit")
+	}
+	seconds, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "failed to parse the date of the last commit")
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+// setupReproducible stamps the packages and the tools run by the build with
+// the source date epoch. The returned function restores the previous state.
+func setupReproducible(ctx context.Context) (func(), error) {
+	epoch, err := sourceDateEpoch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Building reproducibly, files are dated %s", epoch.Format(time.RFC3339))
+	previousEnv, envSet := os.LookupEnv("SOURCE_DATE_EPOCH")
+	// packaging tools such as snapcraft and the mksquashfs of appimagetool
+	// read it from the environment
+	os.Setenv("SOURCE_DATE_EPOCH", strconv.FormatInt(epoch.Unix(), 10))
+	packaging.SetSourceDateEpoch(epoch)
+	return func() {
+		packaging.SetSourceDateEpoch(time.Time{})
+		if envSet {
+			os.Setenv("SOURCE_DATE_EPOCH", previousEnv)
+		} else {
+			os.Unsetenv("SOURCE_DATE_EPOCH")
+		}
+	}, nil
+}
+
+// Difference is a file of an artifact whose content differs between two
+// builds.
+type Difference struct {
+	// Target is the name of the target the artifact belongs to.
+	Target string
+	// Path is the path of the file, it is the artifact itself for packaged
+	// targets.
+	Path string
+}
+
+// VerifyReproducible builds the targets twice in reproducible mode, rerunning
+// every build step, and returns the files of the artifacts whose content
+// differs between the two builds.
+func VerifyReproducible(ctx context.Context, opts BuildOptions) ([]Difference, error) {
+	opts.Reproducible = true
+	opts.Force = true
+
+	var hashes [2]map[string]buildmanifest.Inputs
+	for i := range hashes {
+		log.Infof("Verifying reproducibility, build %d of 2", i+1)
+		result, err := Build(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		hashes[i], err = hashArtifacts(result)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var differences []Difference
+	for target, first := range hashes[0] {
+		second := hashes[1][target]
+		var paths []string
+		for name, hash := range first {
+			if second[name] != hash {
+				paths = append(paths, name)
+			}
+		}
+		for name := range second {
+			if _, ok := first[name]; !ok {
+				paths = append(paths, name)
+			}
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			differences = append(differences, Difference{
+				Target: target,
+				Path:   strings.TrimPrefix(path, "file:"),
+			})
+		}
+	}
+	sort.SliceStable(differences, func(i, j int) bool {
+		return differences[i].Target < differences[j].Target
+	})
+	return differences, nil
+}
+
+// hashArtifacts returns the content hash of the files of every artifact of a
+// build, indexed by target.
+func hashArtifacts(result *BuildResult) (map[string]buildmanifest.Inputs, error) {
+	hashes := make(map[string]buildmanifest.Inputs)
+	for _, target := range result.Targets {
+		inputs := buildmanifest.Inputs{}
+		err := addBuildInput(inputs, target.Artifact)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to hash the artifact of %s", target.Target)
+		}
+		hashes[target.Target] = inputs
+	}
+	return hashes, nil
+}